@@ -3,8 +3,9 @@ module plugin-fake
 go 1.14
 
 require (
+	github.com/hashicorp/go-plugin v1.0.1
 	github.com/josephburnett/sk-plugin v0.0.0 //-20190726113842-f4cc79709047
 )
 
 // TODO: replace this import with github.com/skenario/plugin
-replace github.com/josephburnett/sk-plugin => ../plugin
\ No newline at end of file
+replace github.com/josephburnett/sk-plugin => ../plugin
@@ -1,16 +1,13 @@
 package dispatcher
 
 import (
-	"fmt"
 	"github.com/hashicorp/go-plugin"
 	"github.com/josephburnett/sk-plugin/pkg/skplug"
 	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
-	"os"
-	"os/exec"
 )
 
 type Dispatcher interface {
-	Init(pluginsPaths []string)
+	Init(pluginsPaths []string) error
 	Shutdown()
 	GetPlugin() skplug.Plugin
 }
@@ -72,31 +69,14 @@ func (d *dispatcher) VerticalRecommendation(partition string, time int64) (rec [
 	return []*proto.RecommendedPodResources{}, nil
 }
 
-func (d *dispatcher) Init(pluginsPaths []string) {
+func (d *dispatcher) Init(pluginsPaths []string) error {
 	// We don't want to see the plugin logs.
 	//log.SetOutput(ioutil.Discard)
 	for _, pluginPath := range pluginsPaths {
-		// We're a host. Start by launching the plugin process.
-		client := plugin.NewClient(&plugin.ClientConfig{
-			HandshakeConfig: skplug.Handshake,
-			Plugins:         skplug.PluginMap,
-			Cmd:             exec.Command("sh", "-c", pluginPath),
-			AllowedProtocols: []plugin.Protocol{
-				plugin.ProtocolNetRPC, plugin.ProtocolGRPC},
-		})
-
-		// Connect via RPC
-		rpcClient, err := client.Client()
-		if err != nil {
-			fmt.Println("Error:", err.Error())
-			os.Exit(1)
-		}
-
-		// Request the plugin
-		raw, err := rpcClient.Dispense("autoscaler")
+		client, raw, err := launchPlugin(pluginPath, "autoscaler", plugin.ProtocolNetRPC, plugin.ProtocolGRPC)
+		d.pluginsClients = append(d.pluginsClients, client)
 		if err != nil {
-			fmt.Println("Error:", err.Error())
-			os.Exit(1)
+			return err
 		}
 
 		// We should have a Plugin now! This feels like a normal interface
@@ -104,8 +84,8 @@ func (d *dispatcher) Init(pluginsPaths []string) {
 		pluginServer := raw.(skplug.Plugin)
 		d.registerPlugin(&pluginServer)
 		d.pluginsServers = append(d.pluginsServers, pluginServer)
-		d.pluginsClients = append(d.pluginsClients, client)
 	}
+	return nil
 }
 
 func (d *dispatcher) registerPlugin(pluginServer *skplug.Plugin) {
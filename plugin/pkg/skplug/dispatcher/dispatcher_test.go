@@ -15,7 +15,9 @@ var rawSubject *dispatcher
 
 func TestDispatcher(t *testing.T) {
 	subject = NewDispatcher()
-	subject.Init([]string{"../../../../build/plugin-fake"})
+	if err := subject.Init([]string{"../../../../build/plugin-fake"}); err != nil {
+		t.Fatal(err)
+	}
 	rawSubject = subject.(*dispatcher)
 	spec.Run(t, "Dispatcher", testDispatcher, spec.Report(report.Terminal{}))
 }
@@ -0,0 +1,33 @@
+package dispatcher
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-plugin"
+	"github.com/josephburnett/sk-plugin/pkg/skplug"
+	"os/exec"
+)
+
+// launchPlugin starts the plugin process at pluginPath and dispenses the named plugin from it.
+// The client is returned even on error, so that the caller can kill the process.
+func launchPlugin(pluginPath string, name string, protocols ...plugin.Protocol) (*plugin.Client, interface{}, error) {
+	// We're a host. Start by launching the plugin process.
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  skplug.Handshake,
+		Plugins:          skplug.PluginMap,
+		Cmd:              exec.Command("sh", "-c", pluginPath),
+		AllowedProtocols: protocols,
+	})
+
+	// Connect via RPC
+	rpcClient, err := client.Client()
+	if err != nil {
+		return client, nil, fmt.Errorf("could not start plugin '%s': %s", pluginPath, err.Error())
+	}
+
+	// Request the plugin
+	raw, err := rpcClient.Dispense(name)
+	if err != nil {
+		return client, nil, fmt.Errorf("could not dispense '%s' from plugin '%s': %s", name, pluginPath, err.Error())
+	}
+	return client, raw, nil
+}
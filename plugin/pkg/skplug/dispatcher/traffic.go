@@ -0,0 +1,67 @@
+package dispatcher
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-plugin"
+	"github.com/josephburnett/sk-plugin/pkg/skplug"
+)
+
+// TrafficDispatcher launches traffic generator plugins and hands them out by generator type.
+type TrafficDispatcher interface {
+	Init(pluginsPaths []string) error
+	Shutdown()
+	GetGenerator(generatorType string) (skplug.TrafficGenerator, bool)
+}
+
+type trafficDispatcher struct {
+	generators     map[string]skplug.TrafficGenerator
+	pluginsClients []*plugin.Client
+}
+
+var _ TrafficDispatcher = &trafficDispatcher{}
+
+func (d *trafficDispatcher) Init(pluginsPaths []string) error {
+	for _, pluginPath := range pluginsPaths {
+		client, raw, err := launchPlugin(pluginPath, "traffic_generator", plugin.ProtocolGRPC)
+		d.pluginsClients = append(d.pluginsClients, client)
+		if err != nil {
+			return err
+		}
+
+		err = d.registerGenerator(raw.(skplug.TrafficGenerator))
+		if err != nil {
+			return fmt.Errorf("could not register traffic generator '%s': %s", pluginPath, err.Error())
+		}
+	}
+	return nil
+}
+
+func (d *trafficDispatcher) registerGenerator(generator skplug.TrafficGenerator) error {
+	generatorType, err := generator.GeneratorType()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := d.generators[generatorType]; ok {
+		return fmt.Errorf("traffic dispatcher already has a generator of type '%s'", generatorType)
+	}
+	d.generators[generatorType] = generator
+	return nil
+}
+
+func (d *trafficDispatcher) GetGenerator(generatorType string) (skplug.TrafficGenerator, bool) {
+	generator, ok := d.generators[generatorType]
+	return generator, ok
+}
+
+func (d *trafficDispatcher) Shutdown() {
+	for _, client := range d.pluginsClients {
+		client.Kill()
+	}
+}
+
+func NewTrafficDispatcher() TrafficDispatcher {
+	return &trafficDispatcher{
+		generators: make(map[string]skplug.TrafficGenerator),
+	}
+}
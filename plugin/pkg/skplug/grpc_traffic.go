@@ -0,0 +1,98 @@
+package skplug
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+)
+
+var _ TrafficGenerator = &TrafficGeneratorGRPCClient{}
+
+// TrafficGeneratorGRPCClient is an implementation of TrafficGenerator that talks over RPC.
+type TrafficGeneratorGRPCClient struct {
+	broker *plugin.GRPCBroker
+	client proto.TrafficGeneratorClient
+}
+
+func (m *TrafficGeneratorGRPCClient) Init(partition string, startTime int64, haltTime int64, config string) error {
+	_, err := m.client.Init(context.Background(), &proto.TrafficInitRequest{
+		Partition:      partition,
+		StartTimeNanos: startTime,
+		HaltTimeNanos:  haltTime,
+		Config:         config,
+	})
+	return err
+}
+
+func (m *TrafficGeneratorGRPCClient) Generate(partition string, until int64) (arrivals []*proto.Arrival, done bool, err error) {
+	resp, err := m.client.Generate(context.Background(), &proto.TrafficGenerateRequest{
+		Partition:  partition,
+		UntilNanos: until,
+	})
+	if err != nil {
+		return []*proto.Arrival{}, false, err
+	}
+	return resp.Arrivals, resp.Done, nil
+}
+
+func (m *TrafficGeneratorGRPCClient) Finish(partition string) error {
+	_, err := m.client.Finish(context.Background(), &proto.TrafficPartitionRequest{
+		Partition: partition,
+	})
+	return err
+}
+
+func (m *TrafficGeneratorGRPCClient) GeneratorType() (rec string, err error) {
+	resp, err := m.client.GeneratorType(context.Background(), &proto.Empty{})
+	if err != nil {
+		return "", err
+	}
+	return resp.Rec, nil
+}
+
+var _ proto.TrafficGeneratorServer = &TrafficGeneratorGRPCServer{}
+
+// TrafficGeneratorGRPCServer is the gRPC server that the TrafficGeneratorGRPCClient talks to.
+type TrafficGeneratorGRPCServer struct {
+	Impl TrafficGenerator
+
+	broker *plugin.GRPCBroker
+}
+
+func (m *TrafficGeneratorGRPCServer) Init(ctx context.Context, req *proto.TrafficInitRequest) (*proto.Empty, error) {
+	err := m.Impl.Init(req.Partition, req.StartTimeNanos, req.HaltTimeNanos, req.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.Empty{}, nil
+}
+
+func (m *TrafficGeneratorGRPCServer) Generate(ctx context.Context, req *proto.TrafficGenerateRequest) (*proto.TrafficGenerateResponse, error) {
+	arrivals, done, err := m.Impl.Generate(req.Partition, req.UntilNanos)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.TrafficGenerateResponse{
+		Arrivals: arrivals,
+		Done:     done,
+	}, nil
+}
+
+func (m *TrafficGeneratorGRPCServer) Finish(ctx context.Context, req *proto.TrafficPartitionRequest) (*proto.Empty, error) {
+	err := m.Impl.Finish(req.Partition)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.Empty{}, nil
+}
+
+func (m *TrafficGeneratorGRPCServer) GeneratorType(ctx context.Context, req *proto.Empty) (*proto.PluginTypeResponse, error) {
+	rec, err := m.Impl.GeneratorType()
+	if err != nil {
+		return nil, err
+	}
+	return &proto.PluginTypeResponse{
+		Rec: rec,
+	}, nil
+}
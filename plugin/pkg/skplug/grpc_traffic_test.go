@@ -0,0 +1,107 @@
+package skplug
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTrafficGenerator struct {
+	inits    []*proto.TrafficInitRequest
+	finished []string
+}
+
+func (f *fakeTrafficGenerator) Init(partition string, startTime int64, haltTime int64, config string) error {
+	if partition == "" {
+		return fmt.Errorf("no partition")
+	}
+	f.inits = append(f.inits, &proto.TrafficInitRequest{Partition: partition, StartTimeNanos: startTime, HaltTimeNanos: haltTime, Config: config})
+	return nil
+}
+
+func (f *fakeTrafficGenerator) Generate(partition string, until int64) (arrivals []*proto.Arrival, done bool, err error) {
+	return []*proto.Arrival{
+		{TimeNanos: until - 2, Class: "a"},
+		{TimeNanos: until - 1, CpuTimeMillis: 100, IoTimeMillis: 200},
+	}, until >= 100, nil
+}
+
+func (f *fakeTrafficGenerator) Finish(partition string) error {
+	f.finished = append(f.finished, partition)
+	return nil
+}
+
+func (f *fakeTrafficGenerator) GeneratorType() (rec string, err error) {
+	return "fake", nil
+}
+
+func TestTrafficGeneratorGRPC(t *testing.T) {
+	spec.Run(t, "Traffic generator over gRPC", testTrafficGeneratorGRPC, spec.Report(report.Terminal{}))
+}
+
+func testTrafficGeneratorGRPC(t *testing.T, describe spec.G, it spec.S) {
+	var impl *fakeTrafficGenerator
+	var subject TrafficGenerator
+	var client *plugin.GRPCClient
+
+	it.Before(func() {
+		impl = &fakeTrafficGenerator{}
+		client, _ = plugin.TestPluginGRPCConn(t, map[string]plugin.Plugin{
+			"traffic_generator": &TrafficGeneratorPlugin{Impl: impl},
+		})
+		raw, err := client.Dispense("traffic_generator")
+		assert.NoError(t, err)
+		subject = raw.(TrafficGenerator)
+	})
+
+	it.After(func() {
+		client.Close()
+	})
+
+	it("passes the scenario window and config to Init", func() {
+		err := subject.Init("p1", 10, 100, `{"x":1}`)
+		assert.NoError(t, err)
+		assert.Len(t, impl.inits, 1)
+		assert.Equal(t, "p1", impl.inits[0].Partition)
+		assert.Equal(t, int64(10), impl.inits[0].StartTimeNanos)
+		assert.Equal(t, int64(100), impl.inits[0].HaltTimeNanos)
+		assert.Equal(t, `{"x":1}`, impl.inits[0].Config)
+	})
+
+	it("returns errors from Init", func() {
+		err := subject.Init("", 10, 100, "")
+		assert.Error(t, err)
+	})
+
+	it("returns the arrivals from Generate", func() {
+		arrivals, done, err := subject.Generate("p1", 50)
+		assert.NoError(t, err)
+		assert.False(t, done)
+		assert.Len(t, arrivals, 2)
+		assert.Equal(t, int64(48), arrivals[0].TimeNanos)
+		assert.Equal(t, "a", arrivals[0].Class)
+		assert.Equal(t, int32(100), arrivals[1].CpuTimeMillis)
+		assert.Equal(t, int32(200), arrivals[1].IoTimeMillis)
+
+		_, done, err = subject.Generate("p1", 100)
+		assert.NoError(t, err)
+		assert.True(t, done)
+	})
+
+	it("finishes the partition", func() {
+		err := subject.Finish("p1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"p1"}, impl.finished)
+	})
+
+	it("reports the generator type", func() {
+		rec, err := subject.GeneratorType()
+		assert.NoError(t, err)
+		assert.Equal(t, "fake", rec)
+	})
+}
@@ -17,7 +17,8 @@ var Handshake = plugin.HandshakeConfig{
 
 // PluginMap is the map of plugins we can dispense.
 var PluginMap = map[string]plugin.Plugin{
-	"autoscaler": &AutoscalerPlugin{},
+	"autoscaler":        &AutoscalerPlugin{},
+	"traffic_generator": &TrafficGeneratorPlugin{},
 }
 
 // Plugin is the interface that we're exposing as a plugin.
@@ -56,3 +57,36 @@ func (p *AutoscalerPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBr
 }
 
 var _ plugin.GRPCPlugin = &AutoscalerPlugin{}
+
+// TrafficGenerator is the interface exposed by traffic generator plugins. A generator is initialised
+// once per partition with the scenario window, then asked for arrivals a piece at a time. Times are in
+// nanoseconds since the epoch.
+type TrafficGenerator interface {
+	Init(partition string, startTime int64, haltTime int64, config string) error
+	Generate(partition string, until int64) (arrivals []*proto.Arrival, done bool, err error)
+	Finish(partition string) error
+	GeneratorType() (rec string, err error)
+}
+
+// TrafficGeneratorPlugin serves and consumes a TrafficGenerator over gRPC.
+type TrafficGeneratorPlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+	Impl TrafficGenerator
+}
+
+func (p *TrafficGeneratorPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	proto.RegisterTrafficGeneratorServer(s, &TrafficGeneratorGRPCServer{
+		Impl:   p.Impl,
+		broker: broker,
+	})
+	return nil
+}
+
+func (p *TrafficGeneratorPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &TrafficGeneratorGRPCClient{
+		client: proto.NewTrafficGeneratorClient(c),
+		broker: broker,
+	}, nil
+}
+
+var _ plugin.GRPCPlugin = &TrafficGeneratorPlugin{}
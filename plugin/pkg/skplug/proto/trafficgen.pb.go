@@ -0,0 +1,708 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.23.0
+// 	protoc        v3.6.1
+// source: trafficgen.proto
+
+package proto
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type TrafficInitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Partition string `protobuf:"bytes,1,opt,name=partition,proto3" json:"partition,omitempty"`
+	// The scenario window, in nanoseconds since the epoch.
+	StartTimeNanos int64 `protobuf:"varint,2,opt,name=start_time_nanos,json=startTimeNanos,proto3" json:"start_time_nanos,omitempty"`
+	HaltTimeNanos  int64 `protobuf:"varint,3,opt,name=halt_time_nanos,json=haltTimeNanos,proto3" json:"halt_time_nanos,omitempty"`
+	// Generator-specific configuration, passed through unchanged from the scenario.
+	Config string `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+}
+
+func (x *TrafficInitRequest) Reset() {
+	*x = TrafficInitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficgen_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrafficInitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficInitRequest) ProtoMessage() {}
+
+func (x *TrafficInitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficgen_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficInitRequest.ProtoReflect.Descriptor instead.
+func (*TrafficInitRequest) Descriptor() ([]byte, []int) {
+	return file_trafficgen_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TrafficInitRequest) GetPartition() string {
+	if x != nil {
+		return x.Partition
+	}
+	return ""
+}
+
+func (x *TrafficInitRequest) GetStartTimeNanos() int64 {
+	if x != nil {
+		return x.StartTimeNanos
+	}
+	return 0
+}
+
+func (x *TrafficInitRequest) GetHaltTimeNanos() int64 {
+	if x != nil {
+		return x.HaltTimeNanos
+	}
+	return 0
+}
+
+func (x *TrafficInitRequest) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+type TrafficGenerateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Partition  string `protobuf:"bytes,1,opt,name=partition,proto3" json:"partition,omitempty"`
+	UntilNanos int64  `protobuf:"varint,2,opt,name=until_nanos,json=untilNanos,proto3" json:"until_nanos,omitempty"`
+}
+
+func (x *TrafficGenerateRequest) Reset() {
+	*x = TrafficGenerateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficgen_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrafficGenerateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficGenerateRequest) ProtoMessage() {}
+
+func (x *TrafficGenerateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficgen_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficGenerateRequest.ProtoReflect.Descriptor instead.
+func (*TrafficGenerateRequest) Descriptor() ([]byte, []int) {
+	return file_trafficgen_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TrafficGenerateRequest) GetPartition() string {
+	if x != nil {
+		return x.Partition
+	}
+	return ""
+}
+
+func (x *TrafficGenerateRequest) GetUntilNanos() int64 {
+	if x != nil {
+		return x.UntilNanos
+	}
+	return 0
+}
+
+type Arrival struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TimeNanos int64 `protobuf:"varint,1,opt,name=time_nanos,json=timeNanos,proto3" json:"time_nanos,omitempty"`
+	// Zero values keep the service's defaults.
+	CpuTimeMillis int32  `protobuf:"varint,2,opt,name=cpu_time_millis,json=cpuTimeMillis,proto3" json:"cpu_time_millis,omitempty"`
+	IoTimeMillis  int32  `protobuf:"varint,3,opt,name=io_time_millis,json=ioTimeMillis,proto3" json:"io_time_millis,omitempty"`
+	Class         string `protobuf:"bytes,4,opt,name=class,proto3" json:"class,omitempty"`
+}
+
+func (x *Arrival) Reset() {
+	*x = Arrival{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficgen_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Arrival) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Arrival) ProtoMessage() {}
+
+func (x *Arrival) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficgen_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Arrival.ProtoReflect.Descriptor instead.
+func (*Arrival) Descriptor() ([]byte, []int) {
+	return file_trafficgen_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Arrival) GetTimeNanos() int64 {
+	if x != nil {
+		return x.TimeNanos
+	}
+	return 0
+}
+
+func (x *Arrival) GetCpuTimeMillis() int32 {
+	if x != nil {
+		return x.CpuTimeMillis
+	}
+	return 0
+}
+
+func (x *Arrival) GetIoTimeMillis() int32 {
+	if x != nil {
+		return x.IoTimeMillis
+	}
+	return 0
+}
+
+func (x *Arrival) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+type TrafficGenerateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Arrivals not returned before, in any order. They should cover everything before until_nanos
+	// and may run past it.
+	Arrivals []*Arrival `protobuf:"bytes,1,rep,name=arrivals,proto3" json:"arrivals,omitempty"`
+	// No arrivals follow these.
+	Done bool `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`
+}
+
+func (x *TrafficGenerateResponse) Reset() {
+	*x = TrafficGenerateResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficgen_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrafficGenerateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficGenerateResponse) ProtoMessage() {}
+
+func (x *TrafficGenerateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficgen_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficGenerateResponse.ProtoReflect.Descriptor instead.
+func (*TrafficGenerateResponse) Descriptor() ([]byte, []int) {
+	return file_trafficgen_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TrafficGenerateResponse) GetArrivals() []*Arrival {
+	if x != nil {
+		return x.Arrivals
+	}
+	return nil
+}
+
+func (x *TrafficGenerateResponse) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+type TrafficPartitionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Partition string `protobuf:"bytes,1,opt,name=partition,proto3" json:"partition,omitempty"`
+}
+
+func (x *TrafficPartitionRequest) Reset() {
+	*x = TrafficPartitionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficgen_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrafficPartitionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficPartitionRequest) ProtoMessage() {}
+
+func (x *TrafficPartitionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficgen_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficPartitionRequest.ProtoReflect.Descriptor instead.
+func (*TrafficPartitionRequest) Descriptor() ([]byte, []int) {
+	return file_trafficgen_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TrafficPartitionRequest) GetPartition() string {
+	if x != nil {
+		return x.Partition
+	}
+	return ""
+}
+
+var File_trafficgen_proto protoreflect.FileDescriptor
+
+var file_trafficgen_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0c, 0x73, 0x6b, 0x70, 0x6c, 0x75,
+	0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9c, 0x01, 0x0a, 0x12, 0x54, 0x72, 0x61, 0x66,
+	0x66, 0x69, 0x63, 0x49, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c,
+	0x0a, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x10,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d,
+	0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x68, 0x61, 0x6c, 0x74, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0d, 0x68, 0x61, 0x6c, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x57, 0x0a, 0x16, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69,
+	0x63, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f,
+	0x0a, 0x0b, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0a, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x22,
+	0x8c, 0x01, 0x0a, 0x07, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x74,
+	0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x63, 0x70,
+	0x75, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0d, 0x63, 0x70, 0x75, 0x54, 0x69, 0x6d, 0x65, 0x4d, 0x69, 0x6c, 0x6c,
+	0x69, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x69, 0x6f, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6d, 0x69,
+	0x6c, 0x6c, 0x69, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x69, 0x6f, 0x54, 0x69,
+	0x6d, 0x65, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x73,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x59,
+	0x0a, 0x17, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x08, 0x61, 0x72, 0x72,
+	0x69, 0x76, 0x61, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x52, 0x08, 0x61, 0x72, 0x72,
+	0x69, 0x76, 0x61, 0x6c, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x6f, 0x6e, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x04, 0x64, 0x6f, 0x6e, 0x65, 0x22, 0x37, 0x0a, 0x17, 0x54, 0x72, 0x61,
+	0x66, 0x66, 0x69, 0x63, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x32, 0x80, 0x02, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x47, 0x65,
+	0x6e, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x2f, 0x0a, 0x04, 0x49, 0x6e, 0x69, 0x74, 0x12,
+	0x19, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x49,
+	0x6e, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x49, 0x0a, 0x08, 0x47, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x65, 0x12, 0x1d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x72, 0x61,
+	0x66, 0x66, 0x69, 0x63, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x72, 0x61, 0x66,
+	0x66, 0x69, 0x63, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x06, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x12, 0x1e, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x50, 0x61, 0x72,
+	0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x38, 0x0a, 0x0d, 0x47,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x19, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x50, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_trafficgen_proto_rawDescOnce sync.Once
+	file_trafficgen_proto_rawDescData = file_trafficgen_proto_rawDesc
+)
+
+func file_trafficgen_proto_rawDescGZIP() []byte {
+	file_trafficgen_proto_rawDescOnce.Do(func() {
+		file_trafficgen_proto_rawDescData = protoimpl.X.CompressGZIP(file_trafficgen_proto_rawDescData)
+	})
+	return file_trafficgen_proto_rawDescData
+}
+
+var file_trafficgen_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_trafficgen_proto_goTypes = []interface{}{
+	(*TrafficInitRequest)(nil),      // 0: proto.TrafficInitRequest
+	(*TrafficGenerateRequest)(nil),  // 1: proto.TrafficGenerateRequest
+	(*Arrival)(nil),                 // 2: proto.Arrival
+	(*TrafficGenerateResponse)(nil), // 3: proto.TrafficGenerateResponse
+	(*TrafficPartitionRequest)(nil), // 4: proto.TrafficPartitionRequest
+	(*Empty)(nil),                   // 5: proto.Empty
+	(*PluginTypeResponse)(nil),      // 6: proto.PluginTypeResponse
+}
+var file_trafficgen_proto_depIdxs = []int32{
+	2, // 0: proto.TrafficGenerateResponse.arrivals:type_name -> proto.Arrival
+	0, // 1: proto.TrafficGenerator.Init:input_type -> proto.TrafficInitRequest
+	1, // 2: proto.TrafficGenerator.Generate:input_type -> proto.TrafficGenerateRequest
+	4, // 3: proto.TrafficGenerator.Finish:input_type -> proto.TrafficPartitionRequest
+	5, // 4: proto.TrafficGenerator.GeneratorType:input_type -> proto.Empty
+	5, // 5: proto.TrafficGenerator.Init:output_type -> proto.Empty
+	3, // 6: proto.TrafficGenerator.Generate:output_type -> proto.TrafficGenerateResponse
+	5, // 7: proto.TrafficGenerator.Finish:output_type -> proto.Empty
+	6, // 8: proto.TrafficGenerator.GeneratorType:output_type -> proto.PluginTypeResponse
+	5, // [5:9] is the sub-list for method output_type
+	1, // [1:5] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_trafficgen_proto_init() }
+func file_trafficgen_proto_init() {
+	if File_trafficgen_proto != nil {
+		return
+	}
+	file_skplug_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_trafficgen_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrafficInitRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trafficgen_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrafficGenerateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trafficgen_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Arrival); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trafficgen_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrafficGenerateResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trafficgen_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrafficPartitionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_trafficgen_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_trafficgen_proto_goTypes,
+		DependencyIndexes: file_trafficgen_proto_depIdxs,
+		MessageInfos:      file_trafficgen_proto_msgTypes,
+	}.Build()
+	File_trafficgen_proto = out.File
+	file_trafficgen_proto_rawDesc = nil
+	file_trafficgen_proto_goTypes = nil
+	file_trafficgen_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// TrafficGeneratorClient is the client API for TrafficGenerator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TrafficGeneratorClient interface {
+	Init(ctx context.Context, in *TrafficInitRequest, opts ...grpc.CallOption) (*Empty, error)
+	Generate(ctx context.Context, in *TrafficGenerateRequest, opts ...grpc.CallOption) (*TrafficGenerateResponse, error)
+	Finish(ctx context.Context, in *TrafficPartitionRequest, opts ...grpc.CallOption) (*Empty, error)
+	GeneratorType(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PluginTypeResponse, error)
+}
+
+type trafficGeneratorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTrafficGeneratorClient(cc grpc.ClientConnInterface) TrafficGeneratorClient {
+	return &trafficGeneratorClient{cc}
+}
+
+func (c *trafficGeneratorClient) Init(ctx context.Context, in *TrafficInitRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.TrafficGenerator/Init", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficGeneratorClient) Generate(ctx context.Context, in *TrafficGenerateRequest, opts ...grpc.CallOption) (*TrafficGenerateResponse, error) {
+	out := new(TrafficGenerateResponse)
+	err := c.cc.Invoke(ctx, "/proto.TrafficGenerator/Generate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficGeneratorClient) Finish(ctx context.Context, in *TrafficPartitionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.TrafficGenerator/Finish", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficGeneratorClient) GeneratorType(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PluginTypeResponse, error) {
+	out := new(PluginTypeResponse)
+	err := c.cc.Invoke(ctx, "/proto.TrafficGenerator/GeneratorType", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TrafficGeneratorServer is the server API for TrafficGenerator service.
+type TrafficGeneratorServer interface {
+	Init(context.Context, *TrafficInitRequest) (*Empty, error)
+	Generate(context.Context, *TrafficGenerateRequest) (*TrafficGenerateResponse, error)
+	Finish(context.Context, *TrafficPartitionRequest) (*Empty, error)
+	GeneratorType(context.Context, *Empty) (*PluginTypeResponse, error)
+}
+
+// UnimplementedTrafficGeneratorServer can be embedded to have forward compatible implementations.
+type UnimplementedTrafficGeneratorServer struct {
+}
+
+func (*UnimplementedTrafficGeneratorServer) Init(context.Context, *TrafficInitRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Init not implemented")
+}
+func (*UnimplementedTrafficGeneratorServer) Generate(context.Context, *TrafficGenerateRequest) (*TrafficGenerateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Generate not implemented")
+}
+func (*UnimplementedTrafficGeneratorServer) Finish(context.Context, *TrafficPartitionRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Finish not implemented")
+}
+func (*UnimplementedTrafficGeneratorServer) GeneratorType(context.Context, *Empty) (*PluginTypeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GeneratorType not implemented")
+}
+
+func RegisterTrafficGeneratorServer(s *grpc.Server, srv TrafficGeneratorServer) {
+	s.RegisterService(&_TrafficGenerator_serviceDesc, srv)
+}
+
+func _TrafficGenerator_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrafficInitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficGeneratorServer).Init(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.TrafficGenerator/Init",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficGeneratorServer).Init(ctx, req.(*TrafficInitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficGenerator_Generate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrafficGenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficGeneratorServer).Generate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.TrafficGenerator/Generate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficGeneratorServer).Generate(ctx, req.(*TrafficGenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficGenerator_Finish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrafficPartitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficGeneratorServer).Finish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.TrafficGenerator/Finish",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficGeneratorServer).Finish(ctx, req.(*TrafficPartitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficGenerator_GeneratorType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficGeneratorServer).GeneratorType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.TrafficGenerator/GeneratorType",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficGeneratorServer).GeneratorType(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TrafficGenerator_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.TrafficGenerator",
+	HandlerType: (*TrafficGeneratorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Init",
+			Handler:    _TrafficGenerator_Init_Handler,
+		},
+		{
+			MethodName: "Generate",
+			Handler:    _TrafficGenerator_Generate_Handler,
+		},
+		{
+			MethodName: "Finish",
+			Handler:    _TrafficGenerator_Finish_Handler,
+		},
+		{
+			MethodName: "GeneratorType",
+			Handler:    _TrafficGenerator_GeneratorType_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "trafficgen.proto",
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"skenario/pkg/loadtest"
+	"skenario/pkg/model/trafficpatterns"
+)
+
+// export writes a traffic pattern to stdout as input for a load generator, e.g.
+//
+//	skenario export -pattern spike -config '{"base_rps": 5}' -run-for 10m -format k6
+func export(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	pattern := flags.String("pattern", "", "the traffic pattern, as listed by GET /traffic-patterns")
+	config := flags.String("config", "", "the pattern's config as JSON, or @path to read it from a file")
+	runFor := flags.Duration("run-for", 10*time.Minute, "how long the traffic runs for")
+	startAt := flags.String("start-at", "", "when the traffic starts, in RFC3339; defaults to the Unix epoch")
+	format := flags.String("format", loadtest.FormatCSV, "one of "+strings.Join(loadtest.Formats, ", "))
+	target := flags.String("target", loadtest.DefaultTarget, "the vegeta target")
+	traceDir := flags.String("trace-dir", ".", "the directory that replay traces are read from")
+	flags.Parse(args)
+
+	if *pattern == "plugin" {
+		exitOnError(fmt.Errorf("traffic generator plugins are only loaded by the server; use its POST /export instead"))
+	}
+
+	trafficpatterns.TraceDir = *traceDir
+
+	opts := loadtest.Options{
+		Pattern: *pattern,
+		StartAt: time.Unix(0, 0),
+		RunFor:  *runFor,
+		Format:  *format,
+		Target:  *target,
+	}
+
+	if strings.HasPrefix(*config, "@") {
+		contents, err := ioutil.ReadFile(strings.TrimPrefix(*config, "@"))
+		exitOnError(err)
+		opts.Config = json.RawMessage(contents)
+	} else if *config != "" {
+		opts.Config = json.RawMessage(*config)
+	}
+
+	if *startAt != "" {
+		var err error
+		opts.StartAt, err = time.Parse(time.RFC3339, *startAt)
+		exitOnError(err)
+	}
+
+	exitOnError(loadtest.ExportPattern(os.Stdout, opts))
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err.Error())
+		os.Exit(1)
+	}
+}
@@ -17,6 +17,7 @@ package main
 
 import (
 	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
+	"log"
 	"os"
 	"os/signal"
 
@@ -24,11 +25,23 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		export(os.Args[2:])
+		return
+	}
+
 	sighup := make(chan os.Signal, 1)
 	signal.Notify(sighup, os.Interrupt)
 
-	server := serve.SkenarioServer{IndexRoot: "sim/pkg/serve", Dispatcher: dispatcher.NewDispatcher()}
-	server.Serve()
+	server := serve.SkenarioServer{
+		IndexRoot:         "sim/pkg/serve",
+		Dispatcher:        dispatcher.NewDispatcher(),
+		TrafficDispatcher: dispatcher.NewTrafficDispatcher(),
+	}
+	err := server.Serve()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	<-sighup
 	server.Shutdown()
@@ -29,7 +29,7 @@ select
 		 end)
 	  over summation as tally
 	from completed_movements join stock_aggregate sa on sa.id in (from_stock, to_stock)
-	where kind not in ('start_to_running', 'autoscaler_tick', 'running_to_halted', 'metrics_tick', 'send_metrics_to_pipeline', 'send_metrics_to_sink')
+	where kind not in ('start_to_running', 'autoscaler_tick', 'running_to_halted', 'metrics_tick', 'send_metrics_to_pipeline', 'send_metrics_to_sink', 'drop_metrics', 'metrics_outage', 'rollout_step', 'generate_traffic')
 	and scenario_run_id = ?
     window summation as (partition by sa.name order by occurs_at asc rows unbounded preceding)
 )
@@ -90,3 +90,112 @@ and scenario_run_id = ?
 group by occurs_at_second
 ;
 `
+
+// language=sql
+var CostSeriesQuery = `
+select
+    at
+  , cpu_cores
+  , memory_gb
+  , cost
+from cost_series
+where scenario_run_id = ?
+order by at
+;
+`
+
+// language=sql
+var RequestOutcomesQuery = `
+select
+    min(occurs_at) as arrived_at
+  , max(occurs_at) as completed_at
+  , max(case when s.name = 'RequestsFailed' then 1 else 0 end) as failed
+from completed_movements join stocks s on s.id = to_stock
+where moved in (select id from entities where entities.kind = 'Request')
+  and scenario_run_id = ?
+group by moved
+having max(s.name = 'RequestsFailed' or s.name like 'RequestsComplete%')
+order by completed_at
+;
+`
+
+// language=sql
+var ConstrainedRecommendationsQuery = `
+select
+    recommended_at
+  , recommended
+  , target
+  , constraint_name
+  , actuates_at
+from constrained_recommendations
+where scenario_run_id = ?
+order by recommended_at
+;
+`
+
+// language=sql
+var RevisionTallyQuery = `
+with revision_tally as (
+select
+	  occurs_at
+	, s.name      as stock_name
+	, rr.revision
+	, sum(case
+		   when from_stock = to_stock then 0
+		   when from_stock = s.id then -1
+		   when to_stock = s.id then 1
+		 end)
+	  over summation as tally
+	from completed_movements cm
+	join stocks s on s.id in (from_stock, to_stock)
+	join entities e on e.id = cm.moved
+	join replica_revisions rr on rr.replica_name = e.name and rr.scenario_run_id = cm.scenario_run_id
+	where s.kind_stocked = 'Replica'
+	and s.name in ('ReplicasActive', 'ReplicasTerminating')
+	and cm.scenario_run_id = ?
+    window summation as (partition by s.name, rr.revision order by occurs_at asc rows unbounded preceding)
+)
+select occurs_at
+     , stock_name
+     , revision
+     , tally
+from revision_tally
+order by occurs_at asc, stock_name asc, revision asc
+;
+`
+
+// language=sql
+var AutoscalerDecisionsQuery = `
+select
+    id
+  , tick_at
+  , stats_delivered
+  , current_replicas
+  , desired_replicas
+  , ready_replicas
+  , horizontal_recommendation
+  , horizontal_target
+  , horizontal_applied
+  , delta
+  , vertical_cpu_target
+  , vertical_applied
+from autoscaler_decisions
+where scenario_run_id = ?
+order by tick_at
+;
+`
+
+// language=sql
+var AutoscalerDecisionStatsQuery = `
+select
+    decision_id
+  , stat_at
+  , pod_name
+  , type
+  , name
+  , value
+from autoscaler_decision_stats
+where decision_id in (select id from autoscaler_decisions where scenario_run_id = ?)
+order by decision_id, id
+;
+`
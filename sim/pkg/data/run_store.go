@@ -36,6 +36,12 @@ type RunStore interface {
 		ranFor time.Duration,
 		cpuUtilizations []*simulator.CPUUtilization,
 	) (scenarioRunId int64, err error)
+	StoreService(scenarioRunId int64, groupRunId int64, serviceName string) error
+	StoreCost(scenarioRunId int64, costConf model.CostConfig, cost model.CostReport) error
+	StoreConstrainedDecisions(scenarioRunId int64, decisions []model.HorizontalDecision) error
+	StoreReplicaRevisions(scenarioRunId int64, revisions map[simulator.EntityName]int) error
+	StoreRequestClasses(scenarioRunId int64, classes map[simulator.EntityName]string) error
+	StoreDecisions(scenarioRunId int64, decisions []model.AutoscalerDecision) error
 }
 
 type storer struct {
@@ -254,6 +260,182 @@ func (s *storer) scenarioData(scenarioRunId int64) error {
 	return nil
 }
 
+// StoreService records that a scenario run simulated one of several services, grouped under the first service's run.
+func (s *storer) StoreService(scenarioRunId int64, groupRunId int64, serviceName string) error {
+	svcStmt, err := s.conn.Prepare(`insert into service_runs(scenario_run_id, group_run_id, service_name) values (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer svcStmt.Close()
+
+	return svcStmt.Exec(scenarioRunId, groupRunId, serviceName)
+}
+
+// StoreCost records the total cost of a scenario run and how it accrued.
+func (s *storer) StoreCost(scenarioRunId int64, costConf model.CostConfig, cost model.CostReport) error {
+	return s.conn.WithTx(func() error {
+		costStmt, err := s.conn.Prepare(`insert into run_costs(
+			scenario_run_id
+		  , cpu_core_hour_price
+		  , memory_gb_hour_price
+		  , cpu_core_hours
+		  , memory_gb_hours
+		  , total_cost
+		) values (?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer costStmt.Close()
+
+		err = costStmt.Exec(scenarioRunId, costConf.CPUCoreHourPrice, costConf.MemoryGBHourPrice, cost.CPUCoreHours, cost.MemoryGBHours, cost.Total)
+		if err != nil {
+			return err
+		}
+
+		seriesStmt, err := s.conn.Prepare(`insert into cost_series(at, cpu_cores, memory_gb, cost, scenario_run_id) values (?, ?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer seriesStmt.Close()
+
+		for _, point := range cost.Series {
+			err = seriesStmt.Exec(point.At.UnixNano(), point.CPUCores, point.MemoryGB, point.Cost, scenarioRunId)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// StoreConstrainedDecisions records the autoscaler's recommendations which were bounded or delayed.
+func (s *storer) StoreConstrainedDecisions(scenarioRunId int64, decisions []model.HorizontalDecision) error {
+	return s.conn.WithTx(func() error {
+		decisionStmt, err := s.conn.Prepare(`insert into constrained_recommendations(
+			recommended_at
+		  , recommended
+		  , target
+		  , constraint_name
+		  , actuates_at
+		  , scenario_run_id
+		) values (?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer decisionStmt.Close()
+
+		for _, d := range decisions {
+			err = decisionStmt.Exec(d.At.UnixNano(), int(d.Recommended), int(d.Target), string(d.Constraint), d.ActuatesAt.UnixNano(), scenarioRunId)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// StoreReplicaRevisions records which revision each replica ran, so that replicas can be tallied by revision.
+func (s *storer) StoreReplicaRevisions(scenarioRunId int64, revisions map[simulator.EntityName]int) error {
+	return s.conn.WithTx(func() error {
+		revisionStmt, err := s.conn.Prepare(`insert into replica_revisions(scenario_run_id, replica_name, revision) values (?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer revisionStmt.Close()
+
+		for name, revision := range revisions {
+			err = revisionStmt.Exec(scenarioRunId, string(name), revision)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// StoreRequestClasses records the class of each request which had one, e.g. the endpoint of a replayed request.
+func (s *storer) StoreRequestClasses(scenarioRunId int64, classes map[simulator.EntityName]string) error {
+	return s.conn.WithTx(func() error {
+		classStmt, err := s.conn.Prepare(`insert into request_classes(scenario_run_id, request_name, class) values (?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer classStmt.Close()
+
+		for name, class := range classes {
+			err = classStmt.Exec(scenarioRunId, string(name), class)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// StoreDecisions records what the autoscaler was told and what it decided on every tick.
+func (s *storer) StoreDecisions(scenarioRunId int64, decisions []model.AutoscalerDecision) error {
+	return s.conn.WithTx(func() error {
+		decisionStmt, err := s.conn.Prepare(`insert into autoscaler_decisions(
+			tick_at
+		  , stats_delivered
+		  , current_replicas
+		  , desired_replicas
+		  , ready_replicas
+		  , horizontal_recommendation
+		  , horizontal_target
+		  , horizontal_applied
+		  , delta
+		  , vertical_cpu_target
+		  , vertical_applied
+		  , scenario_run_id
+		) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer decisionStmt.Close()
+
+		statStmt, err := s.conn.Prepare(`insert into autoscaler_decision_stats(decision_id, stat_at, pod_name, type, name, value) values (?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer statStmt.Close()
+
+		for _, d := range decisions {
+			err = decisionStmt.Exec(
+				d.At.UnixNano(),
+				len(d.Stats),
+				int(d.CurrentReplicas),
+				int(d.DesiredReplicas),
+				int(d.ReadyReplicas),
+				int(d.HorizontalRecommendation),
+				int(d.HorizontalTarget),
+				d.HorizontalApplied,
+				int(d.Delta),
+				d.VerticalCPUTarget,
+				d.VerticalApplied,
+				scenarioRunId,
+			)
+			if err != nil {
+				return err
+			}
+
+			decisionId := s.conn.LastInsertRowID()
+			for _, stat := range d.Stats {
+				err = statStmt.Exec(decisionId, stat.Time, stat.PodName, stat.Type.String(), stat.Name, int(stat.Value))
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
 func NewRunStore(conn *sqlite3.Conn) RunStore {
 	err := conn.Exec(Schema)
 	if err != nil {
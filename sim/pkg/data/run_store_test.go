@@ -18,6 +18,7 @@ package data
 import (
 	"context"
 	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
 	"os"
 	"path/filepath"
 	"testing"
@@ -274,6 +275,166 @@ func testStorer(t *testing.T, describe spec.G, it spec.S) {
 				assert.Equal(t, "ScheduledToOccurAfterHalt", reason)
 			})
 		})
+
+		describe("StoreService()", func() {
+			var secondRunId int64
+			var serviceName string
+			var groupRunId int64
+			var count int
+
+			it.Before(func() {
+				err = subject.StoreService(scenarioRunId, scenarioRunId, "frontend")
+				assert.NoError(t, err)
+
+				secondRunId, err = subject.Store(completed, ignored, clusterConf, kpaConf, "test_origin", "test_pattern", 10*time.Minute, env.CPUUtilizations())
+				assert.NoError(t, err)
+				err = subject.StoreService(secondRunId, scenarioRunId, "backend")
+				assert.NoError(t, err)
+
+				singleQuery(t, conn, `select count(1) from service_runs where group_run_id = 1`, &count)
+				singleQuery(t, conn, `select service_name, group_run_id from service_runs where scenario_run_id = 2`, &serviceName, &groupRunId)
+			})
+
+			it("groups the runs of each service", func() {
+				assert.Equal(t, 2, count)
+			})
+
+			it("records the service name and its group", func() {
+				assert.Equal(t, "backend", serviceName)
+				assert.Equal(t, int64(1), groupRunId)
+			})
+		})
+
+		describe("StoreCost()", func() {
+			var totalCost, cpuCoreHourPrice float64
+			var points int
+			var lastAt int64
+			var lastCost float64
+
+			it.Before(func() {
+				err = subject.StoreCost(scenarioRunId, model.CostConfig{CPUCoreHourPrice: 0.05}, model.CostReport{
+					CPUCoreHours: 2,
+					Total:        0.1,
+					Series: []model.CostPoint{
+						{At: time.Unix(0, 10), CPUCores: 1, Cost: 0.04},
+						{At: time.Unix(0, 20), CPUCores: 2, Cost: 0.1},
+					},
+				})
+				assert.NoError(t, err)
+
+				singleQuery(t, conn, `select total_cost, cpu_core_hour_price from run_costs where scenario_run_id = 1`, &totalCost, &cpuCoreHourPrice)
+				singleQuery(t, conn, `select count(1) from cost_series where scenario_run_id = 1`, &points)
+				singleQuery(t, conn, `select at, cost from cost_series where scenario_run_id = 1 order by at desc limit 1`, &lastAt, &lastCost)
+			})
+
+			it("records the total and the prices it was worked out with", func() {
+				assert.Equal(t, 0.1, totalCost)
+				assert.Equal(t, 0.05, cpuCoreHourPrice)
+			})
+
+			it("records the series", func() {
+				assert.Equal(t, 2, points)
+				assert.Equal(t, int64(20), lastAt)
+				assert.Equal(t, 0.1, lastCost)
+			})
+		})
+
+		describe("StoreDecisions()", func() {
+			var statsDelivered, delta, statValue int
+			var applied bool
+			var statType, podName string
+
+			it.Before(func() {
+				err = subject.StoreDecisions(scenarioRunId, []model.AutoscalerDecision{{
+					At:                       time.Unix(0, 10),
+					Stats:                    []*proto.Stat{{Time: 5, PodName: "replica-1", Type: proto.MetricType_CPU_MILLIS, Value: 250}},
+					DesiredReplicas:          1,
+					HorizontalRecommendation: 3,
+					HorizontalTarget:         3,
+					HorizontalApplied:        true,
+					Delta:                    2,
+				}})
+				assert.NoError(t, err)
+
+				singleQuery(t, conn, `select stats_delivered, delta, horizontal_applied from autoscaler_decisions where scenario_run_id = 1`, &statsDelivered, &delta, &applied)
+				singleQuery(t, conn, `select pod_name, type, value from autoscaler_decision_stats where decision_id = (select id from autoscaler_decisions where scenario_run_id = 1)`, &podName, &statType, &statValue)
+			})
+
+			it("records the decision", func() {
+				assert.Equal(t, 1, statsDelivered)
+				assert.Equal(t, 2, delta)
+				assert.True(t, applied)
+			})
+
+			it("records the stats it was based on", func() {
+				assert.Equal(t, "replica-1", podName)
+				assert.Equal(t, "CPU_MILLIS", statType)
+				assert.Equal(t, 250, statValue)
+			})
+		})
+
+		describe("StoreReplicaRevisions()", func() {
+			var revision, count int
+
+			it.Before(func() {
+				err = subject.StoreReplicaRevisions(scenarioRunId, map[simulator.EntityName]int{"replica-1": 1, "replica-2": 2})
+				assert.NoError(t, err)
+
+				singleQuery(t, conn, `select count(1) from replica_revisions where scenario_run_id = 1`, &count)
+				singleQuery(t, conn, `select revision from replica_revisions where scenario_run_id = 1 and replica_name = 'replica-2'`, &revision)
+			})
+
+			it("records the revision of each replica", func() {
+				assert.Equal(t, 2, count)
+				assert.Equal(t, 2, revision)
+			})
+		})
+
+		describe("StoreRequestClasses()", func() {
+			var class string
+			var count int
+
+			it.Before(func() {
+				err = subject.StoreRequestClasses(scenarioRunId, map[simulator.EntityName]string{"request-1": "checkout", "request-2": "browse"})
+				assert.NoError(t, err)
+
+				singleQuery(t, conn, `select count(1) from request_classes where scenario_run_id = 1`, &count)
+				singleQuery(t, conn, `select class from request_classes where scenario_run_id = 1 and request_name = 'request-2'`, &class)
+			})
+
+			it("records the class of each request", func() {
+				assert.Equal(t, 2, count)
+				assert.Equal(t, "browse", class)
+			})
+		})
+
+		describe("StoreConstrainedDecisions()", func() {
+			var recommendedAt, actuatesAt int64
+			var recommended, target int
+			var constraint string
+
+			it.Before(func() {
+				err = subject.StoreConstrainedDecisions(scenarioRunId, []model.HorizontalDecision{{
+					At:          time.Unix(0, 10),
+					Recommended: 12,
+					Target:      8,
+					Constraint:  model.ConstraintMaxReplicas,
+					ActuatesAt:  time.Unix(0, 30),
+				}})
+				assert.NoError(t, err)
+
+				singleQuery(t, conn, `select recommended_at, recommended, target, constraint_name, actuates_at from constrained_recommendations where scenario_run_id = 1`,
+					&recommendedAt, &recommended, &target, &constraint, &actuatesAt)
+			})
+
+			it("records the recommendation and what was done with it", func() {
+				assert.Equal(t, int64(10), recommendedAt)
+				assert.Equal(t, 12, recommended)
+				assert.Equal(t, 8, target)
+				assert.Equal(t, "max_replicas", constraint)
+				assert.Equal(t, int64(30), actuatesAt)
+			})
+		})
 	})
 }
 
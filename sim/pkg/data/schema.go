@@ -35,6 +35,13 @@ var Schema = `create table if not exists scenario_runs
     autoscaler_tick_interval                 big integer not null
 );
 
+create table if not exists service_runs
+(
+    scenario_run_id integer primary key references scenario_runs (id),
+    group_run_id    integer not null references scenario_runs (id), -- the run of the first service simulated alongside
+    service_name    text    not null
+);
+
 create table if not exists stocks
 (
     id           integer primary key, -- aliases to rowid
@@ -73,6 +80,86 @@ create table if not exists cpu_utilizations
 	scenario_run_id 	integer not null references scenario_runs (id)
 );
 
+create table if not exists run_costs
+(
+    scenario_run_id      integer primary key references scenario_runs (id),
+    cpu_core_hour_price  real not null,
+    memory_gb_hour_price real not null,
+    cpu_core_hours       real not null,
+    memory_gb_hours      real not null,
+    total_cost           real not null
+);
+
+create table if not exists cost_series
+(
+    id              integer primary key,  -- aliases to rowid
+    at              unsigned big integer not null,
+    cpu_cores       real                 not null, -- averaged over the interval ending at
+    memory_gb       real                 not null,
+    cost            real                 not null, -- cumulative
+
+    scenario_run_id integer not null references scenario_runs (id)
+);
+
+create table if not exists constrained_recommendations
+(
+    id              integer primary key,  -- aliases to rowid
+    recommended_at  unsigned big integer not null,
+    recommended     integer              not null,
+    target          integer              not null,
+    constraint_name text                 not null, -- empty when the recommendation was only delayed
+    actuates_at     unsigned big integer not null,
+
+    scenario_run_id integer not null references scenario_runs (id)
+);
+
+create table if not exists replica_revisions
+(
+    scenario_run_id integer not null references scenario_runs (id),
+    replica_name    text    not null,
+    revision        integer not null,
+
+    primary key (scenario_run_id, replica_name)
+);
+
+create table if not exists request_classes
+(
+    scenario_run_id integer not null references scenario_runs (id),
+    request_name    text    not null,
+    class           text    not null,
+
+    primary key (scenario_run_id, request_name)
+);
+
+create table if not exists autoscaler_decisions
+(
+    id                        integer primary key, -- aliases to rowid
+    tick_at                   unsigned big integer not null,
+    stats_delivered           integer              not null,
+    current_replicas          integer              not null,
+    desired_replicas          integer              not null,
+    ready_replicas            integer              not null,
+    horizontal_recommendation integer              not null,
+    horizontal_target         integer              not null,
+    horizontal_applied        boolean              not null,
+    delta                     integer              not null,
+    vertical_cpu_target       integer              not null, -- 0 when there was no recommendation
+    vertical_applied          boolean              not null,
+
+    scenario_run_id           integer not null references scenario_runs (id)
+);
+
+create table if not exists autoscaler_decision_stats
+(
+    id          integer primary key, -- aliases to rowid
+    decision_id integer              not null references autoscaler_decisions (id),
+    stat_at     unsigned big integer not null,
+    pod_name    text                 not null,
+    type        text                 not null,
+    name        text                 not null, -- only set for metric aliases
+    value       integer              not null
+);
+
 create unique index if not exists move_once_per_run on completed_movements (occurs_at, scenario_run_id);
 
 create table if not exists ignored_movements
@@ -93,6 +180,7 @@ create view if not exists stock_aggregate as
 select id
      , (case
             when name like 'RequestsProcessing%' then 'RequestsProcessing'
+            when name like 'RequestsAwaitingCalls%' then 'RequestsAwaitingCalls'
             else name
     end) as name
      , (case
@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package loadtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FormatVegeta is a shell script that runs vegeta at each second's rate in turn.
+	FormatVegeta = "vegeta"
+	// FormatK6 is k6 options with a ramping-arrival-rate scenario of one-second stages.
+	FormatK6 = "k6"
+	// FormatCSV is the number of requests in each second.
+	FormatCSV = "csv"
+)
+
+// DefaultTarget is the vegeta target used when none is given.
+const DefaultTarget = "GET http://localhost:8080/"
+
+var Formats = []string{FormatVegeta, FormatK6, FormatCSV}
+
+func knownFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType is the media type of an export format.
+func ContentType(format string) string {
+	switch format {
+	case FormatK6:
+		return "application/json"
+	case FormatCSV:
+		return "text/csv"
+	default:
+		return "text/x-shellscript"
+	}
+}
+
+// Export writes the per-second request counts of a run in one of the formats. The target is only used
+// by vegeta, which sends every request to it.
+func Export(w io.Writer, format string, perSecond []int, target string) error {
+	switch format {
+	case FormatVegeta:
+		return writeVegeta(w, perSecond, target)
+	case FormatK6:
+		return writeK6(w, perSecond)
+	case FormatCSV:
+		return writeCSV(w, perSecond)
+	default:
+		return fmt.Errorf("unknown export format '%s', expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// rateRun is a number of consecutive seconds with the same request count.
+type rateRun struct {
+	rps     int
+	seconds int
+}
+
+func rateRuns(perSecond []int) []rateRun {
+	runs := make([]rateRun, 0)
+	for _, rps := range perSecond {
+		if len(runs) > 0 && runs[len(runs)-1].rps == rps {
+			runs[len(runs)-1].seconds++
+			continue
+		}
+		runs = append(runs, rateRun{rps: rps, seconds: 1})
+	}
+	return runs
+}
+
+func writeVegeta(w io.Writer, perSecond []int, target string) error {
+	if target == "" {
+		target = DefaultTarget
+	}
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString("# Sends requests at the rate of each second of a Skenario traffic pattern. Results go to results.bin,\n")
+	script.WriteString("# e.g. for 'vegeta report results.bin'.\n")
+	script.WriteString("set -e\n")
+	fmt.Fprintf(&script, "TARGET=${TARGET:-%s}\n", shellQuote(target))
+	script.WriteString("attack() {\n")
+	script.WriteString("  echo \"$TARGET\" | vegeta attack -rate=\"$1/1s\" -duration=\"$2s\" >> results.bin\n")
+	script.WriteString("}\n")
+	script.WriteString(": > results.bin\n")
+	for _, run := range rateRuns(perSecond) {
+		if run.rps == 0 {
+			fmt.Fprintf(&script, "sleep %d\n", run.seconds)
+		} else {
+			fmt.Fprintf(&script, "attack %d %d\n", run.rps, run.seconds)
+		}
+	}
+
+	_, err := io.WriteString(w, script.String())
+	return err
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+type k6Options struct {
+	Scenarios map[string]k6Scenario `json:"scenarios"`
+}
+
+type k6Scenario struct {
+	Executor        string    `json:"executor"`
+	StartRate       int       `json:"startRate"`
+	TimeUnit        string    `json:"timeUnit"`
+	PreAllocatedVUs int       `json:"preAllocatedVUs"`
+	Stages          []k6Stage `json:"stages"`
+}
+
+type k6Stage struct {
+	Target   int    `json:"target"`
+	Duration string `json:"duration"`
+}
+
+func writeK6(w io.Writer, perSecond []int) error {
+	scenario := k6Scenario{
+		Executor:        "ramping-arrival-rate",
+		TimeUnit:        "1s",
+		PreAllocatedVUs: 1,
+		Stages:          make([]k6Stage, 0),
+	}
+	if len(perSecond) > 0 {
+		scenario.StartRate = perSecond[0]
+	}
+
+	// k6 ramps linearly over each stage, so a run of equal seconds is a quick step to the rate, then a hold.
+	for _, run := range rateRuns(perSecond) {
+		scenario.Stages = append(scenario.Stages, k6Stage{Target: run.rps, Duration: "1s"})
+		if run.seconds > 1 {
+			scenario.Stages = append(scenario.Stages, k6Stage{Target: run.rps, Duration: fmt.Sprintf("%ds", run.seconds-1)})
+		}
+		if run.rps > scenario.PreAllocatedVUs {
+			scenario.PreAllocatedVUs = run.rps
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(k6Options{Scenarios: map[string]k6Scenario{"skenario": scenario}})
+}
+
+func writeCSV(w io.Writer, perSecond []int) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"second", "requests"})
+	if err != nil {
+		return err
+	}
+	for second, requests := range perSecond {
+		err = writer.Write([]string{strconv.Itoa(second), strconv.Itoa(requests)})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
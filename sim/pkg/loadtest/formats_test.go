@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package loadtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormats(t *testing.T) {
+	spec.Run(t, "Export formats", testFormats, spec.Report(report.Terminal{}))
+}
+
+func testFormats(t *testing.T, describe spec.G, it spec.S) {
+	var buf *bytes.Buffer
+	perSecond := []int{0, 0, 5, 5, 5, 8}
+
+	it.Before(func() {
+		buf = new(bytes.Buffer)
+	})
+
+	describe("vegeta", func() {
+		it.Before(func() {
+			assert.NoError(t, Export(buf, FormatVegeta, perSecond, "POST http://staging/it's"))
+		})
+
+		it("is a shell script", func() {
+			assert.True(t, strings.HasPrefix(buf.String(), "#!/bin/sh\n"))
+		})
+
+		it("quotes the target", func() {
+			assert.Contains(t, buf.String(), `TARGET=${TARGET:-'POST http://staging/it'\''s'}`)
+		})
+
+		it("sleeps through seconds without requests and attacks at the rate of the rest", func() {
+			assert.True(t, strings.HasSuffix(buf.String(), "sleep 2\nattack 5 3\nattack 8 1\n"))
+		})
+
+		it("uses the default target when none is given", func() {
+			buf.Reset()
+			assert.NoError(t, Export(buf, FormatVegeta, perSecond, ""))
+			assert.Contains(t, buf.String(), DefaultTarget)
+		})
+	})
+
+	describe("k6", func() {
+		var options k6Options
+
+		it.Before(func() {
+			assert.NoError(t, Export(buf, FormatK6, perSecond, ""))
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &options))
+		})
+
+		it("is a ramping arrival rate scenario", func() {
+			scenario := options.Scenarios["skenario"]
+			assert.Equal(t, "ramping-arrival-rate", scenario.Executor)
+			assert.Equal(t, "1s", scenario.TimeUnit)
+			assert.Equal(t, 0, scenario.StartRate)
+			assert.Equal(t, 8, scenario.PreAllocatedVUs)
+		})
+
+		it("steps to each rate and holds it", func() {
+			assert.Equal(t, []k6Stage{
+				{Target: 0, Duration: "1s"},
+				{Target: 0, Duration: "1s"},
+				{Target: 5, Duration: "1s"},
+				{Target: 5, Duration: "2s"},
+				{Target: 8, Duration: "1s"},
+			}, options.Scenarios["skenario"].Stages)
+		})
+	})
+
+	describe("csv", func() {
+		it("has the requests in each second", func() {
+			assert.NoError(t, Export(buf, FormatCSV, perSecond, ""))
+			assert.Equal(t, "second,requests\n0,0\n1,0\n2,5\n3,5\n4,5\n5,8\n", buf.String())
+		})
+	})
+
+	describe("unknown formats", func() {
+		it("are an error", func() {
+			assert.Error(t, Export(buf, "jmeter", perSecond, ""))
+		})
+	})
+
+	describe("ContentType()", func() {
+		it("matches the format", func() {
+			assert.Equal(t, "text/x-shellscript", ContentType(FormatVegeta))
+			assert.Equal(t, "application/json", ContentType(FormatK6))
+			assert.Equal(t, "text/csv", ContentType(FormatCSV))
+		})
+	})
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug"
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+
+	"skenario/pkg/model"
+	"skenario/pkg/model/trafficpatterns"
+	"skenario/pkg/plugin"
+	"skenario/pkg/simulator"
+)
+
+// Options choose a registered traffic pattern and how to export it.
+type Options struct {
+	Pattern string
+	Config  json.RawMessage
+	StartAt time.Time
+	RunFor  time.Duration
+	Format  string
+	Target  string
+	// TrafficGenerator finds out-of-process traffic generators for the "plugin" pattern. It may be nil.
+	TrafficGenerator func(generatorType string) skplug.TrafficGenerator
+}
+
+// ExportPattern records a registered pattern and writes its requests per second in the chosen format.
+func ExportPattern(w io.Writer, opts Options) error {
+	if !knownFormat(opts.Format) {
+		return fmt.Errorf("unknown export format '%s', expected one of %s", opts.Format, strings.Join(Formats, ", "))
+	}
+	if opts.RunFor <= 0 {
+		return fmt.Errorf("the run must have a positive length, got %v", opts.RunFor)
+	}
+	if _, err := trafficpatterns.ReadConfig(opts.Pattern, opts.Config); err != nil {
+		return err
+	}
+
+	arrivals, err := recordPattern(opts)
+	if err != nil {
+		return err
+	}
+	return Export(w, opts.Format, PerSecond(arrivals, opts.RunFor), opts.Target)
+}
+
+// recordPattern returns the panics that patterns raise while being built or generated as errors, e.g. for
+// a plugin generator that isn't loaded or a trace that can't be read.
+func recordPattern(opts Options) (arrivals []time.Duration, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("could not generate the '%s' traffic pattern: %v", opts.Pattern, r)
+		}
+	}()
+
+	return Record(opts.StartAt, opts.RunFor, func(wiring trafficpatterns.Wiring) trafficpatterns.Pattern {
+		wiring.TrafficGenerator = opts.TrafficGenerator
+		return trafficpatterns.Build(wiring, opts.Pattern, opts.Config)
+	}), nil
+}
+
+// Record generates a pattern's traffic on its own, with no cluster to serve it, and returns when each
+// request arrives as an offset from startAt, in order. The pattern's other movements, such as virtual
+// users joining, are run in time order so that the requests they send are recorded too. Closed-loop
+// users wait for responses that only a simulation gives, so only their first requests are recorded.
+func Record(startAt time.Time, runFor time.Duration, build func(w trafficpatterns.Wiring) trafficpatterns.Pattern) []time.Duration {
+	env := &recordingEnvironment{
+		startAt:   startAt,
+		current:   startAt,
+		haltAt:    startAt.Add(runFor),
+		scheduled: simulator.NewMovementPriorityQueue(),
+	}
+	routingStock := model.NewRequestsRoutingStock(env, model.NewReplicasActiveStock(env), simulator.NewSinkStock("RequestsFailed", "Request"))
+
+	build(trafficpatterns.Wiring{
+		Env:          env,
+		Source:       model.NewTrafficSource(env, routingStock, model.RequestConfig{}),
+		RoutingStock: routingStock,
+	}).Generate()
+	env.runScheduled()
+
+	sort.Slice(env.arrivals, func(i, j int) bool {
+		return env.arrivals[i] < env.arrivals[j]
+	})
+	return env.arrivals
+}
+
+// PerSecond counts the arrivals in each second of the run.
+func PerSecond(arrivals []time.Duration, runFor time.Duration) []int {
+	seconds := int((runFor + time.Second - 1) / time.Second)
+	counts := make([]int, seconds)
+	for _, at := range arrivals {
+		second := int(at / time.Second)
+		if second >= 0 && second < seconds {
+			counts[second]++
+		}
+	}
+	return counts
+}
+
+// recordingEnvironment keeps the arrival times of the requests a pattern schedules. Requests are never
+// run, as there is nothing to serve them, but every other movement is.
+type recordingEnvironment struct {
+	startAt   time.Time
+	current   time.Time
+	haltAt    time.Time
+	arrivals  []time.Duration
+	scheduled simulator.MovementPriorityQueue
+	pending   int // the queue blocks when empty, so it is only dequeued while movements are pending
+}
+
+// runScheduled runs the scheduled movements in time order, including any they schedule in turn.
+func (re *recordingEnvironment) runScheduled() {
+	for re.pending > 0 {
+		movement, err, _ := re.scheduled.DequeueMovement()
+		if err != nil {
+			panic(err)
+		}
+		re.pending--
+
+		re.current = movement.OccursAt()
+		moved := movement.From().Remove(movement.WhatToMove())
+		if moved != nil {
+			err := movement.To().Add(moved)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// Plugin gives a partition which ignores events and recommends nothing, as there is no autoscaler.
+func (re *recordingEnvironment) Plugin() plugin.PluginPartition {
+	return noAutoscalerPartition{}
+}
+
+func (re *recordingEnvironment) AddToSchedule(movement simulator.Movement) (added bool) {
+	if !movement.OccursAt().After(re.current) || !movement.OccursAt().Before(re.haltAt) {
+		return false
+	}
+	if movement.Kind() == "arrive_at_routing_stock" {
+		re.arrivals = append(re.arrivals, movement.OccursAt().Sub(re.startAt))
+		return true
+	}
+
+	_, _, err := re.scheduled.EnqueueMovement(movement)
+	if err != nil {
+		panic(err)
+	}
+	re.pending++
+	return true
+}
+
+func (re *recordingEnvironment) RemoveFromSchedule(movement simulator.Movement) (removed bool) {
+	removed, err := re.scheduled.RemoveMovement(movement)
+	if err != nil {
+		panic(err)
+	}
+	if removed {
+		re.pending--
+	}
+	return removed
+}
+
+func (re *recordingEnvironment) Run() (completed []simulator.CompletedMovement, ignored []simulator.IgnoredMovement, err error) {
+	return nil, nil, nil
+}
+
+func (re *recordingEnvironment) CurrentMovementTime() time.Time {
+	return re.current
+}
+
+func (re *recordingEnvironment) HaltTime() time.Time {
+	return re.haltAt
+}
+
+func (re *recordingEnvironment) Context() context.Context {
+	return context.Background()
+}
+
+func (re *recordingEnvironment) CPUUtilizations() []*simulator.CPUUtilization {
+	return nil
+}
+
+func (re *recordingEnvironment) AppendCPUUtilization(cpuUtilization *simulator.CPUUtilization) {
+}
+
+type noAutoscalerPartition struct{}
+
+func (noAutoscalerPartition) Event(time int64, typ proto.EventType, object skplug.Object) error {
+	return nil
+}
+
+func (noAutoscalerPartition) Stat(stat []*proto.Stat) error {
+	return nil
+}
+
+func (noAutoscalerPartition) HorizontalRecommendation(time int64) (rec int32, err error) {
+	return 0, nil
+}
+
+func (noAutoscalerPartition) VerticalRecommendation(time int64) (rec []*proto.RecommendedPodResources, err error) {
+	return []*proto.RecommendedPodResources{}, nil
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package loadtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skenario/pkg/model/trafficpatterns"
+	"skenario/pkg/simulator"
+)
+
+// orderedStock records the order entities were added to it in.
+type orderedStock struct {
+	simulator.ThroughStock
+	added []simulator.Entity
+}
+
+func (ors *orderedStock) Add(entity simulator.Entity) error {
+	ors.added = append(ors.added, entity)
+	return ors.ThroughStock.Add(entity)
+}
+
+func TestLoadtest(t *testing.T) {
+	spec.Run(t, "Load test export", testLoadtest, spec.Report(report.Terminal{}))
+}
+
+func testLoadtest(t *testing.T, describe spec.G, it spec.S) {
+	startAt := time.Unix(0, 0)
+
+	describe("Record()", func() {
+		var arrivals []time.Duration
+
+		it.Before(func() {
+			arrivals = Record(startAt, 10*time.Second, func(w trafficpatterns.Wiring) trafficpatterns.Pattern {
+				return trafficpatterns.NewStep(w.Env, w.Source, w.RoutingStock, trafficpatterns.StepConfig{RPS: 5, StepAfter: 4 * time.Second})
+			})
+		})
+
+		it("records each request the pattern sends", func() {
+			assert.Len(t, arrivals, 30)
+		})
+
+		it("records arrivals as offsets from the start, in order", func() {
+			assert.True(t, arrivals[0] >= 4*time.Second)
+			for i := 1; i < len(arrivals); i++ {
+				assert.True(t, arrivals[i-1] <= arrivals[i])
+			}
+			assert.True(t, arrivals[len(arrivals)-1] < 10*time.Second)
+		})
+	})
+
+	describe("Record() with closed-loop users", func() {
+		var arrivals []time.Duration
+
+		it.Before(func() {
+			arrivals = Record(startAt, 10*time.Second, func(w trafficpatterns.Wiring) trafficpatterns.Pattern {
+				return trafficpatterns.NewClosedLoop(w.Env, w.Source, w.RoutingStock, trafficpatterns.ClosedLoopConfig{
+					Users: []trafficpatterns.VirtualUsersStep{{After: 0, Users: 2}, {After: 5 * time.Second, Users: 3}},
+				})
+			})
+		})
+
+		it("runs the users joining and records their first requests", func() {
+			require.Len(t, arrivals, 3)
+			assert.True(t, arrivals[0] < time.Second)
+			assert.True(t, arrivals[1] < time.Second)
+			assert.True(t, arrivals[2] >= 5*time.Second)
+		})
+	})
+
+	describe("recordingEnvironment", func() {
+		var env *recordingEnvironment
+		var stock *orderedStock
+		var entities []simulator.Entity
+
+		it.Before(func() {
+			env = &recordingEnvironment{
+				startAt:   startAt,
+				current:   startAt,
+				haltAt:    startAt.Add(10 * time.Second),
+				scheduled: simulator.NewMovementPriorityQueue(),
+			}
+			stock = &orderedStock{ThroughStock: simulator.NewArrayThroughStock("Stock", "Thing")}
+			entities = nil
+			for i := 1; i <= 3; i++ {
+				entity := simulator.NewEntity(simulator.EntityName(fmt.Sprintf("thing-%d", i)), "Thing")
+				require.NoError(t, stock.ThroughStock.Add(entity))
+				entities = append(entities, entity)
+			}
+		})
+
+		move := func(entity simulator.Entity, after time.Duration) simulator.Movement {
+			return simulator.NewMovement("move_thing", startAt.Add(after), stock, stock, &entity)
+		}
+
+		it("runs scheduled movements in time order", func() {
+			assert.True(t, env.AddToSchedule(move(entities[0], 3*time.Second)))
+			assert.True(t, env.AddToSchedule(move(entities[2], 1*time.Second)))
+			assert.True(t, env.AddToSchedule(move(entities[1], 2*time.Second)))
+			env.runScheduled()
+
+			assert.Equal(t, []simulator.Entity{entities[2], entities[1], entities[0]}, stock.added)
+			assert.Equal(t, startAt.Add(3*time.Second), env.CurrentMovementTime())
+		})
+
+		it("does not run movements removed from the schedule", func() {
+			removed := move(entities[0], 3*time.Second)
+			env.AddToSchedule(move(entities[1], 1*time.Second))
+			env.AddToSchedule(removed)
+			assert.True(t, env.RemoveFromSchedule(removed))
+			env.runScheduled()
+
+			assert.Equal(t, []simulator.Entity{entities[1]}, stock.added)
+		})
+
+		it("gives a plugin partition which recommends nothing", func() {
+			require.NotNil(t, env.Plugin())
+			rec, err := env.Plugin().HorizontalRecommendation(0)
+			assert.NoError(t, err)
+			assert.Equal(t, int32(0), rec)
+		})
+	})
+
+	describe("PerSecond()", func() {
+		it("counts the arrivals in each second", func() {
+			arrivals := []time.Duration{100 * time.Millisecond, 900 * time.Millisecond, 2500 * time.Millisecond}
+			assert.Equal(t, []int{2, 0, 1}, PerSecond(arrivals, 3*time.Second))
+		})
+
+		it("includes a last partial second", func() {
+			assert.Len(t, PerSecond(nil, 2500*time.Millisecond), 3)
+		})
+	})
+
+	describe("ExportPattern()", func() {
+		var buf *bytes.Buffer
+		var opts Options
+
+		it.Before(func() {
+			buf = new(bytes.Buffer)
+			opts = Options{
+				Pattern: "step",
+				Config:  json.RawMessage(`{"rps": 2, "step_after": 1000000000}`),
+				StartAt: startAt,
+				RunFor:  3 * time.Second,
+				Format:  FormatCSV,
+			}
+		})
+
+		it("writes the pattern's requests per second", func() {
+			assert.NoError(t, ExportPattern(buf, opts))
+			assert.Equal(t, "second,requests\n0,0\n1,2\n2,2\n", buf.String())
+		})
+
+		it("returns an error for unknown formats", func() {
+			opts.Format = "jmeter"
+			assert.Error(t, ExportPattern(buf, opts))
+		})
+
+		it("returns an error for unknown patterns", func() {
+			opts.Pattern = "fractal"
+			assert.Error(t, ExportPattern(buf, opts))
+		})
+
+		it("returns an error for configs that are not valid", func() {
+			opts.Pattern = "spike"
+			opts.Config = json.RawMessage(`{"decay": "linear"}`)
+			assert.Error(t, ExportPattern(buf, opts))
+		})
+
+		it("returns an error rather than panicking when a plugin generator isn't loaded", func() {
+			opts.Pattern = "plugin"
+			opts.Config = json.RawMessage(`{"generator": "missing"}`)
+			assert.Error(t, ExportPattern(buf, opts))
+		})
+
+		it("returns an error for runs without a length", func() {
+			opts.RunFor = 0
+			assert.Error(t, ExportPattern(buf, opts))
+		})
+	})
+}
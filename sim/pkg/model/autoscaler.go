@@ -27,10 +27,96 @@ import (
 type AutoscalerConfig struct {
 	TickInterval time.Duration
 	Plugins      map[string]string //key - plugin type, value - yaml configuration
+	Horizontal   HorizontalConfig
+	Vertical     VerticalConfig
+}
+
+// HorizontalConfig bounds the replicas the autoscaler can ask for, as the Deployment and the
+// namespace's ResourceQuota would, and delays the ReplicaSet controller acting on recommendations.
+// Zero values mean no limit.
+type HorizontalConfig struct {
+	MinReplicas int32
+	MaxReplicas int32
+	// CPUQuotaMillis and MemoryQuotaMB limit the total requests of the desired replicas.
+	CPUQuotaMillis float64
+	MemoryQuotaMB  float64
+	ActuationDelay time.Duration
+}
+
+type ReplicaConstraint string
+
+const (
+	ConstraintNone        ReplicaConstraint = ""
+	ConstraintMinReplicas ReplicaConstraint = "min_replicas"
+	ConstraintMaxReplicas ReplicaConstraint = "max_replicas"
+	ConstraintCPUQuota    ReplicaConstraint = "cpu_quota"
+	ConstraintMemoryQuota ReplicaConstraint = "memory_quota"
+)
+
+// HorizontalDecision is a recommendation which was bounded or delayed before being acted upon.
+type HorizontalDecision struct {
+	At          time.Time
+	Recommended int32
+	Target      int32
+	Constraint  ReplicaConstraint
+	ActuatesAt  time.Time
+}
+
+// VPAUpdateMode mirrors the VerticalPodAutoscaler's updatePolicy.updateMode.
+type VPAUpdateMode string
+
+const (
+	// VPAUpdateModeOff ignores recommendations.
+	VPAUpdateModeOff VPAUpdateMode = "Off"
+	// VPAUpdateModeInitial applies recommendations to new replicas only.
+	VPAUpdateModeInitial VPAUpdateMode = "Initial"
+	// VPAUpdateModeRecreate also evicts and replaces replicas outside the recommended bounds.
+	VPAUpdateModeRecreate VPAUpdateMode = "Recreate"
+	// VPAUpdateModeAuto is the default, and behaves like Recreate.
+	VPAUpdateModeAuto VPAUpdateMode = "Auto"
+	// VPAUpdateModeInPlace resizes replicas outside the recommended bounds without restarting them.
+	VPAUpdateModeInPlace VPAUpdateMode = "InPlace"
+)
+
+// VerticalConfig limits how quickly the VPA's updater evicts replicas. Zero values mean no limit.
+type VerticalConfig struct {
+	UpdateMode VPAUpdateMode
+	// EvictionRateLimit is evictions per second, allowing bursts of EvictionRateBurst (default 1).
+	EvictionRateLimit float64
+	EvictionRateBurst int
+	// MinAvailable is a PodDisruptionBudget: evictions never leave fewer replicas available.
+	MinAvailable int
+	// EvictionFraction is the largest fraction of active replicas evicted in one tick.
+	EvictionFraction float64
+	// ResizeDelay is how long an in-place resize takes to be actuated.
+	ResizeDelay time.Duration
+}
+
+// AutoscalerDecision is what the autoscaler was told and what it decided on one tick.
+type AutoscalerDecision struct {
+	At time.Time
+	// Stats were delivered to the autoscaler since the previous tick.
+	Stats           []*proto.Stat
+	CurrentReplicas int32
+	DesiredReplicas int32
+	ReadyReplicas   int32
+
+	HorizontalRecommendation int32
+	HorizontalTarget         int32
+	// HorizontalApplied is false when the recommendation was bounded or delayed.
+	HorizontalApplied bool
+	Delta             int32
+
+	// VerticalCPUTarget is zero when there was no CPU recommendation.
+	VerticalCPUTarget int64
+	// VerticalApplied is true when a replica was evicted or resized to follow the recommendation.
+	VerticalApplied bool
 }
 
 type AutoscalerModel interface {
 	Model
+	ConstrainedDecisions() []HorizontalDecision
+	Decisions() []AutoscalerDecision
 }
 
 type autoscaler struct {
@@ -42,6 +128,14 @@ func (a *autoscaler) Env() simulator.Environment {
 	return a.env
 }
 
+func (a *autoscaler) ConstrainedDecisions() []HorizontalDecision {
+	return a.tickTock.ConstrainedDecisions()
+}
+
+func (a *autoscaler) Decisions() []AutoscalerDecision {
+	return a.tickTock.Decisions()
+}
+
 type stubCluster struct{}
 
 // TODO: actually list running pods.
@@ -65,7 +159,7 @@ func NewAutoscaler(env simulator.Environment, startAt time.Time, cluster Cluster
 	// Create the first pods since HPA can't scale from zero.
 	cm := cluster.(*clusterModel)
 	for i := 0; i < int(cm.config.InitialNumberOfReplicas); i++ {
-		replica := NewReplicaEntity(env, &cm.replicaSource.(*replicaSource).failedSink).(simulator.Entity)
+		replica := cm.replicaSource.(*replicaSource).newReplica().(simulator.Entity)
 		cm.replicasLaunching.Add(replica)
 		env.AddToSchedule(simulator.NewMovement(
 			"start_initial_replica",
@@ -77,7 +171,7 @@ func NewAutoscaler(env simulator.Environment, startAt time.Time, cluster Cluster
 	}
 	as := &autoscaler{
 		env:      env,
-		tickTock: NewAutoscalerTicktockStock(env, autoscalerEntity, cluster),
+		tickTock: NewAutoscalerTicktockStock(env, autoscalerEntity, cluster, config),
 	}
 
 	for theTime := startAt.Add(config.TickInterval).Add(1 * time.Nanosecond); theTime.Before(env.HaltTime()); theTime = theTime.Add(config.TickInterval) {
@@ -25,6 +25,8 @@ import (
 
 type AutoscalerTicktockStock interface {
 	simulator.ThroughStock
+	ConstrainedDecisions() []HorizontalDecision
+	Decisions() []AutoscalerDecision
 }
 
 type autoscalerTicktockStock struct {
@@ -33,6 +35,14 @@ type autoscalerTicktockStock struct {
 	autoscalerEntity simulator.Entity
 	desiredSource    simulator.ThroughStock
 	desiredSink      simulator.ThroughStock
+	horizontal       HorizontalConfig
+	scaling          DesiredScalingStock
+	decisions        []HorizontalDecision
+	ticks            []AutoscalerDecision
+	vertical         VerticalConfig
+	evictionTokens   float64
+	tokensUpdatedAt  time.Time
+	resizing         ReplicasResizingStock
 }
 
 func (asts *autoscalerTicktockStock) Name() simulator.StockName {
@@ -64,20 +74,97 @@ func (asts *autoscalerTicktockStock) Add(entity simulator.Entity) error {
 
 	asts.cluster.RecordToAutoscaler(&currentTime)
 
-	asts.adjustHorizontally(&currentTime)
-	asts.adjustVertically(&currentTime)
+	decision := AutoscalerDecision{
+		At:              currentTime,
+		Stats:           asts.cluster.TakeDeliveredStats(),
+		CurrentReplicas: int32(asts.cluster.CurrentLaunching() + asts.cluster.CurrentActive()),
+		DesiredReplicas: int32(asts.cluster.Desired().Count()),
+		ReadyReplicas:   int32(asts.cluster.CurrentActive()),
+	}
+	asts.adjustHorizontally(&currentTime, &decision)
+	asts.adjustVertically(&currentTime, &decision)
+	asts.ticks = append(asts.ticks, decision)
 
 	asts.calculateCPUUtilization()
 
 	return nil
 }
-func (asts *autoscalerTicktockStock) adjustHorizontally(currentTime *time.Time) {
-	autoscalerDesired, err := asts.env.Plugin().HorizontalRecommendation(currentTime.UnixNano())
+func (asts *autoscalerTicktockStock) ConstrainedDecisions() []HorizontalDecision {
+	return asts.decisions
+}
+
+func (asts *autoscalerTicktockStock) Decisions() []AutoscalerDecision {
+	return asts.ticks
+}
+
+func (asts *autoscalerTicktockStock) adjustHorizontally(currentTime *time.Time, decision *AutoscalerDecision) {
+	recommended, err := asts.env.Plugin().HorizontalRecommendation(currentTime.UnixNano())
 	if err != nil {
 		panic(err)
 	}
 
-	delta := autoscalerDesired - int32(asts.cluster.Desired().Count())
+	target, constraint := asts.constrain(recommended)
+	delay := asts.horizontal.ActuationDelay
+	decision.HorizontalRecommendation = recommended
+	decision.HorizontalTarget = target
+	decision.HorizontalApplied = constraint == ConstraintNone && delay == 0
+	decision.Delta = target - decision.DesiredReplicas
+	if constraint != ConstraintNone || delay > 0 {
+		asts.decisions = append(asts.decisions, HorizontalDecision{
+			At:          *currentTime,
+			Recommended: recommended,
+			Target:      target,
+			Constraint:  constraint,
+			ActuatesAt:  currentTime.Add(delay),
+		})
+	}
+
+	if delay > 0 {
+		asts.scaling.ScaleTo(target, delay)
+		return
+	}
+	asts.scaleTo(target)
+}
+
+// constrain bounds a recommendation by the replica limits and the quotas, giving the limit which bound it last.
+func (asts *autoscalerTicktockStock) constrain(recommended int32) (int32, ReplicaConstraint) {
+	target := recommended
+	constraint := ConstraintNone
+	bound := func(limit int32, c ReplicaConstraint) {
+		if target > limit {
+			target = limit
+			constraint = c
+		}
+	}
+
+	if asts.horizontal.MinReplicas > 0 && target < asts.horizontal.MinReplicas {
+		target = asts.horizontal.MinReplicas
+		constraint = ConstraintMinReplicas
+	}
+	if asts.horizontal.MaxReplicas > 0 {
+		bound(asts.horizontal.MaxReplicas, ConstraintMaxReplicas)
+	}
+
+	// quotas are hard limits, so win over the minimum
+	cm := asts.cluster.(*clusterModel)
+	if asts.horizontal.CPUQuotaMillis > 0 {
+		cpuRequest := cm.replicaSource.(*replicaSource).cpuRequest
+		if cpuRequest <= 0 {
+			cpuRequest = defaultCPUCapacityMillisPerSecond
+		}
+		bound(int32(asts.horizontal.CPUQuotaMillis/cpuRequest), ConstraintCPUQuota)
+	}
+	if asts.horizontal.MemoryQuotaMB > 0 && cm.replicasConfig.MemoryRequestMB > 0 {
+		bound(int32(asts.horizontal.MemoryQuotaMB/cm.replicasConfig.MemoryRequestMB), ConstraintMemoryQuota)
+	}
+
+	return target, constraint
+}
+
+// scaleTo has the ReplicaSet controller move the desired replicas towards the target.
+func (asts *autoscalerTicktockStock) scaleTo(target int32) {
+	currentTime := asts.env.CurrentMovementTime()
+	delta := target - int32(asts.cluster.Desired().Count())
 
 	if delta > 0 {
 		for i := int32(0); i < delta; i++ {
@@ -110,7 +197,7 @@ func (asts *autoscalerTicktockStock) adjustHorizontally(currentTime *time.Time)
 	}
 }
 
-func (asts *autoscalerTicktockStock) adjustVertically(currentTime *time.Time) {
+func (asts *autoscalerTicktockStock) adjustVertically(currentTime *time.Time, decision *AutoscalerDecision) {
 	recommendedPodResources, err := asts.env.Plugin().VerticalRecommendation(currentTime.UnixNano())
 	if err != nil {
 		panic(err)
@@ -126,17 +213,48 @@ func (asts *autoscalerTicktockStock) adjustVertically(currentTime *time.Time) {
 	if cpuRecommendation == nil {
 		return
 	}
+	decision.VerticalCPUTarget = cpuRecommendation.Target
+	if asts.vertical.UpdateMode == VPAUpdateModeOff {
+		return
+	}
+
+	// new replicas get the recommendation in every mode but Off
+	rs := asts.cluster.(*clusterModel).replicaSource.(*replicaSource)
+	rs.cpuRequest = float64(cpuRecommendation.Target)
+	if asts.vertical.UpdateMode == VPAUpdateModeInitial {
+		return
+	}
+
+	if asts.vertical.UpdateMode == VPAUpdateModeInPlace {
+		decision.VerticalApplied = asts.resizeInPlace(cpuRecommendation)
+		return
+	}
 
 	//Iterate through replicas
+	cm := asts.cluster.(*clusterModel)
+	cm.forgetTerminated()
 	pods := asts.cluster.ActiveStock().EntitiesInStock()
+	budget := asts.evictionBudget(*currentTime, pods)
 	for _, pod := range pods {
+		if cm.terminating[*pod] {
+			continue
+		}
 		//Check if we need to update this replica
 		resourceRequest := int64((*pod).(Replica).GetCPUCapacity())
 		if resourceRequest < cpuRecommendation.LowerBound || resourceRequest > cpuRecommendation.UpperBound {
+			if budget <= 0 {
+				return
+			}
+			budget--
+			if asts.vertical.EvictionRateLimit > 0 {
+				asts.evictionTokens--
+			}
+			cm.terminating[*pod] = true
+			decision.VerticalApplied = true
+
 			//update
 			//We create new one with recommendations
-			newReplica := NewReplicaEntity(asts.env, &asts.cluster.(*clusterModel).replicaSource.(*replicaSource).failedSink).(simulator.Entity)
-			newReplica.(*replicaEntity).totalCPUCapacityMillisPerSecond = float64(cpuRecommendation.Target)
+			newReplica := rs.newReplica().(simulator.Entity)
 			asts.cluster.LaunchingStock().Add(newReplica)
 
 			asts.env.AddToSchedule(simulator.NewMovement(
@@ -158,6 +276,75 @@ func (asts *autoscalerTicktockStock) adjustVertically(currentTime *time.Time) {
 		}
 	}
 }
+
+// resizeInPlace reports whether any replica was resized.
+func (asts *autoscalerTicktockStock) resizeInPlace(cpuRecommendation *proto.RecommendedPodResources) bool {
+	resized := false
+	for _, pod := range asts.cluster.ActiveStock().EntitiesInStock() {
+		if asts.resizing.IsResizing(*pod) {
+			continue
+		}
+		resourceRequest := int64((*pod).(Replica).GetCPUCapacity())
+		if resourceRequest < cpuRecommendation.LowerBound || resourceRequest > cpuRecommendation.UpperBound {
+			asts.resizing.Resize(*pod, float64(cpuRecommendation.Target), asts.vertical.ResizeDelay)
+			resized = true
+		}
+	}
+	return resized
+}
+
+// evictionBudget is how many replicas may be evicted now, given the rate limit, the disruption
+// budget and the fraction allowed per tick.
+func (asts *autoscalerTicktockStock) evictionBudget(now time.Time, pods []*simulator.Entity) int {
+	active := len(pods)
+	budget := active
+
+	if asts.vertical.EvictionFraction > 0 {
+		perTick := int(asts.vertical.EvictionFraction * float64(active))
+		if perTick < 1 {
+			perTick = 1
+		}
+		if perTick < budget {
+			budget = perTick
+		}
+	}
+
+	if asts.vertical.MinAvailable > 0 {
+		terminating := 0
+		for _, pod := range pods {
+			if asts.cluster.(*clusterModel).terminating[*pod] {
+				terminating++
+			}
+		}
+		disruptionsAllowed := active - terminating - asts.vertical.MinAvailable
+		if disruptionsAllowed < budget {
+			budget = disruptionsAllowed
+		}
+	}
+
+	if asts.vertical.EvictionRateLimit > 0 {
+		burst := float64(asts.vertical.EvictionRateBurst)
+		if burst < 1 {
+			burst = 1
+		}
+		if asts.tokensUpdatedAt.IsZero() {
+			asts.evictionTokens = burst
+		} else {
+			asts.evictionTokens += asts.vertical.EvictionRateLimit * now.Sub(asts.tokensUpdatedAt).Seconds()
+			if asts.evictionTokens > burst {
+				asts.evictionTokens = burst
+			}
+		}
+		asts.tokensUpdatedAt = now
+
+		if int(asts.evictionTokens) < budget {
+			budget = int(asts.evictionTokens)
+		}
+	}
+
+	return budget
+}
+
 func (asts *autoscalerTicktockStock) calculateCPUUtilization() {
 	countActiveReplicas := 0.0
 	totalCPUUtilization := 0.0 // total cpuUtilization for all active replicas in percentage
@@ -174,12 +361,19 @@ func (asts *autoscalerTicktockStock) calculateCPUUtilization() {
 	}
 }
 
-func NewAutoscalerTicktockStock(env simulator.Environment, scalerEntity simulator.Entity, cluster ClusterModel) AutoscalerTicktockStock {
-	return &autoscalerTicktockStock{
+func NewAutoscalerTicktockStock(env simulator.Environment, scalerEntity simulator.Entity, cluster ClusterModel, config AutoscalerConfig) AutoscalerTicktockStock {
+	asts := &autoscalerTicktockStock{
 		env:              env,
 		cluster:          cluster,
 		autoscalerEntity: scalerEntity,
 		desiredSource:    simulator.NewArrayThroughStock("DesiredSource", "Desired"),
 		desiredSink:      simulator.NewArrayThroughStock("DesiredSink", "Desired"),
+		horizontal:       config.Horizontal,
+		decisions:        make([]HorizontalDecision, 0),
+		ticks:            make([]AutoscalerDecision, 0),
+		vertical:         config.Vertical,
+		resizing:         NewReplicasResizingStock(env, cluster.ActiveStock()),
 	}
+	asts.scaling = NewDesiredScalingStock(env, asts.scaleTo)
+	return asts
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"skenario/pkg/simulator"
 )
@@ -43,9 +44,9 @@ func testAutoscalerTicktock(t *testing.T, describe spec.G, it spec.S) {
 		envFake = NewFakeEnvironment()
 		envFake.TheTime = time.Unix(0, 0)
 
-		replicasConfig = ReplicasConfig{time.Second, time.Second, 100}
+		replicasConfig = ReplicasConfig{LaunchDelay: time.Second, TerminateDelay: time.Second, MaxRPS: 100}
 		cluster = NewCluster(envFake, ClusterConfig{}, replicasConfig)
-		subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster)
+		subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster, AutoscalerConfig{})
 		rawSubject = subject.(*autoscalerTicktockStock)
 	})
 
@@ -121,7 +122,7 @@ func testAutoscalerTicktock(t *testing.T, describe spec.G, it spec.S) {
 				it.Before(func() {
 					rawCluster = cluster.(*clusterModel)
 					failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-					newReplica := NewReplicaEntity(envFake, &failedSink)
+					newReplica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 					err := rawCluster.replicasActive.Add(newReplica)
 					assert.NoError(t, err)
 
@@ -132,8 +133,9 @@ func testAutoscalerTicktock(t *testing.T, describe spec.G, it spec.S) {
 
 				it("delegates statistics updating to ClusterModel", func() {
 					stats := envFake.ThePlugin.(*FakePluginPartition).stats
-					assert.Len(t, stats, 1)
+					assert.NotEmpty(t, stats)
 					assert.Equal(t, stats[0].Type, proto.MetricType_CONCURRENT_REQUESTS_MILLIS)
+					assert.Equal(t, "RoutingStock", stats[0].PodName)
 				})
 			})
 
@@ -176,6 +178,112 @@ func testAutoscalerTicktock(t *testing.T, describe spec.G, it spec.S) {
 					})
 				})
 			})
+			describe("logging decisions", func() {
+				it.Before(func() {
+					rawCluster := cluster.(*clusterModel)
+					failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
+					require.NoError(t, rawCluster.replicasActive.Add(NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)))
+					require.NoError(t, cluster.Desired().Add(simulator.NewEntity("desired-1", "Desired")))
+
+					envFake.ThePlugin.(*FakePluginPartition).scaleTo = 3
+					envFake.ThePlugin.(*FakePluginPartition).verticalRec = []*proto.RecommendedPodResources{{ResourceName: "cpu", Target: 500}}
+					require.NoError(t, subject.Add(subject.Remove(nil)))
+				})
+
+				it("records the inputs of each tick", func() {
+					require.Len(t, subject.Decisions(), 1)
+					decision := subject.Decisions()[0]
+					assert.Equal(t, time.Unix(0, 0), decision.At)
+					assert.NotEmpty(t, decision.Stats)
+					assert.Equal(t, int32(1), decision.CurrentReplicas)
+					assert.Equal(t, int32(1), decision.DesiredReplicas)
+					assert.Equal(t, int32(1), decision.ReadyReplicas)
+				})
+
+				it("records the outputs of each tick", func() {
+					decision := subject.Decisions()[0]
+					assert.Equal(t, int32(3), decision.HorizontalRecommendation)
+					assert.Equal(t, int32(3), decision.HorizontalTarget)
+					assert.True(t, decision.HorizontalApplied)
+					assert.Equal(t, int32(2), decision.Delta)
+					assert.Equal(t, int64(500), decision.VerticalCPUTarget)
+					assert.True(t, decision.VerticalApplied)
+				})
+			})
+
+			describe("constraining the HPA autoscaler", func() {
+				countMovements := func(kind simulator.MovementKind) int {
+					count := 0
+					for _, mv := range envFake.Movements {
+						if mv.Kind() == kind {
+							count++
+						}
+					}
+					return count
+				}
+
+				tick := func(horizontal HorizontalConfig, scaleTo int32) {
+					subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster, AutoscalerConfig{Horizontal: horizontal})
+					envFake.ThePlugin.(*FakePluginPartition).scaleTo = scaleTo
+					err := subject.Add(subject.Remove(nil))
+					assert.NoError(t, err)
+				}
+
+				it("does not record decisions it didn't constrain", func() {
+					tick(HorizontalConfig{MaxReplicas: 10}, 4)
+					assert.Equal(t, 4, countMovements("increase_desired"))
+					assert.Empty(t, subject.ConstrainedDecisions())
+				})
+
+				it("clamps to the maximum replicas", func() {
+					tick(HorizontalConfig{MaxReplicas: 3}, 5)
+					assert.Equal(t, 3, countMovements("increase_desired"))
+					assert.Equal(t, []HorizontalDecision{{
+						At:          time.Unix(0, 0),
+						Recommended: 5,
+						Target:      3,
+						Constraint:  ConstraintMaxReplicas,
+						ActuatesAt:  time.Unix(0, 0),
+					}}, subject.ConstrainedDecisions())
+				})
+
+				it("clamps to the minimum replicas", func() {
+					tick(HorizontalConfig{MinReplicas: 2}, 0)
+					assert.Equal(t, 2, countMovements("increase_desired"))
+					assert.Equal(t, ConstraintMinReplicas, subject.ConstrainedDecisions()[0].Constraint)
+				})
+
+				it("fits within the CPU quota, even below the minimum replicas", func() {
+					tick(HorizontalConfig{MinReplicas: 4, CPUQuotaMillis: 2500}, 6)
+					assert.Equal(t, 2, countMovements("increase_desired"))
+					assert.Equal(t, ConstraintCPUQuota, subject.ConstrainedDecisions()[0].Constraint)
+				})
+
+				it("fits within the memory quota", func() {
+					cluster.(*clusterModel).replicasConfig.MemoryRequestMB = 512
+					tick(HorizontalConfig{MemoryQuotaMB: 1024}, 6)
+					assert.Equal(t, 2, countMovements("increase_desired"))
+					assert.Equal(t, ConstraintMemoryQuota, subject.ConstrainedDecisions()[0].Constraint)
+				})
+
+				describe("with an actuation delay", func() {
+					it.Before(func() {
+						tick(HorizontalConfig{ActuationDelay: 10 * time.Second}, 3)
+					})
+
+					it("schedules the decision to be acted upon later", func() {
+						assert.Equal(t, 0, countMovements("increase_desired"))
+						assert.Equal(t, 1, countMovements("actuate_scaling"))
+						assert.Equal(t, time.Unix(10, 0), envFake.Movements[len(envFake.Movements)-1].OccursAt())
+					})
+
+					it("records the delayed decision", func() {
+						assert.Equal(t, time.Unix(10, 0), subject.ConstrainedDecisions()[0].ActuatesAt)
+						assert.Equal(t, ConstraintNone, subject.ConstrainedDecisions()[0].Constraint)
+					})
+				})
+			})
+
 			it("cpu utilization list is empty in environment", func() {
 				assert.Equal(t, 0, len(envFake.TheCPUUtilizations))
 			})
@@ -184,13 +292,13 @@ func testAutoscalerTicktock(t *testing.T, describe spec.G, it spec.S) {
 				it.Before(func() {
 					rawCluster := cluster.(*clusterModel)
 					failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-					newReplica1 := NewReplicaEntity(envFake, &failedSink)
+					newReplica1 := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 					newReplica1.(*replicaEntity).occupiedCPUCapacityMillisPerSecond = 50
 					newReplica1.(*replicaEntity).totalCPUCapacityMillisPerSecond = 100
 					err := rawCluster.replicasActive.Add(newReplica1)
 					assert.NoError(t, err)
 
-					newReplica2 := NewReplicaEntity(envFake, &failedSink)
+					newReplica2 := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 					newReplica2.(*replicaEntity).occupiedCPUCapacityMillisPerSecond = 0
 					newReplica2.(*replicaEntity).totalCPUCapacityMillisPerSecond = 100
 					err = rawCluster.replicasActive.Add(newReplica2)
@@ -217,7 +325,7 @@ func testAutoscalerTicktock(t *testing.T, describe spec.G, it spec.S) {
 					it.Before(func() {
 						rawCluster := cluster.(*clusterModel)
 						failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-						replica := NewReplicaEntity(envFake, &failedSink)
+						replica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 						replica.(*replicaEntity).totalCPUCapacityMillisPerSecond = 100
 						err := rawCluster.replicasActive.Add(replica)
 						assert.NoError(t, err)
@@ -248,7 +356,7 @@ func testAutoscalerTicktock(t *testing.T, describe spec.G, it spec.S) {
 					it.Before(func() {
 						rawCluster := cluster.(*clusterModel)
 						failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-						replica := NewReplicaEntity(envFake, &failedSink)
+						replica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 						replica.(*replicaEntity).totalCPUCapacityMillisPerSecond = 100
 						err := rawCluster.replicasActive.Add(replica)
 						assert.NoError(t, err)
@@ -269,6 +377,123 @@ func testAutoscalerTicktock(t *testing.T, describe spec.G, it spec.S) {
 					})
 				})
 			})
+
+			describe("limiting evictions", func() {
+				var tick func()
+				var evictions func() int
+
+				it.Before(func() {
+					rawCluster := cluster.(*clusterModel)
+					failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
+					for i := 0; i < 4; i++ {
+						replica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
+						replica.(*replicaEntity).totalCPUCapacityMillisPerSecond = 100
+						err := rawCluster.replicasActive.Add(replica)
+						assert.NoError(t, err)
+					}
+					envFake.ThePlugin.(*FakePluginPartition).verticalRec = []*proto.RecommendedPodResources{
+						{LowerBound: 150, UpperBound: 500, Target: 250, ResourceName: "cpu"},
+					}
+					envFake.Movements = nil
+
+					tick = func() {
+						ent := subject.Remove(nil)
+						err := subject.Add(ent)
+						assert.NoError(t, err)
+					}
+					evictions = func() int {
+						count := 0
+						for _, mv := range envFake.Movements {
+							if mv.Kind() == "evict_replica" {
+								count++
+							}
+						}
+						return count
+					}
+				})
+
+				it("evicts every replica outside the bounds by default", func() {
+					tick()
+					assert.Equal(t, 4, evictions())
+					assert.True(t, subject.Decisions()[0].VerticalApplied)
+				})
+
+				it("doesn't evict a replica again while it is being replaced", func() {
+					tick()
+					tick()
+					assert.Equal(t, 4, evictions())
+					assert.False(t, subject.Decisions()[1].VerticalApplied)
+				})
+
+				it("evicts nothing when the update mode is Off", func() {
+					subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster, AutoscalerConfig{Vertical: VerticalConfig{UpdateMode: VPAUpdateModeOff}})
+					tick()
+					assert.Equal(t, 0, evictions())
+					assert.Zero(t, cluster.(*clusterModel).replicaSource.(*replicaSource).cpuRequest)
+				})
+
+				it("only sizes new replicas when the update mode is Initial", func() {
+					subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster, AutoscalerConfig{Vertical: VerticalConfig{UpdateMode: VPAUpdateModeInitial}})
+					tick()
+					assert.Equal(t, 0, evictions())
+					assert.False(t, subject.Decisions()[0].VerticalApplied)
+
+					replica := cluster.(*clusterModel).replicaSource.Remove(nil)
+					assert.Equal(t, 250.0, replica.(Replica).GetCPUCapacity())
+				})
+
+				it("evicts a fraction of replicas per tick", func() {
+					subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster, AutoscalerConfig{Vertical: VerticalConfig{EvictionFraction: 0.5}})
+					tick()
+					assert.Equal(t, 2, evictions())
+				})
+
+				it("respects the disruption budget", func() {
+					subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster, AutoscalerConfig{Vertical: VerticalConfig{MinAvailable: 3}})
+					tick()
+					tick()
+					assert.Equal(t, 1, evictions())
+					assert.True(t, subject.Decisions()[0].VerticalApplied)
+					assert.False(t, subject.Decisions()[1].VerticalApplied)
+				})
+
+				describe("resizing in place", func() {
+					it.Before(func() {
+						subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster, AutoscalerConfig{Vertical: VerticalConfig{UpdateMode: VPAUpdateModeInPlace, ResizeDelay: 3 * time.Second}})
+						tick()
+					})
+
+					it("resizes replicas instead of evicting them", func() {
+						assert.Equal(t, 0, evictions())
+						resizes := 0
+						for _, mv := range envFake.Movements {
+							if mv.Kind() == "resize_replica" {
+								resizes++
+								assert.Equal(t, envFake.TheTime.Add(3*time.Second), mv.OccursAt())
+							}
+						}
+						assert.Equal(t, 4, resizes)
+						assert.True(t, subject.Decisions()[0].VerticalApplied)
+					})
+
+					it("doesn't resize a replica again while it is being resized", func() {
+						movements := len(envFake.Movements)
+						tick()
+						assert.Len(t, envFake.Movements, movements)
+						assert.False(t, subject.Decisions()[1].VerticalApplied)
+					})
+				})
+
+				it("limits the rate of evictions", func() {
+					subject = NewAutoscalerTicktockStock(envFake, simulator.NewEntity("Autoscaler", "Autoscaler"), cluster, AutoscalerConfig{Vertical: VerticalConfig{EvictionRateLimit: 0.1, EvictionRateBurst: 2}})
+					tick()
+					assert.Equal(t, 2, evictions())
+
+					envFake.TheTime = envFake.TheTime.Add(10 * time.Second)
+					tick()
+					assert.Equal(t, 3, evictions())
+				})
+			})
 		})
 
 	})
@@ -39,6 +39,9 @@ type ClusterModel interface {
 	ActiveStock() ReplicasActiveStock
 	TerminatingStock() ReplicasTerminatingStock
 	LaunchingStock() simulator.ThroughStock
+	Cost(config CostConfig, from, until time.Time) CostReport
+	ReplicaRevisions() map[simulator.EntityName]int
+	TakeDeliveredStats() []*proto.Stat
 }
 
 type clusterModel struct {
@@ -51,8 +54,13 @@ type clusterModel struct {
 	replicasActive      ReplicasActiveStock
 	replicasTerminating ReplicasTerminatingStock
 	replicasTerminated  simulator.SinkStock
-	requestsInRouting   simulator.ThroughStock
+	requestsInRouting   RequestsRoutingStock
 	requestsFailed      simulator.SinkStock
+	lastRecordTime      time.Time
+	deliveredStats      []*proto.Stat
+	// terminating holds replicas that the vertical autoscaler or a rollout has scheduled to terminate
+	// but which are still launching or active, so that they are not terminated twice.
+	terminating map[simulator.Entity]bool
 }
 
 func (cm *clusterModel) Env() simulator.Environment {
@@ -79,12 +87,46 @@ func (cm *clusterModel) RecordToAutoscaler(atTime *time.Time) {
 		Type:    proto.MetricType_CONCURRENT_REQUESTS_MILLIS,
 		Value:   int32(cm.requestsInRouting.Count() * 1000),
 	})
-	// TODO: report request count
+	stats = append(stats, &proto.Stat{
+		Time:    atTime.UnixNano(),
+		PodName: "RoutingStock",
+		Type:    proto.MetricType_QUEUE_DEPTH,
+		Value:   int32(cm.requestsInRouting.Count()),
+	})
+	requestCount := cm.requestsInRouting.RequestCount()
+	stats = append(stats, &proto.Stat{
+		Time:    atTime.UnixNano(),
+		PodName: "RoutingStock",
+		Type:    proto.MetricType_REQUEST_COUNT,
+		Value:   requestCount,
+	})
+	if elapsed := atTime.Sub(cm.lastRecordTime); !cm.lastRecordTime.IsZero() && elapsed > 0 {
+		stats = append(stats, &proto.Stat{
+			Time:    atTime.UnixNano(),
+			PodName: "RoutingStock",
+			Type:    proto.MetricType_REQUESTS_PER_SECOND_MILLIS,
+			Value:   int32(float64(requestCount) * 1000 / elapsed.Seconds()),
+		})
+	}
+	cm.lastRecordTime = *atTime
+	stats = appendMetricAliases(stats, cm.replicasConfig.MetricAliases)
 
 	err := cm.env.Plugin().Stat(stats)
 	if err != nil {
 		panic(err)
 	}
+	cm.recordDelivered(stats)
+}
+
+func (cm *clusterModel) recordDelivered(stats []*proto.Stat) {
+	cm.deliveredStats = append(cm.deliveredStats, stats...)
+}
+
+// TakeDeliveredStats gives the stats delivered to the autoscaler since it was last called.
+func (cm *clusterModel) TakeDeliveredStats() []*proto.Stat {
+	delivered := cm.deliveredStats
+	cm.deliveredStats = make([]*proto.Stat, 0)
+	return delivered
 }
 
 func (cm *clusterModel) RoutingStock() RequestsRoutingStock {
@@ -103,25 +145,56 @@ func (cm *clusterModel) LaunchingStock() simulator.ThroughStock {
 	return cm.replicasLaunching
 }
 
+// forgetTerminated stops tracking scheduled terminations which have completed.
+func (cm *clusterModel) forgetTerminated() {
+	remaining := make(map[simulator.Entity]bool)
+	for _, stock := range []simulator.ThroughStock{cm.replicasLaunching, cm.replicasActive} {
+		for _, e := range stock.EntitiesInStock() {
+			if cm.terminating[*e] {
+				remaining[*e] = true
+			}
+		}
+	}
+	cm.terminating = remaining
+}
+
+// Cost bills every replica created so far for the CPU and memory it requested, from when it began
+// launching until it finished terminating.
+func (cm *clusterModel) Cost(config CostConfig, from, until time.Time) CostReport {
+	return calculateCost(cm.replicaSource.(*replicaSource).billings, config, from, until)
+}
+
+// ReplicaRevisions gives the revision of every replica created so far.
+func (cm *clusterModel) ReplicaRevisions() map[simulator.EntityName]int {
+	revisions := make(map[simulator.EntityName]int)
+	for name, revision := range cm.replicaSource.(*replicaSource).revisions {
+		revisions[name] = revision
+	}
+	return revisions
+}
+
 func NewCluster(env simulator.Environment, config ClusterConfig, replicasConfig ReplicasConfig) ClusterModel {
 	replicasActive := NewReplicasActiveStock(env)
-	requestsFailed := simulator.NewSinkStock("RequestsFailed", "Request")
+	requestsFailed := NewRequestsSinkStock("RequestsFailed", false)
 	routingStock := NewRequestsRoutingStock(env, replicasActive, requestsFailed)
-	replicasTerminated := simulator.NewSinkStock("ReplicasTerminated", simulator.EntityKind("Replica"))
+	replicasTerminated := NewReplicasTerminatedStock()
 
 	cm := &clusterModel{
 		env:                 env,
 		config:              config,
 		replicasConfig:      replicasConfig,
-		replicaSource:       NewReplicaSource(env, replicasConfig.MaxRPS),
+		replicaSource:       NewReplicaSource(env, replicasConfig),
 		replicasLaunching:   simulator.NewArrayThroughStock("ReplicasLaunching", simulator.EntityKind("Replica")),
 		replicasActive:      replicasActive,
 		replicasTerminating: NewReplicasTerminatingStock(env, replicasConfig, replicasTerminated),
 		replicasTerminated:  replicasTerminated,
 		requestsInRouting:   routingStock,
 		requestsFailed:      requestsFailed,
+		terminating:         make(map[simulator.Entity]bool),
 	}
 
+	cm.replicaSource.(*replicaSource).statsObserver = cm.recordDelivered
+
 	desiredConf := ReplicasConfig{
 		LaunchDelay:    config.LaunchDelay,
 		TerminateDelay: config.TerminateDelay,
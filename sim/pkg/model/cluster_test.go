@@ -41,7 +41,7 @@ func testCluster(t *testing.T, describe spec.G, it spec.S) {
 	it.Before(func() {
 		config = ClusterConfig{}
 		config.NumberOfRequests = 10
-		replicasConfig = ReplicasConfig{time.Second, time.Second, 100}
+		replicasConfig = ReplicasConfig{LaunchDelay: time.Second, TerminateDelay: time.Second, MaxRPS: 100}
 		subject = NewCluster(envFake, config, replicasConfig)
 		assert.NotNil(t, subject)
 
@@ -69,8 +69,8 @@ func testCluster(t *testing.T, describe spec.G, it spec.S) {
 		it.Before(func() {
 			rawSubject = subject.(*clusterModel)
 			failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-			firstReplica := NewReplicaEntity(envFake, &failedSink)
-			secondReplica := NewReplicaEntity(envFake, &failedSink)
+			firstReplica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
+			secondReplica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 			rawSubject.replicasLaunching.Add(firstReplica)
 			rawSubject.replicasLaunching.Add(secondReplica)
 		})
@@ -86,8 +86,8 @@ func testCluster(t *testing.T, describe spec.G, it spec.S) {
 		it.Before(func() {
 			rawSubject = subject.(*clusterModel)
 			failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-			firstReplica := NewReplicaEntity(envFake, &failedSink)
-			secondReplica := NewReplicaEntity(envFake, &failedSink)
+			firstReplica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
+			secondReplica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 			rawSubject.replicasActive.Add(firstReplica)
 			rawSubject.replicasActive.Add(secondReplica)
 		})
@@ -109,8 +109,8 @@ func testCluster(t *testing.T, describe spec.G, it spec.S) {
 			request := NewRequestEntity(envFake, rawSubject.requestsInRouting, RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
 			rawSubject.requestsInRouting.Add(request)
 			failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-			firstReplica := NewReplicaEntity(envFake, &failedSink)
-			secondReplica := NewReplicaEntity(envFake, &failedSink)
+			firstReplica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
+			secondReplica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 
 			rawSubject.replicasActive.Add(firstReplica)
 			rawSubject.replicasActive.Add(secondReplica)
@@ -121,11 +121,41 @@ func testCluster(t *testing.T, describe spec.G, it spec.S) {
 
 		// TODO immediately record arrivals at routingStock
 
-		it("records once for the routingStock", func() {
+		it("records concurrency, queue depth and request count for the routingStock", func() {
 			stats := envFake.ThePlugin.(*FakePluginPartition).stats
-			assert.Len(t, envFake.ThePlugin.(*FakePluginPartition).stats, 1)
+			assert.Len(t, envFake.ThePlugin.(*FakePluginPartition).stats, 3)
 			assert.Equal(t, stats[0].Type, proto.MetricType_CONCURRENT_REQUESTS_MILLIS)
+			assert.Equal(t, stats[1].Type, proto.MetricType_QUEUE_DEPTH)
+			assert.Equal(t, int32(1), stats[1].Value)
+			assert.Equal(t, stats[2].Type, proto.MetricType_REQUEST_COUNT)
+			assert.Equal(t, int32(1), stats[2].Value)
+		})
+
+		describe("recording again", func() {
+			var stats []*proto.Stat
+
+			it.Before(func() {
+				envFake.ThePlugin.(*FakePluginPartition).stats = nil
+				for i := 0; i < 4; i++ {
+					request := NewRequestEntity(envFake, rawSubject.requestsInRouting, RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+					rawSubject.requestsInRouting.Add(request)
+				}
+
+				later := theTime.Add(2 * time.Second)
+				subject.RecordToAutoscaler(&later)
+				stats = envFake.ThePlugin.(*FakePluginPartition).stats
+			})
 
+			it("counts only the requests which arrived since the last record", func() {
+				assert.Equal(t, proto.MetricType_REQUEST_COUNT, stats[2].Type)
+				assert.Equal(t, int32(4), stats[2].Value)
+			})
+
+			it("records the request rate since the last record", func() {
+				assert.Len(t, stats, 4)
+				assert.Equal(t, proto.MetricType_REQUESTS_PER_SECOND_MILLIS, stats[3].Type)
+				assert.Equal(t, int32(2000), stats[3].Value)
+			})
 		})
 
 		describe("the record for the routingStock", func() {
@@ -143,6 +173,71 @@ func testCluster(t *testing.T, describe spec.G, it spec.S) {
 		})
 	})
 
+	describe("TakeDeliveredStats()", func() {
+		envFake = NewFakeEnvironment()
+
+		it.Before(func() {
+			theTime := time.Unix(0, 0)
+			subject.RecordToAutoscaler(&theTime)
+		})
+
+		it("gives the stats delivered to the autoscaler", func() {
+			delivered := subject.TakeDeliveredStats()
+			assert.NotEmpty(t, delivered)
+			assert.Equal(t, "RoutingStock", delivered[0].PodName)
+		})
+
+		it("only gives them once", func() {
+			subject.TakeDeliveredStats()
+			assert.Empty(t, subject.TakeDeliveredStats())
+		})
+	})
+
+	describe("Cost()", func() {
+		envFake = NewFakeEnvironment()
+		var cost CostReport
+
+		it.Before(func() {
+			envFake.TheTime = time.Unix(0, 0)
+			rawSubject.replicaSource.Remove(nil)
+			envFake.TheTime = time.Unix(1800, 0)
+			replica := rawSubject.replicaSource.Remove(nil)
+			rawSubject.replicasTerminating.Add(replica)
+
+			cost = subject.Cost(CostConfig{CPUCoreHourPrice: 1, Interval: time.Hour}, time.Unix(0, 0), time.Unix(3600, 0))
+		})
+
+		it("bills every replica created from creation until it finished terminating", func() {
+			assert.InDelta(t, 1.0+(1.0/3600.0), cost.CPUCoreHours, 0.0001)
+			assert.InDelta(t, cost.CPUCoreHours, cost.Total, 0.0001)
+		})
+	})
+
+	describe("ReplicaRevisions()", func() {
+		envFake = NewFakeEnvironment()
+
+		it.Before(func() {
+			envFake.TheTime = time.Unix(0, 0)
+			replica := rawSubject.replicaSource.Remove(nil)
+			rawSubject.replicaSource.(*replicaSource).revision = 2
+			rawSubject.replicaSource.Remove(nil)
+			rawSubject.replicasTerminated.Add(replica)
+		})
+
+		it("gives the revision of every replica created, including terminated ones", func() {
+			var revisions []int
+			for _, revision := range subject.ReplicaRevisions() {
+				revisions = append(revisions, revision)
+			}
+			assert.ElementsMatch(t, []int{1, 2}, revisions)
+		})
+
+		it("does not keep terminated replicas", func() {
+			assert.Equal(t, uint64(1), rawSubject.replicasTerminated.Count())
+			assert.Empty(t, rawSubject.replicasTerminated.EntitiesInStock())
+		})
+	})
+
 	describe("requestsInRouting", func() {
 		it("returns the configured routing stock", func() {
 			assert.Equal(t, rawSubject.requestsInRouting, subject.RoutingStock())
@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"time"
+)
+
+const defaultCostInterval = 10 * time.Second
+
+type CostConfig struct {
+	CPUCoreHourPrice  float64
+	MemoryGBHourPrice float64
+	Interval          time.Duration // between points of the series, defaults to 10s
+}
+
+// CostPoint covers the interval ending At. CPUCores and MemoryGB are averages over the interval,
+// Cost is the cumulative cost up to At.
+type CostPoint struct {
+	At       time.Time
+	CPUCores float64
+	MemoryGB float64
+	Cost     float64
+}
+
+type CostReport struct {
+	CPUCoreHours  float64
+	MemoryGBHours float64
+	Total         float64
+	Series        []CostPoint
+}
+
+type cpuRequestChange struct {
+	at     time.Time
+	millis float64
+}
+
+// replicaBilling is a replica's lifetime, from creation to the end of terminating, and the resources it requested.
+type replicaBilling struct {
+	from        time.Time
+	until       time.Time // zero while the replica is still alive
+	cpuRequests []cpuRequestChange
+	memoryMB    float64
+}
+
+func (rb *replicaBilling) start(at time.Time, cpuMillis float64, memoryMB float64) {
+	rb.from = at
+	rb.cpuRequests = []cpuRequestChange{{at: at, millis: cpuMillis}}
+	rb.memoryMB = memoryMB
+}
+
+func (rb *replicaBilling) resize(at time.Time, cpuMillis float64) {
+	if len(rb.cpuRequests) == 0 {
+		return
+	}
+	rb.cpuRequests = append(rb.cpuRequests, cpuRequestChange{at: at, millis: cpuMillis})
+}
+
+func (rb *replicaBilling) stop(at time.Time) {
+	rb.until = at
+}
+
+// usage gives the core-hours and GB-hours requested between from and until.
+func (rb *replicaBilling) usage(from, until time.Time) (coreHours, gbHours float64) {
+	if len(rb.cpuRequests) == 0 {
+		return 0, 0
+	}
+
+	end := until
+	if !rb.until.IsZero() && rb.until.Before(end) {
+		end = rb.until
+	}
+
+	for i, change := range rb.cpuRequests {
+		segmentFrom := latest(change.at, from)
+		segmentUntil := end
+		if i+1 < len(rb.cpuRequests) && rb.cpuRequests[i+1].at.Before(segmentUntil) {
+			segmentUntil = rb.cpuRequests[i+1].at
+		}
+		if segmentUntil.After(segmentFrom) {
+			coreHours += change.millis / 1000 * segmentUntil.Sub(segmentFrom).Hours()
+		}
+	}
+
+	if lifetimeFrom := latest(rb.from, from); end.After(lifetimeFrom) {
+		gbHours = rb.memoryMB / 1024 * end.Sub(lifetimeFrom).Hours()
+	}
+
+	return coreHours, gbHours
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// calculateCost bills replicas for what they requested between from and until. Replicas which are still
+// alive at until are billed up to until.
+func calculateCost(billings []*replicaBilling, config CostConfig, from, until time.Time) CostReport {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = defaultCostInterval
+	}
+
+	report := CostReport{Series: make([]CostPoint, 0)}
+	for start := from; start.Before(until); start = start.Add(interval) {
+		end := start.Add(interval)
+		if end.After(until) {
+			end = until
+		}
+
+		var coreHours, gbHours float64
+		for _, rb := range billings {
+			ch, gh := rb.usage(start, end)
+			coreHours += ch
+			gbHours += gh
+		}
+
+		report.CPUCoreHours += coreHours
+		report.MemoryGBHours += gbHours
+		report.Total += coreHours*config.CPUCoreHourPrice + gbHours*config.MemoryGBHourPrice
+
+		hours := end.Sub(start).Hours()
+		report.Series = append(report.Series, CostPoint{
+			At:       end,
+			CPUCores: coreHours / hours,
+			MemoryGB: gbHours / hours,
+			Cost:     report.Total,
+		})
+	}
+
+	return report
+}
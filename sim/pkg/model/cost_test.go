@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCost(t *testing.T) {
+	spec.Run(t, "Cost calculation", testCost, spec.Report(report.Terminal{}))
+}
+
+func testCost(t *testing.T, describe spec.G, it spec.S) {
+	var start = time.Unix(0, 0)
+	var config CostConfig
+
+	it.Before(func() {
+		config = CostConfig{CPUCoreHourPrice: 2, MemoryGBHourPrice: 1, Interval: time.Hour}
+	})
+
+	describe("replicaBilling", func() {
+		var subject *replicaBilling
+
+		it.Before(func() {
+			subject = &replicaBilling{}
+			subject.start(start, 1000, 2048)
+		})
+
+		it("bills an unterminated replica up to the end of the period", func() {
+			coreHours, gbHours := subject.usage(start, start.Add(2*time.Hour))
+			assert.Equal(t, 2.0, coreHours)
+			assert.Equal(t, 4.0, gbHours)
+		})
+
+		it("stops billing once the replica has terminated", func() {
+			subject.stop(start.Add(30 * time.Minute))
+			coreHours, gbHours := subject.usage(start, start.Add(2*time.Hour))
+			assert.Equal(t, 0.5, coreHours)
+			assert.Equal(t, 1.0, gbHours)
+		})
+
+		it("bills each CPU request for as long as it was held", func() {
+			subject.resize(start.Add(time.Hour), 500)
+			coreHours, _ := subject.usage(start, start.Add(2*time.Hour))
+			assert.Equal(t, 1.5, coreHours)
+		})
+
+		it("does not bill before the replica was created", func() {
+			late := &replicaBilling{}
+			late.start(start.Add(time.Hour), 1000, 1024)
+			coreHours, gbHours := late.usage(start, start.Add(time.Hour))
+			assert.Equal(t, 0.0, coreHours)
+			assert.Equal(t, 0.0, gbHours)
+		})
+	})
+
+	describe("calculateCost()", func() {
+		var cost CostReport
+
+		it.Before(func() {
+			first := &replicaBilling{}
+			first.start(start, 1000, 1024)
+			second := &replicaBilling{}
+			second.start(start.Add(time.Hour), 2000, 0)
+			second.stop(start.Add(90 * time.Minute))
+
+			cost = calculateCost([]*replicaBilling{first, second}, config, start, start.Add(2*time.Hour))
+		})
+
+		it("totals the core-hours and GB-hours", func() {
+			assert.Equal(t, 3.0, cost.CPUCoreHours)
+			assert.Equal(t, 2.0, cost.MemoryGBHours)
+		})
+
+		it("prices them", func() {
+			assert.Equal(t, 8.0, cost.Total)
+		})
+
+		it("gives a series of average usage and cumulative cost", func() {
+			assert.Equal(t, []CostPoint{
+				{At: start.Add(time.Hour), CPUCores: 1, MemoryGB: 1, Cost: 3},
+				{At: start.Add(2 * time.Hour), CPUCores: 2, MemoryGB: 1, Cost: 8},
+			}, cost.Series)
+		})
+
+		it("cuts the last interval short at the end of the period", func() {
+			cost = calculateCost([]*replicaBilling{}, config, start, start.Add(90*time.Minute))
+			assert.Len(t, cost.Series, 2)
+			assert.Equal(t, start.Add(90*time.Minute), cost.Series[1].At)
+		})
+	})
+}
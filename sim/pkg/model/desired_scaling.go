@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"skenario/pkg/simulator"
+)
+
+// DesiredScalingStock holds the autoscaler's decisions until the Deployment/ReplicaSet controller
+// acts on them. Each target is applied when its "actuate_scaling" movement occurs.
+type DesiredScalingStock interface {
+	simulator.ThroughStock
+	ScaleTo(target int32, after time.Duration)
+}
+
+type desiredScalingStock struct {
+	env     simulator.Environment
+	apply   func(target int32)
+	pending map[simulator.Entity]int32
+	order   []simulator.Entity
+	count   int
+}
+
+func (dss *desiredScalingStock) Name() simulator.StockName {
+	return "DesiredScaling"
+}
+
+func (dss *desiredScalingStock) KindStocked() simulator.EntityKind {
+	return "ScalingDecision"
+}
+
+func (dss *desiredScalingStock) Count() uint64 {
+	return uint64(len(dss.order))
+}
+
+func (dss *desiredScalingStock) EntitiesInStock() []*simulator.Entity {
+	entities := make([]*simulator.Entity, 0, len(dss.order))
+	for i := range dss.order {
+		entities = append(entities, &dss.order[i])
+	}
+	return entities
+}
+
+func (dss *desiredScalingStock) Remove(entity *simulator.Entity) simulator.Entity {
+	if entity == nil {
+		return nil
+	}
+	if _, ok := dss.pending[*entity]; !ok {
+		return nil
+	}
+	return *entity
+}
+
+func (dss *desiredScalingStock) Add(entity simulator.Entity) error {
+	target, ok := dss.pending[entity]
+	if !ok {
+		return fmt.Errorf("'%+v' is not waiting to be actuated", entity)
+	}
+	delete(dss.pending, entity)
+	for i, e := range dss.order {
+		if e == entity {
+			dss.order = append(dss.order[:i], dss.order[i+1:]...)
+			break
+		}
+	}
+
+	dss.apply(target)
+	return nil
+}
+
+func (dss *desiredScalingStock) ScaleTo(target int32, after time.Duration) {
+	dss.count++
+	decision := simulator.NewEntity(simulator.EntityName(fmt.Sprintf("scaling-decision-%d", dss.count)), "ScalingDecision")
+	dss.pending[decision] = target
+	dss.order = append(dss.order, decision)
+
+	if after <= 0 {
+		after = 1 * time.Nanosecond
+	}
+	dss.env.AddToSchedule(simulator.NewMovement(
+		"actuate_scaling",
+		dss.env.CurrentMovementTime().Add(after),
+		dss,
+		dss,
+		&decision,
+	))
+}
+
+func NewDesiredScalingStock(env simulator.Environment, apply func(target int32)) DesiredScalingStock {
+	return &desiredScalingStock{
+		env:     env,
+		apply:   apply,
+		pending: make(map[simulator.Entity]int32),
+		order:   make([]simulator.Entity, 0),
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/simulator"
+)
+
+func TestDesiredScaling(t *testing.T) {
+	spec.Run(t, "DesiredScaling stock", testDesiredScaling, spec.Report(report.Terminal{}))
+}
+
+func testDesiredScaling(t *testing.T, describe spec.G, it spec.S) {
+	var subject DesiredScalingStock
+	var envFake *FakeEnvironment
+	var applied []int32
+
+	it.Before(func() {
+		envFake = NewFakeEnvironment()
+		envFake.TheTime = time.Unix(0, 0)
+		applied = make([]int32, 0)
+		subject = NewDesiredScalingStock(envFake, func(target int32) {
+			applied = append(applied, target)
+		})
+	})
+
+	describe("Name()", func() {
+		it("calls itself DesiredScaling", func() {
+			assert.Equal(t, simulator.StockName("DesiredScaling"), subject.Name())
+		})
+	})
+
+	describe("ScaleTo()", func() {
+		it.Before(func() {
+			subject.ScaleTo(5, 3*time.Second)
+		})
+
+		it("holds the decision until it is actuated", func() {
+			assert.Equal(t, uint64(1), subject.Count())
+			assert.Empty(t, applied)
+		})
+
+		it("schedules the decision to be actuated after the delay", func() {
+			assert.Len(t, envFake.Movements, 1)
+			assert.Equal(t, simulator.MovementKind("actuate_scaling"), envFake.Movements[0].Kind())
+			assert.Equal(t, time.Unix(3, 0), envFake.Movements[0].OccursAt())
+			assert.Equal(t, subject, envFake.Movements[0].From())
+			assert.Equal(t, subject, envFake.Movements[0].To())
+		})
+	})
+
+	describe("Add()", func() {
+		it("applies the target when the decision is actuated", func() {
+			subject.ScaleTo(5, time.Second)
+			subject.ScaleTo(2, 2*time.Second)
+
+			first := subject.Remove(subject.EntitiesInStock()[0])
+			assert.NoError(t, subject.Add(first))
+			assert.Equal(t, []int32{5}, applied)
+			assert.Equal(t, uint64(1), subject.Count())
+		})
+
+		it("rejects decisions it isn't holding", func() {
+			assert.Error(t, subject.Add(simulator.NewEntity("scaling-decision-99", "ScalingDecision")))
+		})
+	})
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+type DistributionKind string
+
+const (
+	DistributionConstant    DistributionKind = "constant"
+	DistributionUniform     DistributionKind = "uniform"
+	DistributionExponential DistributionKind = "exponential"
+	// DistributionErlang sums Shape exponentials; smoother than exponential.
+	DistributionErlang DistributionKind = "erlang"
+	// DistributionHyperexponential mixes two exponentials so that the squared coefficient
+	// of variation is Shape; burstier than exponential.
+	DistributionHyperexponential DistributionKind = "hyperexponential"
+	// DistributionPareto is heavy-tailed, with Shape as its tail index; the variance is infinite below 2.
+	DistributionPareto DistributionKind = "pareto"
+)
+
+type DistributionConfig struct {
+	Kind  DistributionKind `json:"kind"`
+	Mean  time.Duration    `json:"mean"`
+	Min   time.Duration    `json:"min,omitempty"`
+	Max   time.Duration    `json:"max,omitempty"`
+	Shape float64          `json:"shape,omitempty"`
+}
+
+type Distribution interface {
+	Sample() time.Duration
+}
+
+type constantDistribution struct {
+	value time.Duration
+}
+
+func (cd *constantDistribution) Sample() time.Duration {
+	return cd.value
+}
+
+type uniformDistribution struct {
+	min time.Duration
+	max time.Duration
+}
+
+func (ud *uniformDistribution) Sample() time.Duration {
+	if ud.max <= ud.min {
+		return ud.min
+	}
+	return ud.min + time.Duration(rand.Int63n(int64(ud.max-ud.min)))
+}
+
+type exponentialDistribution struct {
+	mean time.Duration
+}
+
+func (ed *exponentialDistribution) Sample() time.Duration {
+	return time.Duration(rand.ExpFloat64() * float64(ed.mean))
+}
+
+type erlangDistribution struct {
+	mean   time.Duration
+	phases int
+}
+
+func (ed *erlangDistribution) Sample() time.Duration {
+	var total float64
+	for i := 0; i < ed.phases; i++ {
+		total += rand.ExpFloat64()
+	}
+	return time.Duration(total / float64(ed.phases) * float64(ed.mean))
+}
+
+// hyperexponentialDistribution uses balanced means: each branch contributes half the mean.
+type hyperexponentialDistribution struct {
+	mean time.Duration
+	p    float64
+}
+
+func (hd *hyperexponentialDistribution) Sample() time.Duration {
+	if rand.Float64() < hd.p {
+		return time.Duration(rand.ExpFloat64() / (2 * hd.p) * float64(hd.mean))
+	}
+	return time.Duration(rand.ExpFloat64() / (2 * (1 - hd.p)) * float64(hd.mean))
+}
+
+type paretoDistribution struct {
+	scale float64
+	shape float64
+}
+
+func (pd *paretoDistribution) Sample() time.Duration {
+	return time.Duration(pd.scale / math.Pow(1-rand.Float64(), 1/pd.shape))
+}
+
+// Validate reports kinds that are unknown and parameters that would leave the distribution without a mean.
+func (c DistributionConfig) Validate() error {
+	if c.Mean < 0 || c.Min < 0 || c.Max < 0 {
+		return fmt.Errorf("distribution durations must not be negative, got mean %v, min %v and max %v", c.Mean, c.Min, c.Max)
+	}
+
+	switch c.Kind {
+	case DistributionConstant, "", DistributionUniform, DistributionExponential:
+	case DistributionErlang:
+		// no shape gives a single phase, which is exponential
+		if c.Shape != 0 && (c.Shape < 1 || c.Shape != math.Trunc(c.Shape)) {
+			return fmt.Errorf("erlang distributions need a whole number of phases as their shape, got %f", c.Shape)
+		}
+	case DistributionHyperexponential:
+		if c.Shape < 1 {
+			return fmt.Errorf("hyperexponential distributions need a shape (squared coefficient of variation) of at least 1, got %f", c.Shape)
+		}
+	case DistributionPareto:
+		if c.Shape <= 1 {
+			return fmt.Errorf("pareto distributions need a shape above 1 to have a mean, got %f", c.Shape)
+		}
+	default:
+		return fmt.Errorf("unknown distribution kind '%s'", c.Kind)
+	}
+	return nil
+}
+
+func NewDistribution(config DistributionConfig) Distribution {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	switch config.Kind {
+	case DistributionUniform:
+		return &uniformDistribution{min: config.Min, max: config.Max}
+	case DistributionExponential:
+		return &exponentialDistribution{mean: config.Mean}
+	case DistributionErlang:
+		phases := int(config.Shape)
+		if phases < 1 {
+			phases = 1
+		}
+		return &erlangDistribution{mean: config.Mean, phases: phases}
+	case DistributionHyperexponential:
+		return &hyperexponentialDistribution{
+			mean: config.Mean,
+			p:    (1 + math.Sqrt((config.Shape-1)/(config.Shape+1))) / 2,
+		}
+	case DistributionPareto:
+		return &paretoDistribution{
+			scale: float64(config.Mean) * (config.Shape - 1) / config.Shape,
+			shape: config.Shape,
+		}
+	default:
+		return &constantDistribution{value: config.Mean}
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistribution(t *testing.T) {
+	spec.Run(t, "Distributions", testDistribution, spec.Report(report.Terminal{}))
+}
+
+func testDistribution(t *testing.T, describe spec.G, it spec.S) {
+	var subject Distribution
+
+	describe("NewDistribution()", func() {
+		describe("constant", func() {
+			it.Before(func() {
+				subject = NewDistribution(DistributionConfig{Kind: DistributionConstant, Mean: 3 * time.Second})
+			})
+
+			it("always gives the mean", func() {
+				assert.Equal(t, 3*time.Second, subject.Sample())
+				assert.Equal(t, 3*time.Second, subject.Sample())
+			})
+		})
+
+		describe("when no kind is given", func() {
+			it.Before(func() {
+				subject = NewDistribution(DistributionConfig{Mean: time.Second})
+			})
+
+			it("defaults to constant", func() {
+				assert.IsType(t, &constantDistribution{}, subject)
+			})
+		})
+
+		describe("uniform", func() {
+			it.Before(func() {
+				subject = NewDistribution(DistributionConfig{Kind: DistributionUniform, Min: time.Second, Max: 2 * time.Second})
+			})
+
+			it("samples between min and max", func() {
+				for i := 0; i < 100; i++ {
+					sample := subject.Sample()
+					assert.True(t, sample >= time.Second)
+					assert.True(t, sample < 2*time.Second)
+				}
+			})
+		})
+
+		describe("exponential", func() {
+			it.Before(func() {
+				subject = NewDistribution(DistributionConfig{Kind: DistributionExponential, Mean: time.Second})
+			})
+
+			it("has roughly the configured mean", func() {
+				var total time.Duration
+				for i := 0; i < 10000; i++ {
+					sample := subject.Sample()
+					assert.True(t, sample >= 0)
+					total += sample
+				}
+				assert.InDelta(t, float64(time.Second), float64(total/10000), float64(100*time.Millisecond))
+			})
+		})
+
+		describe("erlang", func() {
+			it.Before(func() {
+				subject = NewDistribution(DistributionConfig{Kind: DistributionErlang, Mean: time.Second, Shape: 4})
+			})
+
+			it("has roughly the configured mean and less variance than exponential", func() {
+				var total, squares float64
+				for i := 0; i < 10000; i++ {
+					sample := subject.Sample().Seconds()
+					total += sample
+					squares += sample * sample
+				}
+				mean := total / 10000
+				assert.InDelta(t, 1.0, mean, 0.1)
+				assert.InDelta(t, 0.25, squares/10000-mean*mean, 0.05)
+			})
+		})
+
+		describe("hyperexponential", func() {
+			it.Before(func() {
+				subject = NewDistribution(DistributionConfig{Kind: DistributionHyperexponential, Mean: time.Second, Shape: 4})
+			})
+
+			it("has roughly the configured mean and more variance than exponential", func() {
+				var total, squares float64
+				for i := 0; i < 50000; i++ {
+					sample := subject.Sample().Seconds()
+					total += sample
+					squares += sample * sample
+				}
+				mean := total / 50000
+				assert.InDelta(t, 1.0, mean, 0.1)
+				assert.InDelta(t, 4.0, squares/50000-mean*mean, 1.0)
+			})
+
+			it("panics when the shape is below 1", func() {
+				assert.Panics(t, func() {
+					NewDistribution(DistributionConfig{Kind: DistributionHyperexponential, Mean: time.Second, Shape: 0.5})
+				})
+			})
+		})
+
+		describe("pareto", func() {
+			it.Before(func() {
+				subject = NewDistribution(DistributionConfig{Kind: DistributionPareto, Mean: time.Second, Shape: 3})
+			})
+
+			it("never samples below its scale", func() {
+				for i := 0; i < 1000; i++ {
+					assert.True(t, subject.Sample() >= 666*time.Millisecond)
+				}
+			})
+
+			it("has roughly the configured mean", func() {
+				var total time.Duration
+				for i := 0; i < 50000; i++ {
+					total += subject.Sample()
+				}
+				assert.InDelta(t, float64(time.Second), float64(total/50000), float64(100*time.Millisecond))
+			})
+
+			it("panics when the shape leaves it without a mean", func() {
+				assert.Panics(t, func() {
+					NewDistribution(DistributionConfig{Kind: DistributionPareto, Mean: time.Second, Shape: 1})
+				})
+			})
+		})
+
+		describe("unknown kinds", func() {
+			it("panics", func() {
+				assert.Panics(t, func() {
+					NewDistribution(DistributionConfig{Kind: "bogus"})
+				})
+			})
+		})
+	})
+
+	describe("Validate()", func() {
+		it("accepts a known kind", func() {
+			assert.NoError(t, DistributionConfig{Kind: DistributionExponential, Mean: time.Second}.Validate())
+		})
+
+		it("rejects unknown kinds", func() {
+			assert.Error(t, DistributionConfig{Kind: "bogus"}.Validate())
+		})
+
+		it("rejects erlang shapes that aren't a whole number of phases", func() {
+			assert.Error(t, DistributionConfig{Kind: DistributionErlang, Mean: time.Second, Shape: 2.5}.Validate())
+			assert.Error(t, DistributionConfig{Kind: DistributionErlang, Mean: time.Second, Shape: -1}.Validate())
+			assert.NoError(t, DistributionConfig{Kind: DistributionErlang, Mean: time.Second}.Validate())
+		})
+
+		it("rejects hyperexponential shapes below 1", func() {
+			assert.Error(t, DistributionConfig{Kind: DistributionHyperexponential, Mean: time.Second, Shape: 0.5}.Validate())
+		})
+
+		it("rejects pareto shapes of 1 or less", func() {
+			assert.Error(t, DistributionConfig{Kind: DistributionPareto, Mean: time.Second, Shape: 1}.Validate())
+		})
+
+		it("rejects negative durations", func() {
+			assert.Error(t, DistributionConfig{Kind: DistributionConstant, Mean: -time.Second}.Validate())
+		})
+	})
+}
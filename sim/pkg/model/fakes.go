@@ -42,6 +42,16 @@ func (fe *FakeEnvironment) AddToSchedule(movement simulator.Movement) (added boo
 	return true
 }
 
+func (fe *FakeEnvironment) RemoveFromSchedule(movement simulator.Movement) (removed bool) {
+	for i, mv := range fe.Movements {
+		if mv == movement {
+			fe.Movements = append(fe.Movements[:i], fe.Movements[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 func (fe *FakeEnvironment) Run() (completed []simulator.CompletedMovement, ignored []simulator.IgnoredMovement, err error) {
 	return nil, nil, nil
 }
@@ -77,6 +87,8 @@ type FakeReplica struct {
 	DeactivateCalled                   bool
 	RequestsProcessingCalled           bool
 	StatCalled                         bool
+	FakeStats                          []*proto.Stat
+	ResizedTo                          float64
 	FakeReplicaNum                     int
 	ProcessingStock                    RequestsProcessingStock
 	totalCPUCapacityMillisPerSecond    float64
@@ -113,20 +125,27 @@ func (fr *FakeReplica) RequestsProcessing() RequestsProcessingStock {
 
 func (fr *FakeReplica) Stats() []*proto.Stat {
 	fr.StatCalled = true
+	if fr.FakeStats != nil {
+		return fr.FakeStats
+	}
 	return make([]*proto.Stat, 0)
 }
 
+func (fr *FakeReplica) Resize(cpuRequest float64) {
+	fr.ResizedTo = cpuRequest
+}
+
 func (fr *FakeReplica) GetCPUCapacity() float64 {
 	return fr.totalCPUCapacityMillisPerSecond
 }
 
 func (fr *FakeReplica) MetricsTicktock() MetricsTicktockStock {
-	return NewMetricsTickTockStock(NewFakeEnvironment(), fr)
+	return NewMetricsTickTockStock(NewFakeEnvironment(), fr, MetricsConfig{})
 }
 
 func NewFakeReplica() *FakeReplica {
 	fakeReplica := &FakeReplica{}
-	fakeReplica.metricsTicktockStock = NewMetricsTickTockStock(NewFakeEnvironment(), fakeReplica)
+	fakeReplica.metricsTicktockStock = NewMetricsTickTockStock(NewFakeEnvironment(), fakeReplica, MetricsConfig{})
 	return fakeReplica
 }
 
@@ -136,9 +155,17 @@ type FakePluginPartition struct {
 	scaleTo     int32
 	plugin      skplug.Plugin
 	verticalRec []*proto.RecommendedPodResources
+	events      []FakeEvent
+}
+
+type FakeEvent struct {
+	Time   int64
+	Type   proto.EventType
+	Object skplug.Object
 }
 
 func (fp *FakePluginPartition) Event(time int64, typ proto.EventType, object skplug.Object) error {
+	fp.events = append(fp.events, FakeEvent{Time: time, Type: typ, Object: object})
 	return nil
 }
 
@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+)
+
+// MetricAliasConfig reports a built-in metric again under a user-defined name, scaled into
+// whatever units the autoscaler expects. Aliases are not new measurements: they let autoscalers
+// that scale on a named CUSTOM metric, such as the HPA's Pods metrics, be driven by one of the
+// built-in ones.
+type MetricAliasConfig struct {
+	Name   string
+	Source proto.MetricType
+	Scale  float64
+}
+
+func appendMetricAliases(stats []*proto.Stat, metricAliases []MetricAliasConfig) []*proto.Stat {
+	aliased := make([]*proto.Stat, 0)
+	for _, ma := range metricAliases {
+		for _, stat := range stats {
+			if stat.Type != ma.Source {
+				continue
+			}
+			aliased = append(aliased, &proto.Stat{
+				Time:    stat.Time,
+				PodName: stat.PodName,
+				Type:    proto.MetricType_CUSTOM,
+				Value:   int32(float64(stat.Value) * ma.Scale),
+				Name:    ma.Name,
+			})
+		}
+	}
+	return append(stats, aliased...)
+}
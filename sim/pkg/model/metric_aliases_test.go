@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricAliases(t *testing.T) {
+	spec.Run(t, "Metric aliases", testMetricAliases, spec.Report(report.Terminal{}))
+}
+
+func testMetricAliases(t *testing.T, describe spec.G, it spec.S) {
+	describe("appendMetricAliases()", func() {
+		var stats []*proto.Stat
+
+		it.Before(func() {
+			stats = []*proto.Stat{
+				{Time: 10, PodName: "replica-1", Type: proto.MetricType_CPU_MILLIS, Value: 500},
+				{Time: 10, PodName: "replica-1", Type: proto.MetricType_REQUESTS_PER_SECOND_MILLIS, Value: 2500},
+			}
+			stats = appendMetricAliases(stats, []MetricAliasConfig{
+				{Name: "rps", Source: proto.MetricType_REQUESTS_PER_SECOND_MILLIS, Scale: 0.001},
+				{Name: "queue", Source: proto.MetricType_QUEUE_DEPTH, Scale: 1},
+			})
+		})
+
+		it("keeps the original stats", func() {
+			assert.Equal(t, proto.MetricType_CPU_MILLIS, stats[0].Type)
+			assert.Equal(t, proto.MetricType_REQUESTS_PER_SECOND_MILLIS, stats[1].Type)
+		})
+
+		it("appends a scaled, named stat for each matching source", func() {
+			assert.Len(t, stats, 3)
+			assert.Equal(t, proto.MetricType_CUSTOM, stats[2].Type)
+			assert.Equal(t, "rps", stats[2].Name)
+			assert.Equal(t, "replica-1", stats[2].PodName)
+			assert.Equal(t, int64(10), stats[2].Time)
+			assert.Equal(t, int32(2), stats[2].Value)
+		})
+	})
+}
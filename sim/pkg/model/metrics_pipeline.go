@@ -1,10 +1,31 @@
 package model
 
 import (
+	"math/rand"
 	"skenario/pkg/simulator"
 	"time"
 )
 
+// MetricsConfig describes how metrics travel from replicas to the autoscaler. Zero values fall
+// back to the defaults: a scrape every 10s starting 5s after activation, a constant 4s lag, and
+// instantaneous stats that always arrive.
+type MetricsConfig struct {
+	ScrapeInterval   time.Duration
+	FirstScrapeAfter time.Duration
+	Lag              DistributionConfig
+	// Window averages each scrape with the scrapes before it, like metrics-server's 1m window.
+	Window           time.Duration
+	DropProbability  float64
+	StaleProbability float64
+	Outages          []MetricsOutage
+}
+
+// MetricsOutage is a period during which scraped metrics never reach the autoscaler.
+type MetricsOutage struct {
+	From  time.Time
+	Until time.Time
+}
+
 type MetricsPipelineStock interface {
 	simulator.ThroughStock
 }
@@ -13,6 +34,9 @@ type metricsPipelineStock struct {
 	env      simulator.Environment
 	pipeline simulator.ThroughStock
 	sink     MetricsSinkStock
+	dropped  simulator.SinkStock
+	lag      Distribution
+	config   MetricsConfig
 }
 
 var metricsLagDuration = 4 * time.Second
@@ -38,10 +62,26 @@ func (mpls *metricsPipelineStock) Add(entity simulator.Entity) error {
 	if err != nil {
 		return err
 	}
+
+	lag := mpls.lag.Sample()
+	if lag <= 0 {
+		lag = 1 * time.Nanosecond
+	}
+	arriveAt := mpls.env.CurrentMovementTime().Add(lag)
+
+	if mpls.config.DropProbability > 0 && rand.Float64() < mpls.config.DropProbability {
+		mpls.env.AddToSchedule(simulator.NewMovement("drop_metrics", arriveAt, mpls.pipeline, mpls.dropped, &entity))
+		return nil
+	}
+	if mpls.inOutage(arriveAt) {
+		mpls.env.AddToSchedule(simulator.NewMovement("metrics_outage", arriveAt, mpls.pipeline, mpls.dropped, &entity))
+		return nil
+	}
+
 	//get metrics and pass it to sink with a delay
 	mpls.env.AddToSchedule(simulator.NewMovement(
 		"send_metrics_to_sink",
-		mpls.env.CurrentMovementTime().Add(metricsLagDuration),
+		arriveAt,
 		mpls.pipeline,
 		mpls.sink,
 		&entity,
@@ -53,10 +93,31 @@ func (mpls *metricsPipelineStock) Remove(entity *simulator.Entity) simulator.Ent
 	return mpls.pipeline.Remove(entity)
 }
 
-func NewMetricsPipeLineStock(env simulator.Environment) MetricsPipelineStock {
+func (mpls *metricsPipelineStock) inOutage(at time.Time) bool {
+	for _, outage := range mpls.config.Outages {
+		if !at.Before(outage.From) && at.Before(outage.Until) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mpls *metricsPipelineStock) observeStats(observer statsObserver) {
+	mpls.sink.(statsObservable).observeStats(observer)
+}
+
+func NewMetricsPipeLineStock(env simulator.Environment, config MetricsConfig) MetricsPipelineStock {
+	lagConfig := config.Lag
+	if lagConfig.Kind == "" && lagConfig.Mean == 0 {
+		lagConfig = DistributionConfig{Kind: DistributionConstant, Mean: metricsLagDuration}
+	}
+
 	return &metricsPipelineStock{
 		env:      env,
 		pipeline: simulator.NewArrayThroughStock("MetricsPipeline", "Metrics"),
 		sink:     NewMetricsSinkStock(env),
+		dropped:  simulator.NewSinkStock("MetricsDropped", "Metrics"),
+		lag:      NewDistribution(lagConfig),
+		config:   config,
 	}
 }
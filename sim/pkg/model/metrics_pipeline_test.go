@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"skenario/pkg/simulator"
 	"testing"
+	"time"
 )
 
 func TestMetricsPipelineStock(t *testing.T) {
@@ -21,9 +22,9 @@ func testMetricsPipelineStock(t *testing.T, describe spec.G, it spec.S) {
 	it.Before(func() {
 		envFake = NewFakeEnvironment()
 		failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-		replica := NewReplicaEntity(envFake, &failedSink)
+		replica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 		metrics = NewMetricsEntity(replica.Stats())
-		subject = NewMetricsPipeLineStock(envFake)
+		subject = NewMetricsPipeLineStock(envFake, MetricsConfig{})
 		rawSubject = subject.(*metricsPipelineStock)
 	})
 
@@ -89,6 +90,57 @@ func testMetricsPipelineStock(t *testing.T, describe spec.G, it spec.S) {
 		})
 	})
 
+	describe("configured lag", func() {
+		it("defaults to 4s", func() {
+			err := subject.Add(metrics)
+			assert.NoError(t, err)
+			assert.Equal(t, envFake.TheTime.Add(4*time.Second), envFake.Movements[0].OccursAt())
+		})
+
+		it("samples the lag distribution", func() {
+			subject = NewMetricsPipeLineStock(envFake, MetricsConfig{Lag: DistributionConfig{Kind: DistributionConstant, Mean: 30 * time.Second}})
+			err := subject.Add(metrics)
+			assert.NoError(t, err)
+			assert.Equal(t, envFake.TheTime.Add(30*time.Second), envFake.Movements[0].OccursAt())
+		})
+	})
+
+	describe("dropped scrapes", func() {
+		it.Before(func() {
+			subject = NewMetricsPipeLineStock(envFake, MetricsConfig{DropProbability: 1})
+			err := subject.Add(metrics)
+			assert.NoError(t, err)
+		})
+
+		it("never reach the sink", func() {
+			assert.Equal(t, simulator.MovementKind("drop_metrics"), envFake.Movements[0].Kind())
+			assert.Equal(t, simulator.StockName("MetricsDropped"), envFake.Movements[0].To().Name())
+		})
+	})
+
+	describe("outages", func() {
+		it.Before(func() {
+			envFake.TheTime = time.Unix(0, 0)
+			subject = NewMetricsPipeLineStock(envFake, MetricsConfig{Outages: []MetricsOutage{
+				{From: time.Unix(10, 0), Until: time.Unix(20, 0)},
+			}})
+		})
+
+		it("drops metrics arriving during an outage", func() {
+			envFake.TheTime = time.Unix(8, 0)
+			err := subject.Add(metrics)
+			assert.NoError(t, err)
+			assert.Equal(t, simulator.MovementKind("metrics_outage"), envFake.Movements[0].Kind())
+		})
+
+		it("delivers metrics arriving after an outage", func() {
+			envFake.TheTime = time.Unix(17, 0)
+			err := subject.Add(metrics)
+			assert.NoError(t, err)
+			assert.Equal(t, simulator.MovementKind("send_metrics_to_sink"), envFake.Movements[0].Kind())
+		})
+	})
+
 	describe("Remove()", func() {
 		it.Before(func() {
 			err := subject.Add(metrics)
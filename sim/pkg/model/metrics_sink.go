@@ -1,14 +1,32 @@
 package model
 
-import "skenario/pkg/simulator"
+import (
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+
+	"skenario/pkg/simulator"
+)
 
 type MetricsSinkStock interface {
 	simulator.SinkStock
 }
 
 type metricsSinkStock struct {
-	env  simulator.Environment
-	sink simulator.SinkStock
+	env      simulator.Environment
+	sink     simulator.SinkStock
+	observer statsObserver
+}
+
+// statsObserver is told about every stat delivered to the autoscaler.
+type statsObserver func(stats []*proto.Stat)
+
+func (so statsObserver) delivered(stats []*proto.Stat) {
+	if so != nil {
+		so(stats)
+	}
+}
+
+type statsObservable interface {
+	observeStats(observer statsObserver)
 }
 
 func (mss *metricsSinkStock) Name() simulator.StockName {
@@ -40,9 +58,14 @@ func (mss *metricsSinkStock) Add(entity simulator.Entity) error {
 	if err != nil {
 		panic(err)
 	}
+	mss.observer.delivered(metrics.GetStats())
 	return nil
 }
 
+func (mss *metricsSinkStock) observeStats(observer statsObserver) {
+	mss.observer = observer
+}
+
 func NewMetricsSinkStock(env simulator.Environment) MetricsSinkStock {
 	return &metricsSinkStock{
 		env:  env,
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +22,7 @@ func testMetricsSinkStock(t *testing.T, describe spec.G, it spec.S) {
 	it.Before(func() {
 		envFake = NewFakeEnvironment()
 		failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-		replica := NewReplicaEntity(envFake, &failedSink)
+		replica := NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 		metrics = NewMetricsEntity(replica.Stats())
 		subject = NewMetricsSinkStock(envFake)
 		rawSubject = subject.(*metricsSinkStock)
@@ -68,7 +69,13 @@ func testMetricsSinkStock(t *testing.T, describe spec.G, it spec.S) {
 	})
 
 	describe("Add()", func() {
+		var observed []*proto.Stat
+
 		it.Before(func() {
+			observed = nil
+			rawSubject.observeStats(func(stats []*proto.Stat) {
+				observed = append(observed, stats...)
+			})
 			err := subject.Add(metrics)
 			assert.Nil(t, err)
 		})
@@ -77,5 +84,9 @@ func testMetricsSinkStock(t *testing.T, describe spec.G, it spec.S) {
 			fakePlugin := envFake.ThePlugin.(*FakePluginPartition)
 			assert.Equal(t, fakePlugin.stats, metrics.GetStats())
 		})
+
+		it("tells the observer which stats were delivered", func() {
+			assert.Equal(t, metrics.GetStats(), observed)
+		})
 	})
 }
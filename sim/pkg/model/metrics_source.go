@@ -1,6 +1,13 @@
 package model
 
-import "skenario/pkg/simulator"
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+	"skenario/pkg/simulator"
+)
 
 type MetricsSourceStock interface {
 	simulator.SourceStock
@@ -9,6 +16,16 @@ type MetricsSourceStock interface {
 type metricsSourceStock struct {
 	env           simulator.Environment
 	replicaEntity ReplicaEntity
+	config        MetricsConfig
+	scrapes       []scrape
+	lastScrapeAt  time.Time
+	lastStats     []*proto.Stat
+}
+
+type scrape struct {
+	at     time.Time
+	covers time.Duration // the time since the scrape before, which the replica's stats are averaged over
+	stats  []*proto.Stat
 }
 
 func (mss *metricsSourceStock) Name() simulator.StockName {
@@ -27,13 +44,87 @@ func (mss *metricsSourceStock) EntitiesInStock() []*simulator.Entity {
 	return []*simulator.Entity{}
 }
 
+// Remove scrapes the replica. Stale scrapes resend the previous stats with their original timestamps,
+// as a metrics server serving from its cache would, and are not added to the averaging window.
 func (mss *metricsSourceStock) Remove(entity *simulator.Entity) simulator.Entity {
-	return NewMetricsEntity(mss.replicaEntity.Stats())
+	if mss.lastStats != nil && mss.config.StaleProbability > 0 && rand.Float64() < mss.config.StaleProbability {
+		return NewMetricsEntity(mss.lastStats)
+	}
+
+	stats := mss.replicaEntity.Stats()
+	if mss.config.Window > 0 {
+		stats = mss.windowed(stats)
+	}
+	mss.lastStats = stats
+
+	return NewMetricsEntity(stats)
+}
+
+// windowed averages each stat over the scrapes within the window. Request counts are summed instead.
+// Replicas already average their stats over the time since they were last scraped, so each scrape is
+// weighted by the time it covers; the result is then the average over the whole window rather than
+// an average of averages. The first scrape covers an unknown time and only counts while it is alone.
+func (mss *metricsSourceStock) windowed(stats []*proto.Stat) []*proto.Stat {
+	now := mss.env.CurrentMovementTime()
+	var covers time.Duration
+	if !mss.lastScrapeAt.IsZero() {
+		covers = now.Sub(mss.lastScrapeAt)
+	}
+	mss.lastScrapeAt = now
+	mss.scrapes = append(mss.scrapes, scrape{at: now, covers: covers, stats: stats})
+	for len(mss.scrapes) > 0 && !mss.scrapes[0].at.After(now.Add(-mss.config.Window)) {
+		mss.scrapes = mss.scrapes[1:]
+	}
+
+	type statKey struct {
+		podName    string
+		metricType proto.MetricType
+		name       string
+	}
+	keys := make([]statKey, 0)
+	sums := make(map[statKey]float64)
+	weightedSums := make(map[statKey]float64)
+	weights := make(map[statKey]float64)
+	counts := make(map[statKey]int)
+	for _, s := range mss.scrapes {
+		for _, stat := range s.stats {
+			key := statKey{podName: stat.PodName, metricType: stat.Type, name: stat.Name}
+			if _, ok := counts[key]; !ok {
+				keys = append(keys, key)
+			}
+			sums[key] += float64(stat.Value)
+			weightedSums[key] += float64(stat.Value) * s.covers.Seconds()
+			weights[key] += s.covers.Seconds()
+			counts[key]++
+		}
+	}
+
+	averaged := make([]*proto.Stat, 0, len(keys))
+	for _, key := range keys {
+		var value float64
+		switch {
+		case key.metricType == proto.MetricType_REQUEST_COUNT:
+			value = sums[key]
+		case weights[key] > 0:
+			value = weightedSums[key] / weights[key]
+		default:
+			value = sums[key] / float64(counts[key])
+		}
+		averaged = append(averaged, &proto.Stat{
+			Time:    now.UnixNano(),
+			PodName: key.podName,
+			Type:    key.metricType,
+			Value:   int32(math.Round(value)),
+			Name:    key.name,
+		})
+	}
+	return averaged
 }
 
-func NewMetricsSourceStock(env simulator.Environment, replicaEntity ReplicaEntity) MetricsSourceStock {
+func NewMetricsSourceStock(env simulator.Environment, replicaEntity ReplicaEntity, config MetricsConfig) MetricsSourceStock {
 	return &metricsSourceStock{
 		env:           env,
 		replicaEntity: replicaEntity,
+		config:        config,
 	}
 }
@@ -1,11 +1,13 @@
 package model
 
 import (
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 	"github.com/stretchr/testify/assert"
 	"skenario/pkg/simulator"
 	"testing"
+	"time"
 )
 
 func TestMetricsSourceStock(t *testing.T) {
@@ -21,8 +23,8 @@ func testMetricsSourceStock(t *testing.T, describe spec.G, it spec.S) {
 	it.Before(func() {
 		envFake = NewFakeEnvironment()
 		failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-		replica = NewReplicaEntity(envFake, &failedSink)
-		subject = NewMetricsSourceStock(envFake, replica)
+		replica = NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
+		subject = NewMetricsSourceStock(envFake, replica, MetricsConfig{})
 		rawSubject = subject.(*metricsSourceStock)
 	})
 
@@ -74,4 +76,75 @@ func testMetricsSourceStock(t *testing.T, describe spec.G, it spec.S) {
 			assert.Equal(t, simulator.EntityKind("Metrics"), entity1.Kind())
 		})
 	})
+
+	describe("stale scrapes", func() {
+		it("repeat the previous stats", func() {
+			subject = NewMetricsSourceStock(envFake, replica, MetricsConfig{StaleProbability: 1})
+			first := subject.Remove(nil).(MetricsEntity)
+			second := subject.Remove(nil).(MetricsEntity)
+			assert.Equal(t, first.GetStats(), second.GetStats())
+		})
+	})
+
+	describe("averaging window", func() {
+		var fakeReplica *FakeReplica
+		var stats []*proto.Stat
+
+		it.Before(func() {
+			fakeReplica = NewFakeReplica()
+			subject = NewMetricsSourceStock(envFake, fakeReplica, MetricsConfig{Window: time.Minute})
+
+			for i, concurrency := range []int32{1000, 2000, 6000} {
+				envFake.TheTime = time.Unix(int64(i*30), 0)
+				fakeReplica.FakeStats = []*proto.Stat{
+					{PodName: "replica", Type: proto.MetricType_CONCURRENT_REQUESTS_MILLIS, Value: concurrency},
+					{PodName: "replica", Type: proto.MetricType_REQUEST_COUNT, Value: concurrency / 1000},
+				}
+				stats = subject.Remove(nil).(MetricsEntity).GetStats()
+			}
+		})
+
+		it("averages stats scraped within the window", func() {
+			assert.Equal(t, int32(4000), stats[0].Value)
+		})
+
+		it("sums request counts within the window", func() {
+			assert.Equal(t, int32(8), stats[1].Value)
+		})
+
+		it("stamps the stats with the current time", func() {
+			assert.Equal(t, time.Unix(60, 0).UnixNano(), stats[0].Time)
+		})
+
+		describe("when scrapes cover different lengths of time", func() {
+			it.Before(func() {
+				envFake.TheTime = time.Unix(70, 0)
+				fakeReplica.FakeStats = []*proto.Stat{
+					{PodName: "replica", Type: proto.MetricType_CONCURRENT_REQUESTS_MILLIS, Value: 1001},
+				}
+				stats = subject.Remove(nil).(MetricsEntity).GetStats()
+			})
+
+			it("weights each scrape by the time it covers", func() {
+				// 30s at 2000, 30s at 6000 and 10s at 1001
+				assert.Equal(t, int32(3572), stats[0].Value)
+			})
+		})
+	})
+
+	describe("averaging window with small values", func() {
+		it("rounds rather than truncating the average", func() {
+			fakeReplica := NewFakeReplica()
+			subject = NewMetricsSourceStock(envFake, fakeReplica, MetricsConfig{Window: time.Minute})
+			var stats []*proto.Stat
+			for i, cpu := range []int32{3, 3, 4} {
+				envFake.TheTime = time.Unix(int64(i*10), 0)
+				fakeReplica.FakeStats = []*proto.Stat{
+					{PodName: "replica", Type: proto.MetricType_CPU_MILLIS, Value: cpu},
+				}
+				stats = subject.Remove(nil).(MetricsEntity).GetStats()
+			}
+			assert.Equal(t, int32(4), stats[0].Value)
+		})
+	})
 }
@@ -8,6 +8,7 @@ import (
 
 type MetricsTicktockStock interface {
 	simulator.ThroughStock
+	FirstScrapeAfter() time.Duration
 }
 
 type metricsTicktockStock struct {
@@ -15,9 +16,12 @@ type metricsTicktockStock struct {
 	replicaEntity   ReplicaEntity
 	metricsSource   MetricsSourceStock
 	metricsPipeline MetricsPipelineStock
+	scrapeInterval  time.Duration
+	firstScrape     time.Duration
 }
 
 var metricsTickInterval = 10 * time.Second
+var metricsFirstTickAfter = 5 * time.Second
 
 func (mts *metricsTicktockStock) Name() simulator.StockName {
 	return "Metrics Ticktock"
@@ -51,7 +55,7 @@ func (mts *metricsTicktockStock) Add(entity simulator.Entity) error {
 
 	mts.env.AddToSchedule(simulator.NewMovement(
 		"metrics_tick",
-		mts.env.CurrentMovementTime().Add(metricsTickInterval),
+		mts.env.CurrentMovementTime().Add(mts.scrapeInterval),
 		mts,
 		mts,
 		&entity,
@@ -62,11 +66,30 @@ func (mts *metricsTicktockStock) Remove(entity *simulator.Entity) simulator.Enti
 	return mts.replicaEntity
 }
 
-func NewMetricsTickTockStock(env simulator.Environment, replicaEntity ReplicaEntity) MetricsTicktockStock {
+func (mts *metricsTicktockStock) FirstScrapeAfter() time.Duration {
+	return mts.firstScrape
+}
+
+func (mts *metricsTicktockStock) observeStats(observer statsObserver) {
+	mts.metricsPipeline.(statsObservable).observeStats(observer)
+}
+
+func NewMetricsTickTockStock(env simulator.Environment, replicaEntity ReplicaEntity, config MetricsConfig) MetricsTicktockStock {
+	scrapeInterval := config.ScrapeInterval
+	if scrapeInterval == 0 {
+		scrapeInterval = metricsTickInterval
+	}
+	firstScrape := config.FirstScrapeAfter
+	if firstScrape == 0 {
+		firstScrape = metricsFirstTickAfter
+	}
+
 	return &metricsTicktockStock{
 		env:             env,
 		replicaEntity:   replicaEntity,
-		metricsSource:   NewMetricsSourceStock(env, replicaEntity),
-		metricsPipeline: NewMetricsPipeLineStock(env),
+		metricsSource:   NewMetricsSourceStock(env, replicaEntity, config),
+		metricsPipeline: NewMetricsPipeLineStock(env, config),
+		scrapeInterval:  scrapeInterval,
+		firstScrape:     firstScrape,
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"skenario/pkg/simulator"
 	"testing"
+	"time"
 )
 
 func TestMetricsTicktockStock(t *testing.T) {
@@ -21,8 +22,8 @@ func testMetricsTicktockStock(t *testing.T, describe spec.G, it spec.S) {
 	it.Before(func() {
 		envFake = NewFakeEnvironment()
 		failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-		replica = NewReplicaEntity(envFake, &failedSink)
-		subject = NewMetricsTickTockStock(envFake, replica)
+		replica = NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
+		subject = NewMetricsTickTockStock(envFake, replica, MetricsConfig{})
 		rawSubject = subject.(*metricsTicktockStock)
 	})
 
@@ -93,6 +94,25 @@ func testMetricsTicktockStock(t *testing.T, describe spec.G, it spec.S) {
 		})
 	})
 
+	describe("scrape interval", func() {
+		it("defaults to a 10s interval, starting after 5s", func() {
+			assert.Equal(t, 5*time.Second, subject.FirstScrapeAfter())
+
+			err := subject.Add(replica)
+			assert.NoError(t, err)
+			assert.Equal(t, envFake.TheTime.Add(10*time.Second), envFake.Movements[1].OccursAt())
+		})
+
+		it("can be configured", func() {
+			subject = NewMetricsTickTockStock(envFake, replica, MetricsConfig{ScrapeInterval: 15 * time.Second, FirstScrapeAfter: time.Second})
+			assert.Equal(t, time.Second, subject.FirstScrapeAfter())
+
+			err := subject.Add(replica)
+			assert.NoError(t, err)
+			assert.Equal(t, envFake.TheTime.Add(15*time.Second), envFake.Movements[1].OccursAt())
+		})
+	})
+
 	describe("Remove()", func() {
 		it("gives back the one Replica", func() {
 			assert.Equal(t, subject.Remove(nil), subject.Remove(nil))
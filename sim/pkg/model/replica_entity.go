@@ -20,6 +20,7 @@ import (
 	"github.com/josephburnett/sk-plugin/pkg/skplug"
 	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
 	"skenario/pkg/simulator"
+	"time"
 )
 
 type Replica interface {
@@ -29,6 +30,7 @@ type Replica interface {
 	MetricsTicktock() MetricsTicktockStock
 	Stats() []*proto.Stat
 	GetCPUCapacity() float64
+	Resize(cpuRequest float64)
 }
 
 type ReplicaEntity interface {
@@ -36,22 +38,42 @@ type ReplicaEntity interface {
 	Replica
 }
 
+// cpuResizable processing stocks adjust to their replica's CPU capacity being changed by apply.
+type cpuResizable interface {
+	resizeCPU(apply func())
+}
+
 type replicaEntity struct {
 	env                                simulator.Environment
 	number                             int
 	requestsProcessing                 RequestsProcessingStock
 	requestsComplete                   simulator.SinkStock
 	requestsFailed                     simulator.SinkStock
-	numRequestsSinceStat               int32
+	outcomes                           requestOutcomes
+	lastStatTime                       time.Time
+	metricAliases                      []MetricAliasConfig
 	totalCPUCapacityMillisPerSecond    float64
 	occupiedCPUCapacityMillisPerSecond float64
 	tickTock                           MetricsTicktockStock
+	concurrencyUsage                   timeWeighted
+	cpuUsage                           timeWeighted
+	activatedAt                        time.Time
+	billing                            *replicaBilling
+	warmup                             replicaWarmup
+	revision                           int
 }
 
 var replicaNum int
 
+// defaultCPUCapacityMillisPerSecond is the CPU request of replicas the vertical autoscaler hasn't sized.
+const defaultCPUCapacityMillisPerSecond = 1000
+
 func (re *replicaEntity) Activate() {
-	now := re.env.CurrentMovementTime().UnixNano()
+	re.lastStatTime = re.env.CurrentMovementTime()
+	re.activatedAt = re.lastStatTime
+	re.warmup.activatedAt = re.activatedAt
+	re.recordUsage(re.lastStatTime)
+	now := re.lastStatTime.UnixNano()
 	err := re.env.Plugin().Event(now, proto.EventType_CREATE, &skplug.Pod{
 		Name: string(re.Name()),
 		// TODO: enumerate states in proto.
@@ -74,6 +96,26 @@ func (re *replicaEntity) Deactivate() {
 	}
 }
 
+// Resize changes the replica's CPU request without restarting it, as in-place pod resize does.
+func (re *replicaEntity) Resize(cpuRequest float64) {
+	now := re.env.CurrentMovementTime()
+	re.requestsProcessing.(cpuResizable).resizeCPU(func() {
+		re.totalCPUCapacityMillisPerSecond = cpuRequest
+	})
+	re.recordUsage(now)
+	re.billing.resize(now, cpuRequest)
+
+	err := re.env.Plugin().Event(now.UnixNano(), proto.EventType_UPDATE, &skplug.Pod{
+		Name:           string(re.Name()),
+		State:          "active",
+		LastTransition: re.activatedAt.UnixNano(),
+		CpuRequest:     int32(re.GetCPUCapacity()),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
 func (re *replicaEntity) RequestsProcessing() RequestsProcessingStock {
 	return re.requestsProcessing
 }
@@ -86,24 +128,63 @@ func (re *replicaEntity) Stats() []*proto.Stat {
 	atTime := re.env.CurrentMovementTime()
 	stats := make([]*proto.Stat, 0)
 
+	// averaged over the time since the last stat, as Knative's queue-proxy does
 	stats = append(stats, &proto.Stat{
 		Time:    atTime.UnixNano(),
 		PodName: string(re.Name()),
 		Type:    proto.MetricType_CONCURRENT_REQUESTS_MILLIS,
-		Value:   int32(re.requestsProcessing.Count() * 1000),
+		Value:   int32(re.concurrencyUsage.average(atTime) * 1000),
 	})
-	cpuUsage := int32(re.occupiedCPUCapacityMillisPerSecond)
 	stats = append(stats, &proto.Stat{
 		Time:    atTime.UnixNano(),
 		PodName: string(re.Name()),
 		Type:    proto.MetricType_CPU_MILLIS,
-		Value:   cpuUsage,
+		Value:   int32(re.cpuUsage.average(atTime)),
+	})
+
+	stats = append(stats, &proto.Stat{
+		Time:    atTime.UnixNano(),
+		PodName: string(re.Name()),
+		Type:    proto.MetricType_INSTANTANEOUS_CONCURRENT_REQUESTS_MILLIS,
+		Value:   int32(re.concurrency() * 1000),
+	})
+	stats = append(stats, &proto.Stat{
+		Time:    atTime.UnixNano(),
+		PodName: string(re.Name()),
+		Type:    proto.MetricType_INSTANTANEOUS_CPU_MILLIS,
+		Value:   int32(re.occupiedCPUCapacityMillisPerSecond),
 	})
 
-	re.numRequestsSinceStat = 0
-	// TODO: report request count
+	requestCount := re.requestsProcessing.RequestCount()
+	stats = append(stats, &proto.Stat{
+		Time:    atTime.UnixNano(),
+		PodName: string(re.Name()),
+		Type:    proto.MetricType_REQUEST_COUNT,
+		Value:   requestCount,
+	})
+	if elapsed := atTime.Sub(re.lastStatTime); !re.lastStatTime.IsZero() && elapsed > 0 {
+		stats = append(stats, &proto.Stat{
+			Time:    atTime.UnixNano(),
+			PodName: string(re.Name()),
+			Type:    proto.MetricType_REQUESTS_PER_SECOND_MILLIS,
+			Value:   int32(float64(requestCount) * 1000 / elapsed.Seconds()),
+		})
+	}
+
+	stats = append(stats, re.outcomes.stats(atTime, string(re.Name()))...)
+	re.outcomes.reset()
+	re.lastStatTime = atTime
+
+	return appendMetricAliases(stats, re.metricAliases)
+}
+
+func (re *replicaEntity) concurrency() uint64 {
+	return re.requestsProcessing.Count() + re.requestsProcessing.RequestsAwaitingCalls().Count()
+}
 
-	return stats
+func (re *replicaEntity) recordUsage(at time.Time) {
+	re.concurrencyUsage.set(at, float64(re.concurrency()))
+	re.cpuUsage.set(at, re.occupiedCPUCapacityMillisPerSecond)
 }
 
 func (re *replicaEntity) Name() simulator.EntityName {
@@ -118,17 +199,31 @@ func (re *replicaEntity) GetCPUCapacity() float64 {
 	return re.totalCPUCapacityMillisPerSecond
 }
 
-func NewReplicaEntity(env simulator.Environment, failedSink *simulator.SinkStock) ReplicaEntity {
+func NewReplicaEntity(env simulator.Environment, config ReplicasConfig, failedSink *simulator.SinkStock) ReplicaEntity {
 	replicaNum++
 
 	re := &replicaEntity{
 		env:                                env,
 		number:                             replicaNum,
-		totalCPUCapacityMillisPerSecond:    1000,
+		totalCPUCapacityMillisPerSecond:    defaultCPUCapacityMillisPerSecond,
 		occupiedCPUCapacityMillisPerSecond: 0,
+		metricAliases:                      config.MetricAliases,
+		warmup:                             replicaWarmup{config: config.Warmup},
+		billing:                            &replicaBilling{},
+	}
+	var requestsComplete simulator.SinkStock = NewRequestsSinkStock(simulator.StockName(fmt.Sprintf("RequestsComplete [%d]", re.number)), true)
+	re.requestsComplete = newRequestsOutcomeStock(env, &requestsComplete, true, &re.outcomes)
+	re.requestsFailed = newRequestsOutcomeStock(env, failedSink, false, &re.outcomes)
+	switch config.CPUModel {
+	case "", CPUModelSakasegawa:
+		re.requestsProcessing = NewRequestsProcessingStock(env, re.number, re.requestsComplete, &re.requestsFailed, &re.totalCPUCapacityMillisPerSecond, &re.occupiedCPUCapacityMillisPerSecond)
+	case CPUModelProcessorSharing:
+		re.requestsProcessing = NewRequestsProcessorSharingStock(env, re.number, re.requestsComplete, &re.requestsFailed, &re.totalCPUCapacityMillisPerSecond, &re.occupiedCPUCapacityMillisPerSecond)
+	default:
+		panic(fmt.Errorf("unknown CPU model '%s'", config.CPUModel))
 	}
-	re.requestsComplete = simulator.NewSinkStock(simulator.StockName(fmt.Sprintf("RequestsComplete [%d]", re.number)), "Request")
-	re.requestsProcessing = NewRequestsProcessingStock(env, re.number, re.requestsComplete, failedSink, &re.totalCPUCapacityMillisPerSecond, &re.occupiedCPUCapacityMillisPerSecond)
-	re.tickTock = NewMetricsTickTockStock(env, re)
+	re.requestsProcessing.(usageObservable).observeUsage(re.recordUsage)
+	re.requestsProcessing.(warmable).warmUpWith(&re.warmup)
+	re.tickTock = NewMetricsTickTockStock(env, re, config.Metrics)
 	return re
 }
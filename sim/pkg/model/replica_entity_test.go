@@ -17,6 +17,7 @@ package model
 
 import (
 	"fmt"
+	"github.com/josephburnett/sk-plugin/pkg/skplug"
 	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
 	"testing"
 	"time"
@@ -39,7 +40,7 @@ func testReplicaEntity(t *testing.T, describe spec.G, it spec.S) {
 	it.Before(func() {
 		envFake = NewFakeEnvironment()
 		failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-		subject = NewReplicaEntity(envFake, &failedSink)
+		subject = NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 		assert.NotNil(t, subject)
 
 		rawSubject = subject.(*replicaEntity)
@@ -53,13 +54,36 @@ func testReplicaEntity(t *testing.T, describe spec.G, it spec.S) {
 		it("sets a RequestsComplete stock", func() {
 			assert.Equal(t, simulator.StockName(fmt.Sprintf("RequestsComplete [%d]", rawSubject.number)), rawSubject.requestsComplete.Name())
 		})
+
+		describe("CPU model", func() {
+			var failedSink simulator.SinkStock
+
+			it.Before(func() {
+				failedSink = simulator.NewSinkStock("fake-requestsFailed", "Request")
+			})
+
+			it("uses the Sakasegawa approximation by default", func() {
+				assert.IsType(t, &requestsProcessingStock{}, rawSubject.requestsProcessing)
+			})
+
+			it("uses processor sharing when configured", func() {
+				subject = NewReplicaEntity(envFake, ReplicasConfig{CPUModel: CPUModelProcessorSharing}, &failedSink)
+				assert.IsType(t, &requestsProcessorSharingStock{}, subject.RequestsProcessing())
+			})
+
+			it("panics on an unknown CPU model", func() {
+				assert.Panics(t, func() {
+					NewReplicaEntity(envFake, ReplicasConfig{CPUModel: "unknown"}, &failedSink)
+				})
+			})
+		})
 	})
 
 	describe("Entity interface", func() {
 		it("Name() creates sequential names", func() {
 			beforeName := subject.Name()
 			failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
-			subject = NewReplicaEntity(envFake, &failedSink)
+			subject = NewReplicaEntity(envFake, ReplicasConfig{}, &failedSink)
 			afterName := subject.Name()
 			assert.NotEqual(t, beforeName, afterName)
 		})
@@ -69,6 +93,54 @@ func testReplicaEntity(t *testing.T, describe spec.G, it spec.S) {
 		})
 	})
 
+	describe("Resize()", func() {
+		var lastEvent func() FakeEvent
+
+		it.Before(func() {
+			lastEvent = func() FakeEvent {
+				events := envFake.ThePlugin.(*FakePluginPartition).events
+				return events[len(events)-1]
+			}
+
+			envFake.TheTime = time.Unix(5, 0)
+			subject.Activate()
+			envFake.TheTime = time.Unix(60, 0)
+			subject.Resize(250)
+		})
+
+		it("changes the CPU capacity", func() {
+			assert.Equal(t, 250.0, subject.GetCPUCapacity())
+		})
+
+		it("tells the plugin about the new CPU request", func() {
+			assert.Equal(t, proto.EventType_UPDATE, lastEvent().Type)
+			pod := lastEvent().Object.(*skplug.Pod)
+			assert.Equal(t, int32(250), pod.CpuRequest)
+			assert.Equal(t, string(subject.Name()), pod.Name)
+			assert.Equal(t, time.Unix(5, 0).UnixNano(), pod.LastTransition)
+			assert.Equal(t, time.Unix(60, 0).UnixNano(), lastEvent().Time)
+		})
+
+		describe("with processor sharing", func() {
+			it.Before(func() {
+				failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
+				subject = NewReplicaEntity(envFake, ReplicasConfig{CPUModel: CPUModelProcessorSharing}, &failedSink)
+				envFake.TheHaltTime = envFake.TheTime.Add(time.Minute)
+				envFake.Movements = nil
+				request := NewRequestEntity(envFake, NewRequestsRoutingStock(envFake, NewReplicasActiveStock(envFake), nil),
+					RequestConfig{CPUTimeMillis: 1000, Timeout: 10 * time.Second})
+				assert.NoError(t, subject.RequestsProcessing().Add(request))
+
+				subject.Resize(500)
+			})
+
+			it("reschedules requests in progress", func() {
+				last := envFake.Movements[len(envFake.Movements)-1]
+				assert.Equal(t, envFake.TheTime.Add(2*time.Second), last.OccursAt())
+			})
+		})
+	})
+
 	describe("RequestsProcessing()", func() {
 		it("returns the Requests Processing stock", func() {
 			assert.Contains(t, subject.RequestsProcessing().Name(), "RequestsProcessing [")
@@ -105,6 +177,127 @@ func testReplicaEntity(t *testing.T, describe spec.G, it spec.S) {
 			it("sets Value based on RequestsProcessing.Count() * 1000", func() {
 				assert.Equal(t, int32(rawSubject.requestsProcessing.Count()*1000), stats[0].Value)
 			})
+
+			it("reports the requests which arrived since the last stat", func() {
+				assert.Equal(t, proto.MetricType_REQUEST_COUNT, stats[4].Type)
+				assert.Equal(t, int32(2), stats[4].Value)
+			})
+
+			it("reports no failures", func() {
+				last := stats[len(stats)-1]
+				assert.Equal(t, proto.MetricType_FAILURE_RATE_MILLIS, last.Type)
+				assert.Equal(t, int32(0), last.Value)
+			})
+		})
+
+		describe("after requests have finished", func() {
+			var stats []*proto.Stat
+
+			statOfType := func(metricType proto.MetricType) *proto.Stat {
+				for _, s := range stats {
+					if s.Type == metricType {
+						return s
+					}
+				}
+				return nil
+			}
+
+			it.Before(func() {
+				envFake.TheTime = time.Unix(0, 0)
+				subject.Activate()
+				started := envFake.TheTime
+				for i := 1; i <= 4; i++ {
+					request := NewRequestEntity(envFake, NewRequestsRoutingStock(envFake, NewReplicasActiveStock(envFake), nil),
+						RequestConfig{CPUTimeMillis: 200, IOTimeMillis: 200, Timeout: 1 * time.Second}).(*requestEntity)
+					request.startTime = &started
+					envFake.TheTime = started.Add(time.Duration(i*100) * time.Millisecond)
+					if i < 4 {
+						assert.NoError(t, rawSubject.requestsComplete.Add(request))
+					} else {
+						assert.NoError(t, rawSubject.requestsFailed.Add(request))
+					}
+					assert.NoError(t, rawSubject.requestsProcessing.Add(request))
+				}
+				envFake.TheTime = started.Add(2 * time.Second)
+
+				stats = subject.Stats()
+			})
+
+			it("reports the request rate since activation", func() {
+				assert.Equal(t, int32(2000), statOfType(proto.MetricType_REQUESTS_PER_SECOND_MILLIS).Value)
+			})
+
+			it("reports latency percentiles of completed requests", func() {
+				assert.Equal(t, int32(200), statOfType(proto.MetricType_LATENCY_P50_MILLIS).Value)
+				assert.Equal(t, int32(300), statOfType(proto.MetricType_LATENCY_P95_MILLIS).Value)
+				assert.Equal(t, int32(300), statOfType(proto.MetricType_LATENCY_P99_MILLIS).Value)
+			})
+
+			it("reports the failure rate", func() {
+				assert.Equal(t, int32(250), statOfType(proto.MetricType_FAILURE_RATE_MILLIS).Value)
+			})
+
+			it("starts afresh for the next stat", func() {
+				stats = subject.Stats()
+				assert.Nil(t, statOfType(proto.MetricType_LATENCY_P50_MILLIS))
+				assert.Equal(t, int32(0), statOfType(proto.MetricType_REQUEST_COUNT).Value)
+				assert.Equal(t, int32(0), statOfType(proto.MetricType_FAILURE_RATE_MILLIS).Value)
+			})
+		})
+
+		describe("concurrency and CPU usage over time", func() {
+			var stats []*proto.Stat
+
+			it.Before(func() {
+				envFake.TheTime = time.Unix(0, 0)
+				subject.Activate()
+
+				envFake.TheTime = time.Unix(2, 0)
+				request := NewRequestEntity(envFake, NewRequestsRoutingStock(envFake, NewReplicasActiveStock(envFake), nil),
+					RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 10 * time.Second})
+				assert.NoError(t, subject.RequestsProcessing().Add(request))
+
+				envFake.TheTime = time.Unix(10, 0)
+				stats = subject.Stats()
+			})
+
+			it("reports the average concurrency since the last stat", func() {
+				assert.Equal(t, proto.MetricType_CONCURRENT_REQUESTS_MILLIS, stats[0].Type)
+				assert.Equal(t, int32(800), stats[0].Value)
+			})
+
+			it("reports the average CPU usage since the last stat", func() {
+				assert.Equal(t, proto.MetricType_CPU_MILLIS, stats[1].Type)
+				assert.InDelta(t, 0.8*rawSubject.occupiedCPUCapacityMillisPerSecond, stats[1].Value, 1)
+			})
+
+			it("reports the instantaneous concurrency and CPU usage separately", func() {
+				assert.Equal(t, proto.MetricType_INSTANTANEOUS_CONCURRENT_REQUESTS_MILLIS, stats[2].Type)
+				assert.Equal(t, int32(1000), stats[2].Value)
+				assert.Equal(t, proto.MetricType_INSTANTANEOUS_CPU_MILLIS, stats[3].Type)
+				assert.Equal(t, int32(rawSubject.occupiedCPUCapacityMillisPerSecond), stats[3].Value)
+			})
+		})
+
+		describe("metric aliases", func() {
+			it.Before(func() {
+				failedSink := simulator.NewSinkStock("fake-requestsFailed", "Request")
+				subject = NewReplicaEntity(envFake, ReplicasConfig{MetricAliases: []MetricAliasConfig{
+					{Name: "concurrency", Source: proto.MetricType_CONCURRENT_REQUESTS_MILLIS, Scale: 0.001},
+				}}, &failedSink)
+				request := NewRequestEntity(envFake, NewRequestsRoutingStock(envFake, NewReplicasActiveStock(envFake), nil),
+					RequestConfig{CPUTimeMillis: 200, IOTimeMillis: 200, Timeout: 1 * time.Second})
+				assert.NoError(t, subject.RequestsProcessing().Add(request))
+			})
+
+			it("reports the configured metric by name", func() {
+				stats := subject.Stats()
+				alias := stats[len(stats)-1]
+				assert.Equal(t, proto.MetricType_CUSTOM, alias.Type)
+				assert.Equal(t, "concurrency", alias.Name)
+				assert.Equal(t, string(subject.Name()), alias.PodName)
+				assert.Equal(t, int32(1), alias.Value)
+			})
 		})
 	})
 }
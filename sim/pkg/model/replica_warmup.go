@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"math"
+	"time"
+)
+
+type WarmupCurve string
+
+const (
+	// WarmupCurveLinear eases off evenly until Duration has passed.
+	WarmupCurveLinear WarmupCurve = "linear"
+	// WarmupCurveStep stays fully cold until Duration has passed.
+	WarmupCurveStep WarmupCurve = "step"
+	// WarmupCurveExponential decays with Duration as its time constant, so never quite ends.
+	WarmupCurveExponential WarmupCurve = "exponential"
+)
+
+// WarmupConfig makes newly active replicas slower and hungrier for CPU, as with JIT compilation
+// and cold caches. A request arriving at a replica that has just become active takes InitialCPUBoost
+// times its CPU time and InitialSlowdown times its IO time. Both ease off to 1 as the replica ages.
+type WarmupConfig struct {
+	Duration        time.Duration
+	Curve           WarmupCurve // defaults to linear
+	InitialSlowdown float64
+	InitialCPUBoost float64
+}
+
+// replicaWarmup is shared by a replica and its processing stock.
+type replicaWarmup struct {
+	config      WarmupConfig
+	activatedAt time.Time
+	// cpuCostFactor scales the CPU time of every request, for revisions which are cheaper or dearer to run.
+	cpuCostFactor float64
+}
+
+// coldness is 1 when the replica has just become active and falls to 0 as it warms up.
+func (rw *replicaWarmup) coldness(at time.Time) float64 {
+	if rw == nil || rw.config.Duration <= 0 || rw.activatedAt.IsZero() {
+		return 0
+	}
+
+	age := at.Sub(rw.activatedAt)
+	if age < 0 {
+		age = 0
+	}
+	x := float64(age) / float64(rw.config.Duration)
+
+	switch rw.config.Curve {
+	case WarmupCurveStep:
+		if x < 1 {
+			return 1
+		}
+		return 0
+	case WarmupCurveExponential:
+		return math.Exp(-x)
+	default:
+		return math.Max(0, 1-x)
+	}
+}
+
+// scale gives the CPU time and IO time, in milliseconds, of a request arriving at the given time.
+func (rw *replicaWarmup) scale(config RequestConfig, at time.Time) (cpuTimeMillis, ioTimeMillis float64) {
+	cpuTimeMillis = float64(config.CPUTimeMillis)
+	ioTimeMillis = float64(config.IOTimeMillis)
+	if rw != nil && rw.cpuCostFactor > 0 {
+		cpuTimeMillis *= rw.cpuCostFactor
+	}
+
+	coldness := rw.coldness(at)
+	if coldness == 0 {
+		return cpuTimeMillis, ioTimeMillis
+	}
+
+	return cpuTimeMillis * warmupFactor(rw.config.InitialCPUBoost, coldness),
+		ioTimeMillis * warmupFactor(rw.config.InitialSlowdown, coldness)
+}
+
+func warmupFactor(initial, coldness float64) float64 {
+	if initial <= 0 {
+		return 1
+	}
+	return 1 + (initial-1)*coldness
+}
+
+// warmable processing stocks scale the requests they are given while their replica warms up.
+type warmable interface {
+	warmUpWith(warmup *replicaWarmup)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaWarmup(t *testing.T) {
+	spec.Run(t, "Replica warm-up", testReplicaWarmup, spec.Report(report.Terminal{}))
+}
+
+func testReplicaWarmup(t *testing.T, describe spec.G, it spec.S) {
+	var subject *replicaWarmup
+	var activatedAt = time.Unix(0, 0)
+	var requestConfig = RequestConfig{CPUTimeMillis: 100, IOTimeMillis: 200}
+
+	it.Before(func() {
+		subject = &replicaWarmup{
+			config:      WarmupConfig{Duration: 10 * time.Second, InitialCPUBoost: 2, InitialSlowdown: 3},
+			activatedAt: activatedAt,
+		}
+	})
+
+	describe("coldness()", func() {
+		it("eases off linearly by default", func() {
+			assert.Equal(t, 1.0, subject.coldness(activatedAt))
+			assert.Equal(t, 0.5, subject.coldness(activatedAt.Add(5*time.Second)))
+			assert.Equal(t, 0.0, subject.coldness(activatedAt.Add(20*time.Second)))
+		})
+
+		it("stays cold until the duration has passed with a step curve", func() {
+			subject.config.Curve = WarmupCurveStep
+			assert.Equal(t, 1.0, subject.coldness(activatedAt.Add(9*time.Second)))
+			assert.Equal(t, 0.0, subject.coldness(activatedAt.Add(10*time.Second)))
+		})
+
+		it("uses the duration as a time constant with an exponential curve", func() {
+			subject.config.Curve = WarmupCurveExponential
+			assert.InDelta(t, math.Exp(-1), subject.coldness(activatedAt.Add(10*time.Second)), 0.0001)
+		})
+
+		it("is warm before the replica is activated", func() {
+			subject.activatedAt = time.Time{}
+			assert.Equal(t, 0.0, subject.coldness(activatedAt))
+		})
+
+		it("is warm without a warm-up", func() {
+			var none *replicaWarmup
+			assert.Equal(t, 0.0, none.coldness(activatedAt))
+		})
+	})
+
+	describe("scale()", func() {
+		it("scales CPU time by the CPU boost and IO time by the slowdown", func() {
+			cpuTimeMillis, ioTimeMillis := subject.scale(requestConfig, activatedAt)
+			assert.Equal(t, 200.0, cpuTimeMillis)
+			assert.Equal(t, 600.0, ioTimeMillis)
+		})
+
+		it("eases off along the curve", func() {
+			cpuTimeMillis, ioTimeMillis := subject.scale(requestConfig, activatedAt.Add(5*time.Second))
+			assert.Equal(t, 150.0, cpuTimeMillis)
+			assert.Equal(t, 400.0, ioTimeMillis)
+		})
+
+		it("scales CPU time by the revision's CPU cost once warm", func() {
+			subject.cpuCostFactor = 1.5
+			cpuTimeMillis, _ := subject.scale(requestConfig, activatedAt.Add(time.Minute))
+			assert.Equal(t, 150.0, cpuTimeMillis)
+		})
+
+		it("leaves a factor which isn't set alone", func() {
+			subject.config.InitialSlowdown = 0
+			_, ioTimeMillis := subject.scale(requestConfig, activatedAt)
+			assert.Equal(t, 200.0, ioTimeMillis)
+		})
+	})
+}
@@ -17,7 +17,6 @@ package model
 
 import (
 	"skenario/pkg/simulator"
-	"time"
 )
 
 type ReplicasActiveStock interface {
@@ -61,7 +60,7 @@ func (ras *replicasActiveStock) Add(entity simulator.Entity) error {
 	replica.Activate()
 	ras.env.AddToSchedule(simulator.NewMovement(
 		"metrics_tick",
-		ras.env.CurrentMovementTime().Add(5*time.Second),
+		ras.env.CurrentMovementTime().Add(replica.MetricsTicktock().FirstScrapeAfter()),
 		replica.MetricsTicktock(),
 		replica.MetricsTicktock(),
 		&entity))
@@ -21,16 +21,40 @@ import (
 	"skenario/pkg/simulator"
 )
 
+type CPUModel string
+
+const (
+	// CPUModelSakasegawa fixes each request's completion time when it arrives at a replica.
+	CPUModelSakasegawa CPUModel = "sakasegawa"
+	// CPUModelProcessorSharing shares CPU between in-flight requests, recalculating completion times as they come and go.
+	CPUModelProcessorSharing CPUModel = "processor_sharing"
+)
+
 type ReplicasConfig struct {
 	LaunchDelay    time.Duration
 	TerminateDelay time.Duration
 	MaxRPS         int64
+	CPUModel       CPUModel
+	MetricAliases  []MetricAliasConfig
+	Metrics        MetricsConfig
+	Warmup         WarmupConfig
+	// MemoryRequestMB is only used to work out the cost of replicas.
+	MemoryRequestMB float64
 }
 
 type RequestConfig struct {
 	CPUTimeMillis int
 	IOTimeMillis  int
 	Timeout       time.Duration
+	Calls         []ServiceCall
+	Class         string // labels the request, e.g. the endpoint a replayed request was made to
+}
+
+// ServiceCall is a synchronous call to a downstream service, made while a request is processed.
+type ServiceCall struct {
+	Service     TrafficSource
+	FanOut      int
+	Probability float64
 }
 
 type ReplicasDesiredStock interface {
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"skenario/pkg/simulator"
+)
+
+// ReplicasResizingStock holds replicas whose CPU request is being changed in place. Each is
+// resized when its "resize_replica" movement occurs, unless it has stopped being active.
+type ReplicasResizingStock interface {
+	simulator.ThroughStock
+	Resize(replica simulator.Entity, cpuRequest float64, after time.Duration)
+	IsResizing(replica simulator.Entity) bool
+}
+
+type replicasResizingStock struct {
+	env     simulator.Environment
+	active  ReplicasActiveStock
+	pending map[simulator.Entity]float64
+	order   []simulator.Entity
+}
+
+func (rrs *replicasResizingStock) Name() simulator.StockName {
+	return "ReplicasResizing"
+}
+
+func (rrs *replicasResizingStock) KindStocked() simulator.EntityKind {
+	return "Replica"
+}
+
+func (rrs *replicasResizingStock) Count() uint64 {
+	return uint64(len(rrs.order))
+}
+
+func (rrs *replicasResizingStock) EntitiesInStock() []*simulator.Entity {
+	entities := make([]*simulator.Entity, 0, len(rrs.order))
+	for i := range rrs.order {
+		entities = append(entities, &rrs.order[i])
+	}
+	return entities
+}
+
+func (rrs *replicasResizingStock) Remove(entity *simulator.Entity) simulator.Entity {
+	if entity == nil {
+		return nil
+	}
+	if _, ok := rrs.pending[*entity]; !ok {
+		return nil
+	}
+	return *entity
+}
+
+func (rrs *replicasResizingStock) Add(entity simulator.Entity) error {
+	cpuRequest, ok := rrs.pending[entity]
+	if !ok {
+		return fmt.Errorf("'%+v' is not waiting to be resized", entity)
+	}
+	delete(rrs.pending, entity)
+	for i, e := range rrs.order {
+		if e == entity {
+			rrs.order = append(rrs.order[:i], rrs.order[i+1:]...)
+			break
+		}
+	}
+
+	for _, e := range rrs.active.EntitiesInStock() {
+		if *e == entity {
+			entity.(Replica).Resize(cpuRequest)
+			break
+		}
+	}
+	return nil
+}
+
+func (rrs *replicasResizingStock) Resize(replica simulator.Entity, cpuRequest float64, after time.Duration) {
+	if _, ok := rrs.pending[replica]; !ok {
+		rrs.order = append(rrs.order, replica)
+	}
+	rrs.pending[replica] = cpuRequest
+
+	if after <= 0 {
+		after = 1 * time.Nanosecond
+	}
+	rrs.env.AddToSchedule(simulator.NewMovement(
+		"resize_replica",
+		rrs.env.CurrentMovementTime().Add(after),
+		rrs,
+		rrs,
+		&replica,
+	))
+}
+
+func (rrs *replicasResizingStock) IsResizing(replica simulator.Entity) bool {
+	_, ok := rrs.pending[replica]
+	return ok
+}
+
+func NewReplicasResizingStock(env simulator.Environment, active ReplicasActiveStock) ReplicasResizingStock {
+	return &replicasResizingStock{
+		env:     env,
+		active:  active,
+		pending: make(map[simulator.Entity]float64),
+		order:   make([]simulator.Entity, 0),
+	}
+}
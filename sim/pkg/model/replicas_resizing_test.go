@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/simulator"
+)
+
+func TestReplicasResizing(t *testing.T) {
+	spec.Run(t, "ReplicasResizing stock", testReplicasResizing, spec.Report(report.Terminal{}))
+}
+
+func testReplicasResizing(t *testing.T, describe spec.G, it spec.S) {
+	var subject ReplicasResizingStock
+	var envFake *FakeEnvironment
+	var active ReplicasActiveStock
+	var replica *FakeReplica
+
+	it.Before(func() {
+		envFake = NewFakeEnvironment()
+		envFake.TheTime = time.Unix(0, 0)
+		active = NewReplicasActiveStock(envFake)
+		replica = NewFakeReplica()
+		err := active.Add(replica)
+		assert.NoError(t, err)
+		envFake.Movements = nil
+
+		subject = NewReplicasResizingStock(envFake, active)
+	})
+
+	describe("Resize()", func() {
+		it.Before(func() {
+			subject.Resize(replica, 300, 5*time.Second)
+		})
+
+		it("holds the replica until it is resized", func() {
+			assert.True(t, subject.IsResizing(replica))
+			assert.Equal(t, uint64(1), subject.Count())
+		})
+
+		it("schedules the resize after the actuation delay", func() {
+			assert.Equal(t, simulator.MovementKind("resize_replica"), envFake.Movements[0].Kind())
+			assert.Equal(t, time.Unix(5, 0), envFake.Movements[0].OccursAt())
+			assert.Equal(t, subject, envFake.Movements[0].From())
+			assert.Equal(t, subject, envFake.Movements[0].To())
+		})
+	})
+
+	describe("the resize_replica movement", func() {
+		var entity simulator.Entity = nil
+
+		it.Before(func() {
+			entity = replica
+			subject.Resize(replica, 300, 5*time.Second)
+		})
+
+		it("resizes an active replica", func() {
+			err := subject.Add(subject.Remove(&entity))
+			assert.NoError(t, err)
+			assert.Equal(t, 300.0, replica.ResizedTo)
+			assert.False(t, subject.IsResizing(replica))
+			assert.Zero(t, subject.Count())
+		})
+
+		it("leaves a replica which is no longer active", func() {
+			active.Remove(&entity)
+			err := subject.Add(subject.Remove(&entity))
+			assert.NoError(t, err)
+			assert.Zero(t, replica.ResizedTo)
+		})
+
+		it("ignores replicas it isn't resizing", func() {
+			other := simulator.Entity(NewFakeReplica())
+			assert.Nil(t, subject.Remove(&other))
+			assert.Error(t, subject.Add(other))
+		})
+	})
+}
@@ -24,9 +24,18 @@ type ReplicaSource interface {
 }
 
 type replicaSource struct {
-	env           simulator.Environment
-	maxReplicaRPS int64
-	failedSink    simulator.SinkStock
+	env        simulator.Environment
+	config     ReplicasConfig
+	failedSink simulator.SinkStock
+	// cpuRequest is set by the vertical autoscaler for replicas created from now on.
+	cpuRequest float64
+	// revision and cpuCostFactor are set by rollouts for replicas created from now on.
+	revision      int
+	cpuCostFactor float64
+	// billings and revisions outlive the replicas they describe, so that terminated replicas can be dropped.
+	billings      []*replicaBilling
+	revisions     map[simulator.EntityName]int
+	statsObserver statsObserver
 }
 
 func (rs *replicaSource) Name() simulator.StockName {
@@ -49,13 +58,31 @@ func (rs *replicaSource) Remove(entity *simulator.Entity) simulator.Entity {
 	if entity != nil {
 		return *entity
 	}
-	return NewReplicaEntity(rs.env, &rs.failedSink)
+	return rs.newReplica()
 }
 
-func NewReplicaSource(env simulator.Environment, maxReplicaRPS int64) ReplicaSource {
+func (rs *replicaSource) newReplica() ReplicaEntity {
+	replica := NewReplicaEntity(rs.env, rs.config, &rs.failedSink)
+	if rs.cpuRequest > 0 {
+		replica.(*replicaEntity).totalCPUCapacityMillisPerSecond = rs.cpuRequest
+	}
+
+	re := replica.(*replicaEntity)
+	re.tickTock.(statsObservable).observeStats(rs.statsObserver)
+	re.revision = rs.revision
+	re.warmup.cpuCostFactor = rs.cpuCostFactor
+	re.billing.start(rs.env.CurrentMovementTime(), re.totalCPUCapacityMillisPerSecond, rs.config.MemoryRequestMB)
+	rs.billings = append(rs.billings, re.billing)
+	rs.revisions[re.Name()] = re.revision
+	return replica
+}
+
+func NewReplicaSource(env simulator.Environment, config ReplicasConfig) ReplicaSource {
 	return &replicaSource{
-		env:           env,
-		maxReplicaRPS: maxReplicaRPS,
-		failedSink:    simulator.NewSinkStock("RequestsFailed", "Request"),
+		env:        env,
+		config:     config,
+		failedSink: NewRequestsSinkStock("RequestsFailed", false),
+		revision:   1,
+		revisions:  make(map[simulator.EntityName]int),
 	}
 }
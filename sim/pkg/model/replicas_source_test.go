@@ -36,7 +36,7 @@ func testReplicasSource(t *testing.T, describe spec.G, it spec.S) {
 	it.Before(func() {
 		envFake = NewFakeEnvironment()
 
-		subject = NewReplicaSource(envFake, 100)
+		subject = NewReplicaSource(envFake, ReplicasConfig{MaxRPS: 100})
 		rawSubject = subject.(*replicaSource)
 	})
 
@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package model
+
+import (
+	"fmt"
+
+	"skenario/pkg/simulator"
+)
+
+// ReplicasTerminatedStock is where replicas end up once they have finished terminating. It only
+// counts them: their billing and revision outlive them in the ReplicaSource, so the replicas
+// themselves, with their requests and metrics, can be dropped.
+type ReplicasTerminatedStock interface {
+	simulator.SinkStock
+}
+
+type replicasTerminatedStock struct {
+	count uint64
+}
+
+func (rts *replicasTerminatedStock) Name() simulator.StockName {
+	return "ReplicasTerminated"
+}
+
+func (rts *replicasTerminatedStock) KindStocked() simulator.EntityKind {
+	return "Replica"
+}
+
+func (rts *replicasTerminatedStock) Count() uint64 {
+	return rts.count
+}
+
+func (rts *replicasTerminatedStock) EntitiesInStock() []*simulator.Entity {
+	return []*simulator.Entity{}
+}
+
+func (rts *replicasTerminatedStock) Add(entity simulator.Entity) error {
+	if entity == nil {
+		return fmt.Errorf("could not add Entity, as it was nil")
+	}
+	if entity.Kind() != rts.KindStocked() {
+		return fmt.Errorf(
+			"stock '%s' could not stock entity '%s'; stock accepts '%s' but kind is '%s'",
+			rts.Name(),
+			entity.Name(),
+			rts.KindStocked(),
+			entity.Kind(),
+		)
+	}
+
+	rts.count++
+	return nil
+}
+
+func NewReplicasTerminatedStock() ReplicasTerminatedStock {
+	return &replicasTerminatedStock{}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package model
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/simulator"
+)
+
+func TestReplicasTerminated(t *testing.T) {
+	spec.Run(t, "Replicas terminated stock", testReplicasTerminated, spec.Report(report.Terminal{}))
+}
+
+func testReplicasTerminated(t *testing.T, describe spec.G, it spec.S) {
+	var subject ReplicasTerminatedStock
+	var envFake *FakeEnvironment
+
+	it.Before(func() {
+		envFake = NewFakeEnvironment()
+		subject = NewReplicasTerminatedStock()
+	})
+
+	describe("NewReplicasTerminatedStock()", func() {
+		it("is named ReplicasTerminated", func() {
+			assert.Equal(t, simulator.StockName("ReplicasTerminated"), subject.Name())
+		})
+
+		it("stocks Replicas", func() {
+			assert.Equal(t, simulator.EntityKind("Replica"), subject.KindStocked())
+		})
+	})
+
+	describe("Add()", func() {
+		it.Before(func() {
+			err := subject.Add(NewReplicaEntity(envFake, ReplicasConfig{}, nil))
+			assert.NoError(t, err)
+			err = subject.Add(NewReplicaEntity(envFake, ReplicasConfig{}, nil))
+			assert.NoError(t, err)
+		})
+
+		it("counts the replicas", func() {
+			assert.Equal(t, uint64(2), subject.Count())
+		})
+
+		it("does not keep the replicas", func() {
+			assert.Empty(t, subject.EntitiesInStock())
+		})
+
+		it("rejects entities of the wrong kind", func() {
+			err := subject.Add(simulator.NewEntity("not a replica", "Request"))
+			assert.Error(t, err)
+		})
+	})
+}
@@ -63,6 +63,9 @@ func (rts *replicasTerminatingStock) Add(entity simulator.Entity) error {
 	drainTime := time.Second * time.Duration(count)
 
 	terminateAt := rts.env.CurrentMovementTime().Add(drainTime).Add(rts.config.TerminateDelay)
+	if re, ok := entity.(*replicaEntity); ok {
+		re.billing.stop(terminateAt)
+	}
 	rts.env.AddToSchedule(simulator.NewMovement(
 		"finish_terminating",
 		terminateAt,
@@ -24,6 +24,11 @@ import (
 type Request interface {
 }
 
+// Requestor is told when a request it issued has either completed or failed.
+type Requestor interface {
+	RequestFinished(request RequestEntity, successful bool)
+}
+
 type RequestEntity interface {
 	simulator.Entity
 	Request
@@ -36,6 +41,8 @@ type requestEntity struct {
 	routingStock                         RequestsRoutingStock
 	utilizationForRequestMillisPerSecond *float64
 	startTime                            *time.Time
+	requestor                            Requestor
+	calls                                *callGroup
 }
 
 var reqNumber int
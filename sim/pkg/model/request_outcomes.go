@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+	"skenario/pkg/simulator"
+)
+
+// requestOutcomes tallies the requests a replica has finished since it last reported stats.
+type requestOutcomes struct {
+	completed int32
+	failed    int32
+	latencies []time.Duration
+}
+
+func (ro *requestOutcomes) record(latency time.Duration, successful bool) {
+	if successful {
+		ro.completed++
+		ro.latencies = append(ro.latencies, latency)
+	} else {
+		ro.failed++
+	}
+}
+
+// percentile uses the nearest-rank method over completed requests.
+func (ro *requestOutcomes) percentile(p float64) time.Duration {
+	if len(ro.latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(ro.latencies))
+	copy(sorted, ro.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func (ro *requestOutcomes) failureRateMillis() int32 {
+	finished := ro.completed + ro.failed
+	if finished == 0 {
+		return 0
+	}
+	return ro.failed * 1000 / finished
+}
+
+func (ro *requestOutcomes) stats(atTime time.Time, podName string) []*proto.Stat {
+	stats := make([]*proto.Stat, 0)
+	if len(ro.latencies) > 0 {
+		for _, p := range []struct {
+			percentile float64
+			metricType proto.MetricType
+		}{
+			{50, proto.MetricType_LATENCY_P50_MILLIS},
+			{95, proto.MetricType_LATENCY_P95_MILLIS},
+			{99, proto.MetricType_LATENCY_P99_MILLIS},
+		} {
+			stats = append(stats, &proto.Stat{
+				Time:    atTime.UnixNano(),
+				PodName: podName,
+				Type:    p.metricType,
+				Value:   int32(ro.percentile(p.percentile) / time.Millisecond),
+			})
+		}
+	}
+	stats = append(stats, &proto.Stat{
+		Time:    atTime.UnixNano(),
+		PodName: podName,
+		Type:    proto.MetricType_FAILURE_RATE_MILLIS,
+		Value:   ro.failureRateMillis(),
+	})
+	return stats
+}
+
+func (ro *requestOutcomes) reset() {
+	ro.completed = 0
+	ro.failed = 0
+	ro.latencies = ro.latencies[:0]
+}
+
+// requestsOutcomeStock records each request finishing on a replica before passing it on to the
+// sink it would otherwise have gone to.
+type requestsOutcomeStock struct {
+	env        simulator.Environment
+	delegate   *simulator.SinkStock
+	successful bool
+	outcomes   *requestOutcomes
+}
+
+func (ros *requestsOutcomeStock) Name() simulator.StockName {
+	return (*ros.delegate).Name()
+}
+
+func (ros *requestsOutcomeStock) KindStocked() simulator.EntityKind {
+	return (*ros.delegate).KindStocked()
+}
+
+func (ros *requestsOutcomeStock) Count() uint64 {
+	return (*ros.delegate).Count()
+}
+
+func (ros *requestsOutcomeStock) EntitiesInStock() []*simulator.Entity {
+	return (*ros.delegate).EntitiesInStock()
+}
+
+func (ros *requestsOutcomeStock) Add(entity simulator.Entity) error {
+	err := (*ros.delegate).Add(entity)
+	if err != nil {
+		return err
+	}
+
+	var latency time.Duration
+	request, ok := entity.(*requestEntity)
+	if ok && request.startTime != nil {
+		latency = ros.env.CurrentMovementTime().Sub(*request.startTime)
+	}
+	ros.outcomes.record(latency, ros.successful)
+
+	return nil
+}
+
+func newRequestsOutcomeStock(env simulator.Environment, delegate *simulator.SinkStock, successful bool, outcomes *requestOutcomes) simulator.SinkStock {
+	return &requestsOutcomeStock{
+		env:        env,
+		delegate:   delegate,
+		successful: successful,
+		outcomes:   outcomes,
+	}
+}
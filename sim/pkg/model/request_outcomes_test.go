@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+	"skenario/pkg/simulator"
+)
+
+func TestRequestOutcomes(t *testing.T) {
+	spec.Run(t, "Request outcomes", testRequestOutcomes, spec.Report(report.Terminal{}))
+}
+
+func testRequestOutcomes(t *testing.T, describe spec.G, it spec.S) {
+	var subject *requestOutcomes
+
+	it.Before(func() {
+		subject = &requestOutcomes{}
+	})
+
+	describe("percentile()", func() {
+		it("is zero when nothing has completed", func() {
+			assert.Equal(t, time.Duration(0), subject.percentile(50))
+		})
+
+		it("uses the nearest rank", func() {
+			for i := 10; i >= 1; i-- {
+				subject.record(time.Duration(i)*time.Second, true)
+			}
+			assert.Equal(t, 5*time.Second, subject.percentile(50))
+			assert.Equal(t, 10*time.Second, subject.percentile(95))
+			assert.Equal(t, 1*time.Second, subject.percentile(0))
+		})
+	})
+
+	describe("failureRateMillis()", func() {
+		it("is zero when nothing has finished", func() {
+			assert.Equal(t, int32(0), subject.failureRateMillis())
+		})
+
+		it("gives failures per thousand finished requests", func() {
+			subject.record(time.Second, true)
+			subject.record(0, false)
+			assert.Equal(t, int32(500), subject.failureRateMillis())
+		})
+	})
+
+	describe("requestsOutcomeStock", func() {
+		var envFake *FakeEnvironment
+		var delegate simulator.SinkStock
+		var stock simulator.SinkStock
+
+		it.Before(func() {
+			envFake = NewFakeEnvironment()
+			envFake.TheTime = time.Unix(0, 0).Add(300 * time.Millisecond)
+			delegate = simulator.NewSinkStock("RequestsComplete", "Request")
+			stock = newRequestsOutcomeStock(envFake, &delegate, true, subject)
+
+			started := time.Unix(0, 0)
+			request := NewRequestEntity(envFake, nil, RequestConfig{}).(*requestEntity)
+			request.startTime = &started
+			assert.NoError(t, stock.Add(request))
+		})
+
+		it("passes requests on to its delegate", func() {
+			assert.Equal(t, delegate.Name(), stock.Name())
+			assert.Equal(t, uint64(1), delegate.Count())
+		})
+
+		it("records the request's latency", func() {
+			assert.Equal(t, int32(1), subject.completed)
+			assert.Equal(t, []time.Duration{300 * time.Millisecond}, subject.latencies)
+		})
+	})
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"skenario/pkg/simulator"
+)
+
+// RequestsAwaitingCallsStock holds requests that have finished their own processing, but are still
+// waiting on the calls they made to downstream services.
+type RequestsAwaitingCallsStock interface {
+	simulator.ThroughStock
+	MakeCalls(request RequestEntity)
+}
+
+// callGroup tracks the downstream requests made on behalf of a single upstream request.
+type callGroup struct {
+	stock     *requestsAwaitingCallsStock
+	request   simulator.Entity
+	startedAt time.Time
+	pending   int
+	failed    bool
+	awaiting  bool
+	resolved  bool
+	timeout   simulator.Movement
+}
+
+func (cg *callGroup) RequestFinished(request RequestEntity, successful bool) {
+	cg.pending--
+	if !successful {
+		cg.failed = true
+	}
+
+	if cg.awaiting {
+		cg.stock.resolve(cg)
+	}
+}
+
+type requestsAwaitingCallsStock struct {
+	env              simulator.Environment
+	delegate         simulator.ThroughStock
+	replicaNumber    int
+	requestsComplete simulator.SinkStock
+	requestsFailed   *simulator.SinkStock
+	observer         usageObserver
+}
+
+func (racs *requestsAwaitingCallsStock) Name() simulator.StockName {
+	name := fmt.Sprintf("%s [%d]", racs.delegate.Name(), racs.replicaNumber)
+	return simulator.StockName(name)
+}
+
+func (racs *requestsAwaitingCallsStock) KindStocked() simulator.EntityKind {
+	return racs.delegate.KindStocked()
+}
+
+func (racs *requestsAwaitingCallsStock) Count() uint64 {
+	return racs.delegate.Count()
+}
+
+func (racs *requestsAwaitingCallsStock) EntitiesInStock() []*simulator.Entity {
+	return racs.delegate.EntitiesInStock()
+}
+
+func (racs *requestsAwaitingCallsStock) Remove(entity *simulator.Entity) simulator.Entity {
+	removed := racs.delegate.Remove(entity)
+	if removed != nil {
+		removed.(*requestEntity).calls.resolved = true
+		racs.observer.changed(racs.env.CurrentMovementTime())
+	}
+	return removed
+}
+
+func (racs *requestsAwaitingCallsStock) Add(entity simulator.Entity) error {
+	req, ok := entity.(*requestEntity)
+	if !ok || req.calls == nil {
+		return fmt.Errorf("requests awaiting calls stock only supports requests that made calls. got %T", entity)
+	}
+
+	err := racs.delegate.Add(entity)
+	if err != nil {
+		return err
+	}
+	racs.observer.changed(racs.env.CurrentMovementTime())
+
+	cg := req.calls
+	cg.awaiting = true
+	racs.resolve(cg)
+	if cg.resolved {
+		return nil
+	}
+
+	timesOutAt := cg.startedAt.Add(req.requestConfig.Timeout)
+	if !timesOutAt.After(racs.env.CurrentMovementTime()) {
+		racs.finish(cg, "request_failed", *racs.requestsFailed)
+		return nil
+	}
+
+	cg.timeout = simulator.NewMovement("request_failed", timesOutAt, racs, *racs.requestsFailed, &cg.request)
+	racs.env.AddToSchedule(cg.timeout)
+
+	return nil
+}
+
+// MakeCalls sends the request's downstream calls, according to their fan out and probability.
+// Requests which made calls should be moved to this stock once their own processing is done.
+func (racs *requestsAwaitingCallsStock) MakeCalls(request RequestEntity) {
+	req := request.(*requestEntity)
+
+	cg := &callGroup{
+		stock:     racs,
+		request:   request,
+		startedAt: racs.env.CurrentMovementTime(),
+	}
+
+	for _, call := range req.requestConfig.Calls {
+		if rand.Float64() >= call.Probability {
+			continue
+		}
+
+		fanOut := call.FanOut
+		if fanOut < 1 {
+			fanOut = 1
+		}
+		for i := 0; i < fanOut; i++ {
+			cg.pending++
+			call.Service.Send(cg)
+		}
+	}
+
+	if cg.pending > 0 {
+		req.calls = cg
+	}
+}
+
+func (racs *requestsAwaitingCallsStock) observeUsage(observer usageObserver) {
+	racs.observer = observer
+}
+
+func (racs *requestsAwaitingCallsStock) resolve(cg *callGroup) {
+	if cg.resolved {
+		return
+	}
+
+	if cg.failed {
+		racs.finish(cg, "request_failed", *racs.requestsFailed)
+	} else if cg.pending == 0 {
+		racs.finish(cg, "complete_request", racs.requestsComplete)
+	}
+}
+
+func (racs *requestsAwaitingCallsStock) finish(cg *callGroup, kind simulator.MovementKind, to simulator.SinkStock) {
+	cg.resolved = true
+	if cg.timeout != nil {
+		racs.env.RemoveFromSchedule(cg.timeout)
+	}
+
+	racs.env.AddToSchedule(simulator.NewMovement(
+		kind,
+		racs.env.CurrentMovementTime().Add(1*time.Nanosecond),
+		racs,
+		to,
+		&cg.request,
+	))
+}
+
+func NewRequestsAwaitingCallsStock(env simulator.Environment, replicaNumber int, requestsComplete simulator.SinkStock, requestsFailed *simulator.SinkStock) RequestsAwaitingCallsStock {
+	return &requestsAwaitingCallsStock{
+		env:              env,
+		delegate:         simulator.NewArrayThroughStock("RequestsAwaitingCalls", "Request"),
+		replicaNumber:    replicaNumber,
+		requestsComplete: requestsComplete,
+		requestsFailed:   requestsFailed,
+	}
+}
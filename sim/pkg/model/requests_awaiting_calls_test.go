@@ -0,0 +1,185 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/simulator"
+)
+
+func TestRequestsAwaitingCalls(t *testing.T) {
+	spec.Run(t, "RequestsAwaitingCalls stock", testRequestsAwaitingCalls, spec.Report(report.Terminal{}))
+}
+
+func testRequestsAwaitingCalls(t *testing.T, describe spec.G, it spec.S) {
+	var subject RequestsAwaitingCallsStock
+	var envFake *FakeEnvironment
+	var downstream TrafficSource
+	var downstreamComplete, downstreamFailed RequestsSinkStock
+	var request simulator.Entity
+	var upstreamConfig RequestConfig
+	var startAt time.Time
+
+	downstreamRequests := func() []simulator.Entity {
+		requests := make([]simulator.Entity, 0)
+		for _, mv := range envFake.Movements {
+			if mv.Kind() == "arrive_at_routing_stock" {
+				requests = append(requests, *mv.WhatToMove())
+			}
+		}
+		return requests
+	}
+
+	it.Before(func() {
+		envFake = NewFakeEnvironment()
+		startAt = envFake.TheTime
+		failedSink := simulator.NewSinkStock("RequestsFailed", "Request")
+		subject = NewRequestsAwaitingCallsStock(envFake, 7, simulator.NewSinkStock("RequestsComplete", "Request"), &failedSink)
+
+		routingStock := NewRequestsRoutingStock(envFake, NewReplicasActiveStock(envFake), simulator.NewSinkStock("RequestsFailed", "Request"))
+		downstream = NewTrafficSource(envFake, routingStock, RequestConfig{CPUTimeMillis: 100, IOTimeMillis: 100, Timeout: time.Second})
+		downstreamComplete = NewRequestsSinkStock("DownstreamComplete", true)
+		downstreamFailed = NewRequestsSinkStock("DownstreamFailed", false)
+
+		upstreamConfig = RequestConfig{
+			CPUTimeMillis: 100,
+			IOTimeMillis:  100,
+			Timeout:       3 * time.Second,
+			Calls: []ServiceCall{
+				{Service: downstream, FanOut: 2, Probability: 1},
+				{Service: downstream, FanOut: 1, Probability: 0},
+			},
+		}
+		request = NewRequestEntity(envFake, routingStock, upstreamConfig)
+	})
+
+	describe("Name()", func() {
+		it("includes the replica's number", func() {
+			assert.Equal(t, simulator.StockName("RequestsAwaitingCalls [7]"), subject.Name())
+		})
+	})
+
+	describe("MakeCalls()", func() {
+		it.Before(func() {
+			subject.MakeCalls(request)
+		})
+
+		it("sends fan out requests for each call that is made", func() {
+			assert.Len(t, downstreamRequests(), 2)
+		})
+
+		it("sends the requests on behalf of the upstream request", func() {
+			for _, r := range downstreamRequests() {
+				assert.Equal(t, request.(*requestEntity).calls, r.(*requestEntity).requestor)
+			}
+		})
+
+		it("tracks the calls awaited", func() {
+			assert.Equal(t, 2, request.(*requestEntity).calls.pending)
+		})
+
+		describe("when no calls are made", func() {
+			it("does not track any calls", func() {
+				quiet := NewRequestEntity(envFake, nil, RequestConfig{Calls: []ServiceCall{{Service: downstream, Probability: 0}}})
+				subject.MakeCalls(quiet)
+				assert.Nil(t, quiet.(*requestEntity).calls)
+			})
+		})
+	})
+
+	describe("Add()", func() {
+		describe("requests that made no calls", func() {
+			it("returns an error", func() {
+				assert.Error(t, subject.Add(request))
+			})
+		})
+
+		describe("while calls are outstanding", func() {
+			it.Before(func() {
+				subject.MakeCalls(request)
+				envFake.TheTime = startAt.Add(500 * time.Millisecond)
+				assert.NoError(t, subject.Add(request))
+			})
+
+			it("schedules the request to time out", func() {
+				last := envFake.Movements[len(envFake.Movements)-1]
+				assert.Equal(t, simulator.MovementKind("request_failed"), last.Kind())
+				assert.Equal(t, startAt.Add(3*time.Second), last.OccursAt())
+			})
+
+			describe("once every call has succeeded", func() {
+				it.Before(func() {
+					for _, r := range downstreamRequests() {
+						assert.NoError(t, downstreamComplete.Add(r))
+					}
+				})
+
+				it("cancels the timeout", func() {
+					for _, mv := range envFake.Movements {
+						assert.False(t, mv.Kind() == "request_failed" && mv.OccursAt().Equal(startAt.Add(3*time.Second)))
+					}
+				})
+
+				it("schedules the request to complete", func() {
+					last := envFake.Movements[len(envFake.Movements)-1]
+					assert.Equal(t, simulator.MovementKind("complete_request"), last.Kind())
+					assert.Equal(t, simulator.StockName("RequestsComplete"), last.To().Name())
+					assert.Equal(t, envFake.TheTime.Add(1*time.Nanosecond), last.OccursAt())
+				})
+			})
+
+			describe("when a call fails", func() {
+				it.Before(func() {
+					assert.NoError(t, downstreamFailed.Add(downstreamRequests()[0]))
+				})
+
+				it("schedules the request to fail straight away", func() {
+					last := envFake.Movements[len(envFake.Movements)-1]
+					assert.Equal(t, simulator.MovementKind("request_failed"), last.Kind())
+					assert.Equal(t, envFake.TheTime.Add(1*time.Nanosecond), last.OccursAt())
+				})
+
+				it("ignores calls that finish afterwards", func() {
+					count := len(envFake.Movements)
+					assert.NoError(t, downstreamComplete.Add(downstreamRequests()[1]))
+					assert.Len(t, envFake.Movements, count)
+				})
+			})
+		})
+
+		describe("when the calls finished first", func() {
+			it.Before(func() {
+				subject.MakeCalls(request)
+				for _, r := range downstreamRequests() {
+					assert.NoError(t, downstreamComplete.Add(r))
+				}
+				assert.NoError(t, subject.Add(request))
+			})
+
+			it("schedules the request to complete straight away", func() {
+				last := envFake.Movements[len(envFake.Movements)-1]
+				assert.Equal(t, simulator.MovementKind("complete_request"), last.Kind())
+				assert.Equal(t, envFake.TheTime.Add(1*time.Nanosecond), last.OccursAt())
+			})
+		})
+	})
+}
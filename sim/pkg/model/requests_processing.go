@@ -27,6 +27,7 @@ import (
 type RequestsProcessingStock interface {
 	simulator.ThroughStock
 	RequestCount() int32
+	RequestsAwaitingCalls() RequestsAwaitingCallsStock
 }
 
 type requestsProcessingStock struct {
@@ -35,9 +36,12 @@ type requestsProcessingStock struct {
 	replicaNumber                      int
 	requestsComplete                   simulator.SinkStock
 	requestsFailed                     *simulator.SinkStock
+	requestsAwaitingCalls              RequestsAwaitingCallsStock
 	numRequestsSinceLast               int32
 	totalCPUCapacityMillisPerSecond    *float64
 	occupiedCPUCapacityMillisPerSecond *float64
+	observer                           usageObserver
+	warmup                             *replicaWarmup
 }
 
 func (rps *requestsProcessingStock) Name() simulator.StockName {
@@ -60,6 +64,7 @@ func (rps *requestsProcessingStock) EntitiesInStock() []*simulator.Entity {
 func (rps *requestsProcessingStock) Remove(entity *simulator.Entity) simulator.Entity {
 	request := rps.delegate.Remove(entity).(*requestEntity)
 	*rps.occupiedCPUCapacityMillisPerSecond -= *request.utilizationForRequestMillisPerSecond
+	rps.observer.changed(rps.env.CurrentMovementTime())
 	return request
 }
 
@@ -71,17 +76,28 @@ func (rps *requestsProcessingStock) Add(entity simulator.Entity) error {
 	if !ok {
 		return fmt.Errorf("requests processing stock only supports request entities. got %T", entity)
 	}
-	request := *req
+	if len(req.requestConfig.Calls) > 0 {
+		rps.requestsAwaitingCalls.MakeCalls(req)
+	}
 	now := rps.env.CurrentMovementTime()
-	if request.startTime == nil {
-		request.startTime = &now
+	if req.startTime == nil {
+		req.startTime = &now
 	}
+	request := *req
 
 	isRequestSuccessful := true
 
 	rps.calculateCPUUtilizationForRequest(request, &totalTime, &isRequestSuccessful)
 
-	if isRequestSuccessful {
+	if isRequestSuccessful && req.calls != nil {
+		rps.env.AddToSchedule(simulator.NewMovement(
+			"await_calls",
+			rps.env.CurrentMovementTime().Add(totalTime),
+			rps,
+			rps.requestsAwaitingCalls,
+			&entity,
+		))
+	} else if isRequestSuccessful {
 		rps.env.AddToSchedule(simulator.NewMovement(
 			"complete_request",
 			rps.env.CurrentMovementTime().Add(totalTime),
@@ -99,7 +115,9 @@ func (rps *requestsProcessingStock) Add(entity simulator.Entity) error {
 		))
 	}
 
-	return rps.delegate.Add(entity)
+	err := rps.delegate.Add(entity)
+	rps.observer.changed(now)
+	return err
 }
 
 func (rps *requestsProcessingStock) calculateCPUUtilizationForRequest(request requestEntity, totalTime *time.Duration, isRequestSuccessful *bool) {
@@ -107,12 +125,14 @@ func (rps *requestsProcessingStock) calculateCPUUtilizationForRequest(request re
 	freeCPUCapacityMillisPerSecond := *rps.totalCPUCapacityMillisPerSecond - *rps.occupiedCPUCapacityMillisPerSecond
 	const eps = 0.001
 	if freeCPUCapacityMillisPerSecond > eps {
+		requestCPUTimeMillis, requestIOTimeMillis := rps.warmup.scale(request.requestConfig, rps.env.CurrentMovementTime())
+
 		//step 2 Calculate how many cpu time we need to process this request, need to multiply by 1000
 		//to get cpuTimeMillis in milliseconds
-		cpuTimeMillis := float64(request.requestConfig.CPUTimeMillis) * 1000 / freeCPUCapacityMillisPerSecond
+		cpuTimeMillis := requestCPUTimeMillis * 1000 / freeCPUCapacityMillisPerSecond
 
 		//step 3 Calculate how many time we need to process this request taking into account io time
-		processingTimeMillis := cpuTimeMillis + float64(request.requestConfig.IOTimeMillis)
+		processingTimeMillis := cpuTimeMillis + requestIOTimeMillis
 
 		//step 4 Calculate average cpu load for the request that is utilization for the request
 		utilizationForRequestMillisPerSecond := cpuTimeMillis * freeCPUCapacityMillisPerSecond / processingTimeMillis
@@ -142,6 +162,24 @@ func (rps *requestsProcessingStock) RequestCount() int32 {
 	return rc
 }
 
+func (rps *requestsProcessingStock) RequestsAwaitingCalls() RequestsAwaitingCallsStock {
+	return rps.requestsAwaitingCalls
+}
+
+// resizeCPU leaves requests already in progress alone; only later arrivals see the new capacity.
+func (rps *requestsProcessingStock) resizeCPU(apply func()) {
+	apply()
+}
+
+func (rps *requestsProcessingStock) warmUpWith(warmup *replicaWarmup) {
+	rps.warmup = warmup
+}
+
+func (rps *requestsProcessingStock) observeUsage(observer usageObserver) {
+	rps.observer = observer
+	rps.requestsAwaitingCalls.(usageObservable).observeUsage(observer)
+}
+
 func NewRequestsProcessingStock(env simulator.Environment, replicaNumber int, requestComplete simulator.SinkStock,
 	requestFailed *simulator.SinkStock, totalCPUCapacityMillisPerSecond *float64, occupiedCPUCapacityMillisPerSecond *float64) RequestsProcessingStock {
 	return &requestsProcessingStock{
@@ -150,6 +188,7 @@ func NewRequestsProcessingStock(env simulator.Environment, replicaNumber int, re
 		replicaNumber:                      replicaNumber,
 		requestsComplete:                   requestComplete,
 		requestsFailed:                     requestFailed,
+		requestsAwaitingCalls:              NewRequestsAwaitingCallsStock(env, replicaNumber, requestComplete, requestFailed),
 		occupiedCPUCapacityMillisPerSecond: occupiedCPUCapacityMillisPerSecond,
 		totalCPUCapacityMillisPerSecond:    totalCPUCapacityMillisPerSecond,
 	}
@@ -131,6 +131,30 @@ func testRequestsProcessing(t *testing.T, describe spec.G, it spec.S) {
 				assert.Less(t, math.Abs(*rawSubject.occupiedCPUCapacityMillisPerSecond-0.0), 0.001)
 			})
 		})
+
+		describe("request makes calls to a downstream service", func() {
+			it.Before(func() {
+				*rawSubject.occupiedCPUCapacityMillisPerSecond = 0.0
+				routingStock := NewRequestsRoutingStock(envFake, NewReplicasActiveStock(envFake), nil)
+				downstream := NewTrafficSource(envFake, routingStock, RequestConfig{CPUTimeMillis: 200, IOTimeMillis: 200, Timeout: 3 * time.Second})
+				request = NewRequestEntity(envFake, routingStock, RequestConfig{
+					CPUTimeMillis: 200,
+					IOTimeMillis:  200,
+					Timeout:       3 * time.Second,
+					Calls:         []ServiceCall{{Service: downstream, FanOut: 1, Probability: 1}},
+				})
+				subject.Add(request)
+			})
+
+			it("sends the call", func() {
+				assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), envFake.Movements[0].Kind())
+			})
+
+			it("schedules a movement from RequestsProcessing to RequestsAwaitingCalls", func() {
+				assert.Equal(t, simulator.MovementKind("await_calls"), envFake.Movements[1].Kind())
+				assert.Equal(t, simulator.StockName("RequestsAwaitingCalls [99]"), envFake.Movements[1].To().Name())
+			})
+		})
 	})
 
 	describe("RequestCount()", func() {
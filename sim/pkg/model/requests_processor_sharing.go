@@ -0,0 +1,320 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"skenario/pkg/simulator"
+)
+
+// A single request can't use more than one core, no matter how idle the replica is.
+const maxCPUMillisPerSecondPerRequest = 1000.0
+
+// sharedRequest tracks a request's progress through its CPU phase, followed by its IO phase.
+type sharedRequest struct {
+	entity             simulator.Entity
+	arrivedAt          time.Time
+	remainingCPUMillis float64
+	ioTime             time.Duration
+	timeout            time.Duration
+	awaitsCalls        bool
+	finish             simulator.Movement
+}
+
+func (sr *sharedRequest) cpuBound() bool {
+	return sr.remainingCPUMillis > 0
+}
+
+// requestsProcessorSharingStock divides the replica's CPU capacity evenly between the requests
+// in their CPU phase. Whenever a request arrives or leaves, the completion times of all other
+// requests are recalculated and their movements rescheduled.
+type requestsProcessorSharingStock struct {
+	env                                simulator.Environment
+	delegate                           simulator.ThroughStock
+	replicaNumber                      int
+	requestsComplete                   simulator.SinkStock
+	requestsFailed                     *simulator.SinkStock
+	requestsAwaitingCalls              RequestsAwaitingCallsStock
+	numRequestsSinceLast               int32
+	totalCPUCapacityMillisPerSecond    *float64
+	occupiedCPUCapacityMillisPerSecond *float64
+	requests                           []*sharedRequest
+	updatedAt                          time.Time
+	observer                           usageObserver
+	warmup                             *replicaWarmup
+}
+
+func (rpss *requestsProcessorSharingStock) Name() simulator.StockName {
+	name := fmt.Sprintf("%s [%d]", rpss.delegate.Name(), rpss.replicaNumber)
+	return simulator.StockName(name)
+}
+
+func (rpss *requestsProcessorSharingStock) KindStocked() simulator.EntityKind {
+	return rpss.delegate.KindStocked()
+}
+
+func (rpss *requestsProcessorSharingStock) Count() uint64 {
+	return rpss.delegate.Count()
+}
+
+func (rpss *requestsProcessorSharingStock) EntitiesInStock() []*simulator.Entity {
+	return rpss.delegate.EntitiesInStock()
+}
+
+func (rpss *requestsProcessorSharingStock) Remove(entity *simulator.Entity) simulator.Entity {
+	rpss.advance(rpss.env.CurrentMovementTime())
+
+	removed := rpss.delegate.Remove(entity)
+	if removed == nil {
+		return nil
+	}
+
+	wasCPUBound := false
+	for i, sr := range rpss.requests {
+		if sr.entity == removed {
+			wasCPUBound = sr.cpuBound()
+			if sr.finish != nil {
+				rpss.env.RemoveFromSchedule(sr.finish)
+			}
+			rpss.requests = append(rpss.requests[:i], rpss.requests[i+1:]...)
+			break
+		}
+	}
+
+	if wasCPUBound {
+		rpss.reschedule()
+	}
+	rpss.observer.changed(rpss.updatedAt)
+
+	return removed
+}
+
+func (rpss *requestsProcessorSharingStock) Add(entity simulator.Entity) error {
+	req, ok := entity.(*requestEntity)
+	if !ok {
+		return fmt.Errorf("requests processing stock only supports request entities. got %T", entity)
+	}
+	rpss.numRequestsSinceLast++
+	if len(req.requestConfig.Calls) > 0 {
+		rpss.requestsAwaitingCalls.MakeCalls(req)
+	}
+
+	now := rpss.env.CurrentMovementTime()
+	if req.startTime == nil {
+		req.startTime = &now
+	}
+	rpss.advance(now)
+
+	err := rpss.delegate.Add(entity)
+	if err != nil {
+		return err
+	}
+
+	cpuTimeMillis, ioTimeMillis := rpss.warmup.scale(req.requestConfig, now)
+	sr := &sharedRequest{
+		entity:             entity,
+		awaitsCalls:        req.calls != nil,
+		arrivedAt:          now,
+		remainingCPUMillis: cpuTimeMillis,
+		ioTime:             time.Duration(ioTimeMillis * float64(time.Millisecond)),
+		timeout:            req.requestConfig.Timeout,
+	}
+	rpss.requests = append(rpss.requests, sr)
+
+	if sr.cpuBound() {
+		rpss.reschedule()
+	} else {
+		rpss.schedule(sr, now.Add(sr.ioTime))
+	}
+	rpss.observer.changed(now)
+
+	return nil
+}
+
+func (rpss *requestsProcessorSharingStock) RequestCount() int32 {
+	rc := rpss.numRequestsSinceLast
+	rpss.numRequestsSinceLast = 0
+	return rc
+}
+
+func (rpss *requestsProcessorSharingStock) RequestsAwaitingCalls() RequestsAwaitingCallsStock {
+	return rpss.requestsAwaitingCalls
+}
+
+// resizeCPU shares the new capacity between requests from now on.
+func (rpss *requestsProcessorSharingStock) resizeCPU(apply func()) {
+	now := rpss.env.CurrentMovementTime()
+	rpss.advance(now)
+	apply()
+	rpss.reschedule()
+	rpss.observer.changed(now)
+}
+
+func (rpss *requestsProcessorSharingStock) warmUpWith(warmup *replicaWarmup) {
+	rpss.warmup = warmup
+}
+
+func (rpss *requestsProcessorSharingStock) observeUsage(observer usageObserver) {
+	rpss.observer = observer
+	rpss.requestsAwaitingCalls.(usageObservable).observeUsage(observer)
+}
+
+func (rpss *requestsProcessorSharingStock) cpuBoundRequests() []*sharedRequest {
+	cpuBound := make([]*sharedRequest, 0, len(rpss.requests))
+	for _, sr := range rpss.requests {
+		if sr.cpuBound() {
+			cpuBound = append(cpuBound, sr)
+		}
+	}
+	return cpuBound
+}
+
+func (rpss *requestsProcessorSharingStock) cpuMillisPerSecondPerRequest(cpuBound int) float64 {
+	if cpuBound == 0 {
+		return 0
+	}
+
+	share := *rpss.totalCPUCapacityMillisPerSecond / float64(cpuBound)
+	if share > maxCPUMillisPerSecondPerRequest {
+		return maxCPUMillisPerSecondPerRequest
+	}
+	return share
+}
+
+// advance burns down the remaining CPU time of every CPU-bound request up until the given time,
+// moving requests into their IO phase as they finish with the CPU.
+func (rpss *requestsProcessorSharingStock) advance(until time.Time) {
+	for until.After(rpss.updatedAt) {
+		cpuBound := rpss.cpuBoundRequests()
+		rate := rpss.cpuMillisPerSecondPerRequest(len(cpuBound))
+		if rate <= 0 {
+			break
+		}
+
+		least := cpuBound[0].remainingCPUMillis
+		for _, sr := range cpuBound {
+			if sr.remainingCPUMillis < least {
+				least = sr.remainingCPUMillis
+			}
+		}
+
+		elapsed := until.Sub(rpss.updatedAt)
+		untilLeastFinishes := time.Duration(least / rate * float64(time.Second))
+		if untilLeastFinishes > elapsed {
+			for _, sr := range cpuBound {
+				sr.remainingCPUMillis -= rate * elapsed.Seconds()
+			}
+			break
+		}
+
+		for _, sr := range cpuBound {
+			sr.remainingCPUMillis -= least
+			if sr.remainingCPUMillis < 1e-9 {
+				sr.remainingCPUMillis = 0
+			}
+		}
+		rpss.updatedAt = rpss.updatedAt.Add(untilLeastFinishes)
+
+		// CPU usage drops as soon as a request moves into its IO phase, not at the next movement
+		rpss.updateOccupiedCPU()
+		rpss.observer.changed(rpss.updatedAt)
+	}
+
+	rpss.updatedAt = until
+	rpss.updateOccupiedCPU()
+}
+
+// reschedule projects when each CPU-bound request will finish, assuming no further arrivals.
+func (rpss *requestsProcessorSharingStock) reschedule() {
+	rpss.updateOccupiedCPU()
+
+	cpuBound := rpss.cpuBoundRequests()
+	sort.SliceStable(cpuBound, func(i, j int) bool {
+		return cpuBound[i].remainingCPUMillis < cpuBound[j].remainingCPUMillis
+	})
+
+	at := rpss.updatedAt
+	done := 0.0
+	for i, sr := range cpuBound {
+		rate := rpss.cpuMillisPerSecondPerRequest(len(cpuBound) - i)
+		if rate <= 0 {
+			// without any CPU to share, the request can only time out
+			rpss.schedule(sr, sr.arrivedAt.Add(sr.timeout).Add(1*time.Nanosecond))
+			continue
+		}
+
+		at = at.Add(time.Duration((sr.remainingCPUMillis - done) / rate * float64(time.Second)))
+		done = sr.remainingCPUMillis
+		rpss.schedule(sr, at.Add(sr.ioTime))
+	}
+}
+
+func (rpss *requestsProcessorSharingStock) schedule(sr *sharedRequest, completesAt time.Time) {
+	var kind simulator.MovementKind = "complete_request"
+	var to simulator.SinkStock = rpss.requestsComplete
+	if sr.awaitsCalls {
+		kind = "await_calls"
+		to = rpss.requestsAwaitingCalls
+	}
+	at := completesAt
+
+	timesOutAt := sr.arrivedAt.Add(sr.timeout)
+	if completesAt.After(timesOutAt) {
+		kind = "request_failed"
+		to = *rpss.requestsFailed
+		at = timesOutAt
+	}
+
+	if sr.finish != nil {
+		if sr.finish.Kind() == kind && sr.finish.OccursAt().Equal(at) {
+			return
+		}
+		rpss.env.RemoveFromSchedule(sr.finish)
+		sr.finish = nil
+	}
+
+	// projections past the end of the simulation may be revised many times, so don't record them as ignored
+	if !at.Before(rpss.env.HaltTime()) {
+		return
+	}
+
+	sr.finish = simulator.NewMovement(kind, at, rpss, to, &sr.entity)
+	rpss.env.AddToSchedule(sr.finish)
+}
+
+func (rpss *requestsProcessorSharingStock) updateOccupiedCPU() {
+	cpuBound := len(rpss.cpuBoundRequests())
+	*rpss.occupiedCPUCapacityMillisPerSecond = rpss.cpuMillisPerSecondPerRequest(cpuBound) * float64(cpuBound)
+}
+
+func NewRequestsProcessorSharingStock(env simulator.Environment, replicaNumber int, requestComplete simulator.SinkStock,
+	requestFailed *simulator.SinkStock, totalCPUCapacityMillisPerSecond *float64, occupiedCPUCapacityMillisPerSecond *float64) RequestsProcessingStock {
+	return &requestsProcessorSharingStock{
+		env:                                env,
+		delegate:                           simulator.NewArrayThroughStock("RequestsProcessing", "Request"),
+		replicaNumber:                      replicaNumber,
+		requestsComplete:                   requestComplete,
+		requestsFailed:                     requestFailed,
+		requestsAwaitingCalls:              NewRequestsAwaitingCallsStock(env, replicaNumber, requestComplete, requestFailed),
+		occupiedCPUCapacityMillisPerSecond: occupiedCPUCapacityMillisPerSecond,
+		totalCPUCapacityMillisPerSecond:    totalCPUCapacityMillisPerSecond,
+		requests:                           make([]*sharedRequest, 0),
+		updatedAt:                          env.CurrentMovementTime(),
+	}
+}
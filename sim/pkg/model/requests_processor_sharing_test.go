@@ -0,0 +1,294 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/simulator"
+)
+
+func TestRequestsProcessorSharing(t *testing.T) {
+	spec.Run(t, "RequestsProcessorSharing stock", testRequestsProcessorSharing, spec.Report(report.Terminal{}))
+}
+
+func testRequestsProcessorSharing(t *testing.T, describe spec.G, it spec.S) {
+	var subject RequestsProcessingStock
+	var rawSubject *requestsProcessorSharingStock
+	var envFake *FakeEnvironment
+	var routingStock RequestsRoutingStock
+	var startAt time.Time
+	var config RequestConfig
+
+	newRequest := func() simulator.Entity {
+		return NewRequestEntity(envFake, routingStock, config)
+	}
+
+	it.Before(func() {
+		envFake = NewFakeEnvironment()
+		startAt = envFake.TheTime
+		envFake.TheHaltTime = startAt.Add(1 * time.Minute)
+		totalCPUCapacityMillisPerSecond := 1000.0
+		occupiedCPUCapacityMillisPerSecond := 0.0
+		failedSink := simulator.NewSinkStock("RequestsFailed", "Request")
+		subject = NewRequestsProcessorSharingStock(envFake, 99, simulator.NewSinkStock("RequestsComplete", "Request"),
+			&failedSink, &totalCPUCapacityMillisPerSecond, &occupiedCPUCapacityMillisPerSecond)
+		rawSubject = subject.(*requestsProcessorSharingStock)
+		routingStock = NewRequestsRoutingStock(envFake, NewReplicasActiveStock(envFake), nil)
+		config = RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 3 * time.Second}
+	})
+
+	describe("NewRequestsProcessorSharingStock()", func() {
+		it("sets an Environment", func() {
+			assert.Equal(t, envFake, rawSubject.env)
+		})
+
+		it("creates a delegate ThroughStock", func() {
+			assert.NotNil(t, rawSubject.delegate)
+			assert.Equal(t, simulator.StockName("RequestsProcessing"), rawSubject.delegate.Name())
+			assert.Equal(t, simulator.EntityKind("Request"), rawSubject.delegate.KindStocked())
+		})
+	})
+
+	describe("Name()", func() {
+		it("includes the replica's name", func() {
+			assert.Equal(t, simulator.StockName("RequestsProcessing [99]"), subject.Name())
+		})
+	})
+
+	describe("Add()", func() {
+		describe("a single request", func() {
+			it.Before(func() {
+				err := subject.Add(newRequest())
+				assert.NoError(t, err)
+			})
+
+			it("gets the whole CPU for its CPU time, then waits for its IO time", func() {
+				assert.Len(t, envFake.Movements, 1)
+				assert.Equal(t, simulator.MovementKind("complete_request"), envFake.Movements[0].Kind())
+				assert.WithinDuration(t, startAt.Add(1*time.Second), envFake.Movements[0].OccursAt(), time.Microsecond)
+			})
+
+			it("occupies the whole CPU", func() {
+				assert.Equal(t, 1000.0, *rawSubject.occupiedCPUCapacityMillisPerSecond)
+			})
+		})
+
+		describe("a request arriving at a replica which is warming up", func() {
+			it.Before(func() {
+				rawSubject.warmUpWith(&replicaWarmup{
+					config:      WarmupConfig{Duration: time.Second, InitialCPUBoost: 3, InitialSlowdown: 5},
+					activatedAt: startAt.Add(-500 * time.Millisecond),
+				})
+				assert.NoError(t, subject.Add(newRequest()))
+			})
+
+			it("takes longer for both its CPU time and its IO time", func() {
+				assert.WithinDuration(t, startAt.Add(2500*time.Millisecond), envFake.Movements[0].OccursAt(), time.Microsecond)
+			})
+
+			it("needs more CPU time", func() {
+				assert.Equal(t, 1000.0, rawSubject.requests[0].remainingCPUMillis)
+			})
+		})
+
+		describe("two requests arriving together", func() {
+			it.Before(func() {
+				assert.NoError(t, subject.Add(newRequest()))
+				assert.NoError(t, subject.Add(newRequest()))
+			})
+
+			it("reschedules the first request rather than adding another movement", func() {
+				assert.Len(t, envFake.Movements, 2)
+			})
+
+			it("shares the CPU, so that both complete later", func() {
+				for _, mv := range envFake.Movements {
+					assert.Equal(t, simulator.MovementKind("complete_request"), mv.Kind())
+					assert.WithinDuration(t, startAt.Add(1500*time.Millisecond), mv.OccursAt(), time.Microsecond)
+				}
+			})
+		})
+
+		describe("a second request arriving while the first is using the CPU", func() {
+			it.Before(func() {
+				assert.NoError(t, subject.Add(newRequest()))
+				envFake.TheTime = startAt.Add(250 * time.Millisecond)
+				assert.NoError(t, subject.Add(newRequest()))
+			})
+
+			it("slows down the first request", func() {
+				assert.WithinDuration(t, startAt.Add(1250*time.Millisecond), envFake.Movements[0].OccursAt(), time.Microsecond)
+			})
+
+			it("speeds up the second request once the first leaves the CPU", func() {
+				assert.WithinDuration(t, startAt.Add(1500*time.Millisecond), envFake.Movements[1].OccursAt(), time.Microsecond)
+			})
+		})
+
+		describe("a second request arriving while the first is waiting on IO", func() {
+			it.Before(func() {
+				assert.NoError(t, subject.Add(newRequest()))
+				envFake.TheTime = startAt.Add(750 * time.Millisecond)
+				assert.NoError(t, subject.Add(newRequest()))
+			})
+
+			it("does not affect the first request", func() {
+				assert.WithinDuration(t, startAt.Add(1*time.Second), envFake.Movements[0].OccursAt(), time.Microsecond)
+			})
+
+			it("gives the whole CPU to the second request", func() {
+				assert.WithinDuration(t, startAt.Add(1750*time.Millisecond), envFake.Movements[1].OccursAt(), time.Microsecond)
+			})
+		})
+
+		describe("requests that would complete after their timeout", func() {
+			it.Before(func() {
+				config.Timeout = 1 * time.Second
+				assert.NoError(t, subject.Add(newRequest()))
+				assert.NoError(t, subject.Add(newRequest()))
+			})
+
+			it("schedules them to fail at the timeout", func() {
+				for _, mv := range envFake.Movements {
+					assert.Equal(t, simulator.MovementKind("request_failed"), mv.Kind())
+					assert.Equal(t, simulator.StockName("RequestsFailed"), mv.To().Name())
+					assert.Equal(t, startAt.Add(1*time.Second), mv.OccursAt())
+				}
+			})
+		})
+
+		describe("requests that would complete after the simulation halts", func() {
+			it.Before(func() {
+				envFake.TheHaltTime = startAt.Add(1200 * time.Millisecond)
+				assert.NoError(t, subject.Add(newRequest()))
+				assert.NoError(t, subject.Add(newRequest()))
+			})
+
+			it("does not schedule them", func() {
+				assert.Len(t, envFake.Movements, 0)
+			})
+		})
+
+		describe("requests without CPU time", func() {
+			it.Before(func() {
+				config.CPUTimeMillis = 0
+				assert.NoError(t, subject.Add(newRequest()))
+			})
+
+			it("completes after the IO time", func() {
+				assert.Equal(t, startAt.Add(500*time.Millisecond), envFake.Movements[0].OccursAt())
+			})
+
+			it("does not occupy the CPU", func() {
+				assert.Equal(t, 0.0, *rawSubject.occupiedCPUCapacityMillisPerSecond)
+			})
+		})
+
+		describe("requests that make calls to a downstream service", func() {
+			it.Before(func() {
+				downstream := NewTrafficSource(envFake, routingStock, RequestConfig{CPUTimeMillis: 100, IOTimeMillis: 100, Timeout: time.Second})
+				config.Calls = []ServiceCall{{Service: downstream, FanOut: 1, Probability: 1}}
+				assert.NoError(t, subject.Add(newRequest()))
+			})
+
+			it("sends the call", func() {
+				assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), envFake.Movements[0].Kind())
+			})
+
+			it("moves the request to RequestsAwaitingCalls once processed", func() {
+				assert.Equal(t, simulator.MovementKind("await_calls"), envFake.Movements[1].Kind())
+				assert.Equal(t, simulator.StockName("RequestsAwaitingCalls [99]"), envFake.Movements[1].To().Name())
+				assert.WithinDuration(t, startAt.Add(1*time.Second), envFake.Movements[1].OccursAt(), time.Microsecond)
+			})
+		})
+
+		describe("entities that aren't requests", func() {
+			it("returns an error", func() {
+				err := subject.Add(simulator.NewEntity("not a request", "Request"))
+				assert.Error(t, err)
+			})
+		})
+	})
+
+	describe("Remove()", func() {
+		var first simulator.Entity
+
+		it.Before(func() {
+			first = newRequest()
+			assert.NoError(t, subject.Add(first))
+			assert.NoError(t, subject.Add(newRequest()))
+
+			envFake.TheTime = startAt.Add(250 * time.Millisecond)
+			removed := subject.Remove(&first)
+			assert.Equal(t, first, removed)
+		})
+
+		it("cancels the removed request's movement", func() {
+			assert.Len(t, envFake.Movements, 1)
+		})
+
+		it("gives the remaining request the whole CPU", func() {
+			assert.WithinDuration(t, startAt.Add(1125*time.Millisecond), envFake.Movements[0].OccursAt(), time.Microsecond)
+			assert.Equal(t, 1000.0, *rawSubject.occupiedCPUCapacityMillisPerSecond)
+		})
+
+		it("removes the request from the stock", func() {
+			assert.Equal(t, uint64(1), subject.Count())
+		})
+	})
+
+	describe("observing usage", func() {
+		var observedAt []time.Time
+
+		it.Before(func() {
+			observedAt = nil
+			rawSubject.observeUsage(func(at time.Time) {
+				observedAt = append(observedAt, at)
+			})
+
+			assert.NoError(t, subject.Add(newRequest()))
+			envFake.TheTime = startAt.Add(2 * time.Second)
+			assert.NoError(t, subject.Add(newRequest()))
+		})
+
+		it("is told about arrivals", func() {
+			assert.Equal(t, startAt, observedAt[0])
+			assert.Equal(t, startAt.Add(2*time.Second), observedAt[len(observedAt)-1])
+		})
+
+		it("is told when a request stops using the CPU, between movements", func() {
+			assert.Contains(t, observedAt, startAt.Add(500*time.Millisecond))
+		})
+	})
+
+	describe("RequestCount()", func() {
+		it.Before(func() {
+			assert.NoError(t, subject.Add(newRequest()))
+			assert.NoError(t, subject.Add(newRequest()))
+		})
+
+		it("counts requests since it was last called", func() {
+			assert.Equal(t, int32(2), subject.RequestCount())
+			assert.Equal(t, int32(0), subject.RequestCount())
+		})
+	})
+}
@@ -23,14 +23,16 @@ import (
 
 type RequestsRoutingStock interface {
 	simulator.ThroughStock
+	RequestCount() int32
 }
 
 type requestsRoutingStock struct {
-	env            simulator.Environment
-	delegate       simulator.ThroughStock
-	replicas       ReplicasActiveStock
-	requestsFailed simulator.SinkStock
-	countRequests  int
+	env                  simulator.Environment
+	delegate             simulator.ThroughStock
+	replicas             ReplicasActiveStock
+	requestsFailed       simulator.SinkStock
+	countRequests        int
+	numRequestsSinceLast int32
 }
 
 func (rbs *requestsRoutingStock) Name() simulator.StockName {
@@ -57,6 +59,7 @@ func (rbs *requestsRoutingStock) Add(entity simulator.Entity) error {
 	addResult := rbs.delegate.Add(entity)
 
 	rbs.countRequests++
+	rbs.numRequestsSinceLast++
 
 	countReplicas := rbs.replicas.Count()
 	if countReplicas > 0 {
@@ -83,6 +86,12 @@ func (rbs *requestsRoutingStock) Add(entity simulator.Entity) error {
 	return addResult
 }
 
+func (rbs *requestsRoutingStock) RequestCount() int32 {
+	rc := rbs.numRequestsSinceLast
+	rbs.numRequestsSinceLast = 0
+	return rc
+}
+
 func NewRequestsRoutingStock(env simulator.Environment, replicas ReplicasActiveStock, requestsFailed simulator.SinkStock) RequestsRoutingStock {
 	return &requestsRoutingStock{
 		env:            env,
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"skenario/pkg/simulator"
+)
+
+// RequestsSinkStock is where requests end up once they have completed or failed.
+// If the arriving request was issued by a Requestor, it is told the outcome.
+type RequestsSinkStock interface {
+	simulator.SinkStock
+}
+
+type requestsSinkStock struct {
+	delegate   simulator.SinkStock
+	successful bool
+}
+
+func (rss *requestsSinkStock) Name() simulator.StockName {
+	return rss.delegate.Name()
+}
+
+func (rss *requestsSinkStock) KindStocked() simulator.EntityKind {
+	return rss.delegate.KindStocked()
+}
+
+func (rss *requestsSinkStock) Count() uint64 {
+	return rss.delegate.Count()
+}
+
+func (rss *requestsSinkStock) EntitiesInStock() []*simulator.Entity {
+	return rss.delegate.EntitiesInStock()
+}
+
+func (rss *requestsSinkStock) Add(entity simulator.Entity) error {
+	err := rss.delegate.Add(entity)
+	if err != nil {
+		return err
+	}
+
+	request, ok := entity.(*requestEntity)
+	if ok && request.requestor != nil {
+		request.requestor.RequestFinished(request, rss.successful)
+	}
+
+	return nil
+}
+
+func NewRequestsSinkStock(name simulator.StockName, successful bool) RequestsSinkStock {
+	return &requestsSinkStock{
+		delegate:   simulator.NewSinkStock(name, "Request"),
+		successful: successful,
+	}
+}
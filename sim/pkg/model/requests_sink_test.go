@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/simulator"
+)
+
+type fakeRequestor struct {
+	finished   []RequestEntity
+	successful []bool
+}
+
+func (fr *fakeRequestor) RequestFinished(request RequestEntity, successful bool) {
+	fr.finished = append(fr.finished, request)
+	fr.successful = append(fr.successful, successful)
+}
+
+func TestRequestsSink(t *testing.T) {
+	spec.Run(t, "Requests sink stock", testRequestsSink, spec.Report(report.Terminal{}))
+}
+
+func testRequestsSink(t *testing.T, describe spec.G, it spec.S) {
+	var subject RequestsSinkStock
+	var envFake *FakeEnvironment
+	var trafficSource TrafficSource
+	var requestor *fakeRequestor
+
+	it.Before(func() {
+		envFake = NewFakeEnvironment()
+		routingStock := NewRequestsRoutingStock(envFake, NewReplicasActiveStock(envFake), simulator.NewSinkStock("RequestsFailed", "Request"))
+		trafficSource = NewTrafficSource(envFake, routingStock, RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+		requestor = &fakeRequestor{}
+	})
+
+	describe("NewRequestsSinkStock()", func() {
+		it.Before(func() {
+			subject = NewRequestsSinkStock("RequestsComplete [1]", true)
+		})
+
+		it("uses the given name", func() {
+			assert.Equal(t, simulator.StockName("RequestsComplete [1]"), subject.Name())
+		})
+
+		it("stocks Requests", func() {
+			assert.Equal(t, simulator.EntityKind("Request"), subject.KindStocked())
+		})
+	})
+
+	describe("Add()", func() {
+		describe("for a successful sink", func() {
+			it.Before(func() {
+				subject = NewRequestsSinkStock("RequestsComplete [1]", true)
+				err := subject.Add(trafficSource.RequestFor(requestor))
+				assert.NoError(t, err)
+			})
+
+			it("stocks the request", func() {
+				assert.Equal(t, uint64(1), subject.Count())
+			})
+
+			it("tells the requestor that the request succeeded", func() {
+				assert.Len(t, requestor.finished, 1)
+				assert.True(t, requestor.successful[0])
+			})
+		})
+
+		describe("for a failure sink", func() {
+			it.Before(func() {
+				subject = NewRequestsSinkStock("RequestsFailed", false)
+				err := subject.Add(trafficSource.RequestFor(requestor))
+				assert.NoError(t, err)
+			})
+
+			it("tells the requestor that the request failed", func() {
+				assert.Len(t, requestor.finished, 1)
+				assert.False(t, requestor.successful[0])
+			})
+		})
+
+		describe("requests without a requestor", func() {
+			it.Before(func() {
+				subject = NewRequestsSinkStock("RequestsComplete [1]", true)
+			})
+
+			it("stocks the request", func() {
+				err := subject.Add(trafficSource.Remove(nil))
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(1), subject.Count())
+			})
+		})
+
+		describe("entities of the wrong kind", func() {
+			it.Before(func() {
+				subject = NewRequestsSinkStock("RequestsComplete [1]", true)
+			})
+
+			it("returns an error", func() {
+				err := subject.Add(simulator.NewEntity("not a request", "Replica"))
+				assert.Error(t, err)
+			})
+		})
+	})
+}
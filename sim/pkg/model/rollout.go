@@ -0,0 +1,228 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"skenario/pkg/simulator"
+)
+
+// rolloutStepInterval is how often the Deployment controller reconciles during a rollout.
+const rolloutStepInterval = time.Second
+
+// RolloutConfig replaces replicas with a new revision from At onwards, as a Deployment's
+// RollingUpdate strategy would. The autoscaler keeps scaling the deployment as a whole.
+type RolloutConfig struct {
+	At time.Time
+	// MaxSurge defaults to 1 when MaxUnavailable is also 0.
+	MaxSurge       int
+	MaxUnavailable int
+	// CPUCostFactor is the new revision's CPU time per request, relative to the configured CPU time.
+	CPUCostFactor float64
+}
+
+// RolloutStock steps through a rollout with "rollout_step" movements, until no replicas of
+// older revisions are left launching or active.
+type RolloutStock interface {
+	simulator.ThroughStock
+	Revision() int
+	Done() bool
+}
+
+type rolloutStock struct {
+	env      simulator.Environment
+	cluster  *clusterModel
+	config   RolloutConfig
+	entity   simulator.Entity
+	revision int
+	done     bool
+	// fallbackDesired stands in for the desired replicas until the autoscaler has set them.
+	fallbackDesired int
+}
+
+func (ros *rolloutStock) Name() simulator.StockName {
+	return "Rollout"
+}
+
+func (ros *rolloutStock) KindStocked() simulator.EntityKind {
+	return "Rollout"
+}
+
+func (ros *rolloutStock) Count() uint64 {
+	return 1
+}
+
+func (ros *rolloutStock) EntitiesInStock() []*simulator.Entity {
+	return []*simulator.Entity{&ros.entity}
+}
+
+func (ros *rolloutStock) Remove(entity *simulator.Entity) simulator.Entity {
+	return ros.entity
+}
+
+func (ros *rolloutStock) Add(entity simulator.Entity) error {
+	if ros.entity != entity {
+		return fmt.Errorf("'%+v' is different from the entity given at creation time, '%+v'", entity, ros.entity)
+	}
+
+	rs := ros.cluster.replicaSource.(*replicaSource)
+	if ros.revision == 0 {
+		rs.revision++
+		rs.cpuCostFactor = ros.config.CPUCostFactor
+		ros.revision = rs.revision
+		oldLaunching, oldActive, _, _ := ros.replicasByRevision()
+		ros.fallbackDesired = len(oldLaunching) + len(oldActive)
+	}
+
+	// a later rollout has taken over
+	if rs.revision != ros.revision {
+		ros.done = true
+		return nil
+	}
+
+	ros.step()
+	if !ros.done {
+		ros.env.AddToSchedule(simulator.NewMovement(
+			"rollout_step",
+			ros.env.CurrentMovementTime().Add(rolloutStepInterval),
+			ros,
+			ros,
+			&ros.entity,
+		))
+	}
+
+	return nil
+}
+
+func (ros *rolloutStock) Revision() int {
+	return ros.revision
+}
+
+func (ros *rolloutStock) Done() bool {
+	return ros.done
+}
+
+// step scales the new revision up and older revisions down, as far as maxSurge and maxUnavailable allow.
+func (ros *rolloutStock) step() {
+	now := ros.env.CurrentMovementTime()
+	ros.cluster.forgetTerminated()
+	oldLaunching, oldActive, newLaunching, newActive := ros.replicasByRevision()
+	if len(oldLaunching)+len(oldActive) == 0 {
+		ros.done = true
+		return
+	}
+
+	desired := int(ros.cluster.Desired().Count())
+	if desired == 0 {
+		desired = ros.fallbackDesired
+	}
+	maxSurge, maxUnavailable := ros.config.MaxSurge, ros.config.MaxUnavailable
+	if maxSurge == 0 && maxUnavailable == 0 {
+		maxSurge = 1
+	}
+
+	current := len(oldLaunching) + len(oldActive) + len(newLaunching) + len(newActive)
+	scaleUp := desired + maxSurge - current
+	if missing := desired - len(newLaunching) - len(newActive); missing < scaleUp {
+		scaleUp = missing
+	}
+	launchDelay := ros.cluster.replicasDesired.(*replicasDesiredStock).config.LaunchDelay
+	for i := 0; i < scaleUp; i++ {
+		replica := ros.cluster.replicaSource.(*replicaSource).newReplica().(simulator.Entity)
+		err := ros.cluster.replicasLaunching.Add(replica)
+		if err != nil {
+			panic(err)
+		}
+		ros.env.AddToSchedule(simulator.NewMovement(
+			"create_rollout_replica",
+			now.Add(launchDelay),
+			ros.cluster.replicasLaunching,
+			ros.cluster.replicasActive,
+			&replica,
+		))
+	}
+
+	// old replicas which aren't available yet can go straight away
+	for _, replica := range oldLaunching {
+		ros.terminate(replica, ros.cluster.replicasLaunching, now)
+	}
+
+	// replicas which are already terminating count towards scaling down
+	scaleDown := len(oldActive) + len(newActive) - (desired - maxUnavailable)
+	for i := 0; i < scaleDown && i < len(oldActive); i++ {
+		ros.terminate(oldActive[i], ros.cluster.replicasActive, now)
+	}
+}
+
+// terminate schedules the replica's termination, unless the autoscaler or an earlier step already has.
+func (ros *rolloutStock) terminate(replica *simulator.Entity, from simulator.ThroughStock, now time.Time) {
+	if ros.cluster.terminating[*replica] {
+		return
+	}
+	ros.cluster.terminating[*replica] = true
+
+	ros.env.AddToSchedule(simulator.NewMovement(
+		"terminate_old_revision",
+		now.Add(1*time.Nanosecond),
+		from,
+		ros.cluster.replicasTerminating,
+		replica,
+	))
+}
+
+func (ros *rolloutStock) replicasByRevision() (oldLaunching, oldActive, newLaunching, newActive []*simulator.Entity) {
+	for _, e := range ros.cluster.replicasLaunching.EntitiesInStock() {
+		if isRevision(*e, ros.revision) {
+			newLaunching = append(newLaunching, e)
+		} else {
+			oldLaunching = append(oldLaunching, e)
+		}
+	}
+	for _, e := range ros.cluster.replicasActive.EntitiesInStock() {
+		if isRevision(*e, ros.revision) {
+			newActive = append(newActive, e)
+		} else {
+			oldActive = append(oldActive, e)
+		}
+	}
+	return oldLaunching, oldActive, newLaunching, newActive
+}
+
+func isRevision(entity simulator.Entity, revision int) bool {
+	re, ok := entity.(*replicaEntity)
+	return ok && re.revision == revision
+}
+
+func NewRolloutStock(env simulator.Environment, cluster ClusterModel, config RolloutConfig) RolloutStock {
+	ros := &rolloutStock{
+		env:     env,
+		cluster: cluster.(*clusterModel),
+		config:  config,
+		entity:  simulator.NewEntity("Rollout", "Rollout"),
+	}
+
+	env.AddToSchedule(simulator.NewMovement(
+		"rollout_step",
+		config.At,
+		ros,
+		ros,
+		&ros.entity,
+	))
+
+	return ros
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skenario/pkg/simulator"
+)
+
+func TestRollout(t *testing.T) {
+	spec.Run(t, "Rollout stock", testRollout, spec.Report(report.Terminal{}))
+}
+
+func testRollout(t *testing.T, describe spec.G, it spec.S) {
+	var subject RolloutStock
+	var envFake *FakeEnvironment
+	var cluster *clusterModel
+	var config RolloutConfig
+
+	movementsOfKind := func(kind simulator.MovementKind) []simulator.Movement {
+		movements := make([]simulator.Movement, 0)
+		for _, mv := range envFake.Movements {
+			if mv.Kind() == kind {
+				movements = append(movements, mv)
+			}
+		}
+		return movements
+	}
+
+	step := func() {
+		envFake.Movements = make([]simulator.Movement, 0)
+		require.NoError(t, subject.Add(subject.Remove(nil)))
+	}
+
+	it.Before(func() {
+		envFake = NewFakeEnvironment()
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = time.Unix(600, 0)
+		cluster = NewCluster(envFake, ClusterConfig{}, ReplicasConfig{LaunchDelay: 5 * time.Second}).(*clusterModel)
+		cluster.replicasDesired.(*replicasDesiredStock).config.LaunchDelay = 5 * time.Second
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, cluster.replicasActive.Add(cluster.replicaSource.(*replicaSource).newReplica()))
+			require.NoError(t, cluster.replicasDesired.Add(simulator.NewEntity("Desired", "Desired")))
+		}
+
+		config = RolloutConfig{At: time.Unix(10, 0), CPUCostFactor: 2}
+	})
+
+	describe("NewRolloutStock()", func() {
+		it.Before(func() {
+			envFake.Movements = make([]simulator.Movement, 0)
+			subject = NewRolloutStock(envFake, cluster, config)
+		})
+
+		it("schedules the rollout to start at the scripted time", func() {
+			assert.Len(t, envFake.Movements, 1)
+			assert.Equal(t, simulator.MovementKind("rollout_step"), envFake.Movements[0].Kind())
+			assert.Equal(t, time.Unix(10, 0), envFake.Movements[0].OccursAt())
+		})
+
+		it("has no revision until it starts", func() {
+			assert.Equal(t, 0, subject.Revision())
+		})
+	})
+
+	describe("Add()", func() {
+		describe("starting with the default max surge", func() {
+			it.Before(func() {
+				subject = NewRolloutStock(envFake, cluster, config)
+				envFake.TheTime = config.At
+				step()
+			})
+
+			it("starts a new revision", func() {
+				assert.Equal(t, 2, subject.Revision())
+				assert.Equal(t, 2, cluster.replicaSource.(*replicaSource).revision)
+			})
+
+			it("surges by one new replica", func() {
+				launches := movementsOfKind("create_rollout_replica")
+				require.Len(t, launches, 1)
+				assert.Equal(t, time.Unix(15, 0), launches[0].OccursAt())
+				assert.Equal(t, 2, (*launches[0].WhatToMove()).(*replicaEntity).revision)
+			})
+
+			it("gives the new revision's replicas its CPU cost", func() {
+				replica := (*movementsOfKind("create_rollout_replica")[0].WhatToMove()).(*replicaEntity)
+				assert.Equal(t, 2.0, replica.warmup.cpuCostFactor)
+			})
+
+			it("doesn't make any replicas unavailable", func() {
+				assert.Empty(t, movementsOfKind("terminate_old_revision"))
+			})
+
+			it("takes another step later", func() {
+				steps := movementsOfKind("rollout_step")
+				require.Len(t, steps, 1)
+				assert.Equal(t, time.Unix(11, 0), steps[0].OccursAt())
+			})
+
+			describe("once the new replica is active", func() {
+				it.Before(func() {
+					replica := cluster.replicasLaunching.Remove(nil)
+					require.NoError(t, cluster.replicasActive.Add(replica))
+					step()
+				})
+
+				it("replaces an old replica", func() {
+					terminations := movementsOfKind("terminate_old_revision")
+					require.Len(t, terminations, 1)
+					assert.Equal(t, 1, (*terminations[0].WhatToMove()).(*replicaEntity).revision)
+					assert.Equal(t, simulator.StockName("ReplicasActive"), terminations[0].From().Name())
+				})
+			})
+		})
+
+		describe("with max unavailable", func() {
+			it.Before(func() {
+				config.MaxUnavailable = 2
+				subject = NewRolloutStock(envFake, cluster, config)
+				envFake.TheTime = config.At
+				step()
+			})
+
+			it("terminates old replicas before their replacements are ready", func() {
+				assert.Len(t, movementsOfKind("terminate_old_revision"), 2)
+				assert.Len(t, movementsOfKind("create_rollout_replica"), 0)
+			})
+
+			it("doesn't terminate them again while they are terminating", func() {
+				step()
+				assert.Empty(t, movementsOfKind("terminate_old_revision"))
+			})
+		})
+
+		describe("when the autoscaler is already evicting an old replica", func() {
+			it.Before(func() {
+				config.MaxUnavailable = 2
+				subject = NewRolloutStock(envFake, cluster, config)
+				envFake.TheTime = config.At
+				evicting := cluster.replicasActive.EntitiesInStock()[0]
+				cluster.terminating[*evicting] = true
+				step()
+			})
+
+			it("leaves that replica to the autoscaler", func() {
+				terminations := movementsOfKind("terminate_old_revision")
+				require.Len(t, terminations, 1)
+				assert.NotEqual(t, *cluster.replicasActive.EntitiesInStock()[0], *terminations[0].WhatToMove())
+			})
+		})
+
+		describe("when no old replicas are left", func() {
+			it.Before(func() {
+				subject = NewRolloutStock(envFake, cluster, config)
+				envFake.TheTime = config.At
+				step()
+				for _, e := range cluster.replicasActive.EntitiesInStock() {
+					(*e).(*replicaEntity).revision = 2
+				}
+				step()
+			})
+
+			it("is done", func() {
+				assert.True(t, subject.Done())
+				assert.Empty(t, movementsOfKind("rollout_step"))
+			})
+		})
+	})
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"time"
+)
+
+// timeWeighted integrates a value which changes in steps, so that its average over a period can
+// be reported rather than whatever it happened to be at the end of the period.
+type timeWeighted struct {
+	since   time.Time
+	lastAt  time.Time
+	current float64
+	area    float64
+}
+
+func (tw *timeWeighted) set(at time.Time, value float64) {
+	if tw.since.IsZero() {
+		tw.since = at
+	} else if at.After(tw.lastAt) {
+		tw.area += tw.current * float64(at.Sub(tw.lastAt))
+	}
+	tw.lastAt = at
+	tw.current = value
+}
+
+// average gives the average since the previous call, then starts a new period.
+func (tw *timeWeighted) average(at time.Time) float64 {
+	tw.set(at, tw.current)
+
+	avg := tw.current
+	if elapsed := at.Sub(tw.since); elapsed > 0 {
+		avg = tw.area / float64(elapsed)
+	}
+
+	tw.since = at
+	tw.area = 0
+	return avg
+}
+
+// usageObserver is told whenever a replica's concurrency or CPU usage may have changed.
+type usageObserver func(at time.Time)
+
+func (uo usageObserver) changed(at time.Time) {
+	if uo != nil {
+		uo(at)
+	}
+}
+
+type usageObservable interface {
+	observeUsage(observer usageObserver)
+}
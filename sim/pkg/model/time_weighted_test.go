@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeWeighted(t *testing.T) {
+	spec.Run(t, "Time weighted average", testTimeWeighted, spec.Report(report.Terminal{}))
+}
+
+func testTimeWeighted(t *testing.T, describe spec.G, it spec.S) {
+	var subject *timeWeighted
+	var start = time.Unix(0, 0)
+
+	it.Before(func() {
+		subject = &timeWeighted{}
+	})
+
+	describe("average()", func() {
+		it("gives the current value if no time has passed", func() {
+			subject.set(start, 3)
+			assert.Equal(t, 3.0, subject.average(start))
+		})
+
+		it("weights each value by how long it was held", func() {
+			subject.set(start, 0)
+			subject.set(start.Add(1*time.Second), 4)
+			subject.set(start.Add(3*time.Second), 2)
+			assert.Equal(t, 2.5, subject.average(start.Add(4*time.Second)))
+		})
+
+		it("starts a new period each time", func() {
+			subject.set(start, 4)
+			subject.average(start.Add(1 * time.Second))
+			subject.set(start.Add(2*time.Second), 0)
+			assert.Equal(t, 2.0, subject.average(start.Add(3*time.Second)))
+		})
+	})
+}
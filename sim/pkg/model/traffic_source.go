@@ -16,17 +16,32 @@
 package model
 
 import (
+	"time"
+
 	"skenario/pkg/simulator"
 )
 
 type TrafficSource interface {
 	simulator.SourceStock
+	RequestFor(requestor Requestor) RequestEntity
+	RequestWith(overrides RequestOverrides) RequestEntity
+	Send(requestor Requestor) RequestEntity
+	RequestClasses() map[simulator.EntityName]string
+}
+
+// RequestOverrides replaces parts of the source's RequestConfig for a single request.
+// Zero values keep what the source would otherwise use.
+type RequestOverrides struct {
+	CPUTimeMillis int
+	IOTimeMillis  int
+	Class         string
 }
 
 type trafficSource struct {
 	env             simulator.Environment
 	requestsRouting RequestsRoutingStock
 	requestConfig   RequestConfig
+	classes         map[simulator.EntityName]string
 }
 
 func (ts *trafficSource) Name() simulator.StockName {
@@ -46,13 +61,63 @@ func (ts *trafficSource) EntitiesInStock() []*simulator.Entity {
 }
 
 func (ts *trafficSource) Remove(entity *simulator.Entity) simulator.Entity {
+	if entity != nil {
+		return *entity
+	}
 	return NewRequestEntity(ts.env, ts.requestsRouting, ts.requestConfig)
 }
 
+func (ts *trafficSource) RequestFor(requestor Requestor) RequestEntity {
+	request := NewRequestEntity(ts.env, ts.requestsRouting, ts.requestConfig)
+	request.(*requestEntity).requestor = requestor
+	return request
+}
+
+func (ts *trafficSource) RequestWith(overrides RequestOverrides) RequestEntity {
+	config := ts.requestConfig
+	if overrides.CPUTimeMillis > 0 {
+		config.CPUTimeMillis = overrides.CPUTimeMillis
+	}
+	if overrides.IOTimeMillis > 0 {
+		config.IOTimeMillis = overrides.IOTimeMillis
+	}
+	if overrides.Class != "" {
+		config.Class = overrides.Class
+	}
+
+	request := NewRequestEntity(ts.env, ts.requestsRouting, config)
+	if config.Class != "" {
+		ts.classes[request.Name()] = config.Class
+	}
+	return request
+}
+
+// RequestClasses gives the class of every request created with one.
+func (ts *trafficSource) RequestClasses() map[simulator.EntityName]string {
+	return ts.classes
+}
+
+// Send creates a request on behalf of the requestor and schedules it to arrive at the routing stock straight away.
+func (ts *trafficSource) Send(requestor Requestor) RequestEntity {
+	request := ts.RequestFor(requestor)
+
+	var entity simulator.Entity = request
+	ts.env.AddToSchedule(simulator.NewMovement(
+		"arrive_at_routing_stock",
+		ts.env.CurrentMovementTime().Add(1*time.Nanosecond),
+		ts,
+		ts.requestsRouting,
+		&entity,
+	))
+
+	return request
+}
+
 func NewTrafficSource(env simulator.Environment, requestsRouting RequestsRoutingStock, requestConfig RequestConfig) TrafficSource {
 	return &trafficSource{
 		env:             env,
 		requestsRouting: requestsRouting,
 		requestConfig:   requestConfig,
+		classes:         make(map[simulator.EntityName]string),
 	}
 }
@@ -94,5 +94,78 @@ func testTrafficSource(t *testing.T, describe spec.G, it spec.S) {
 			assert.IsType(t, &requestEntity{}, entity1)
 			assert.Equal(t, simulator.EntityKind("Request"), entity1.Kind())
 		})
+
+		it("returns the given entity, if any", func() {
+			assert.Equal(t, entity1, subject.Remove(&entity1))
+		})
+	})
+
+	describe("RequestFor()", func() {
+		var request RequestEntity
+		var requestor *fakeRequestor
+
+		it.Before(func() {
+			requestor = &fakeRequestor{}
+			request = subject.RequestFor(requestor)
+		})
+
+		it("creates a new RequestEntity", func() {
+			assert.IsType(t, &requestEntity{}, request)
+		})
+
+		it("uses the source's request configuration", func() {
+			assert.Equal(t, rawSubject.requestConfig, request.(*requestEntity).requestConfig)
+		})
+
+		it("remembers the requestor", func() {
+			assert.Equal(t, requestor, request.(*requestEntity).requestor)
+		})
+	})
+
+	describe("RequestWith()", func() {
+		var request RequestEntity
+		var config RequestConfig
+
+		it.Before(func() {
+			request = subject.RequestWith(RequestOverrides{CPUTimeMillis: 20, Class: "checkout"})
+			config = request.(*requestEntity).requestConfig
+		})
+
+		it("uses the overrides that were given", func() {
+			assert.Equal(t, 20, config.CPUTimeMillis)
+			assert.Equal(t, "checkout", config.Class)
+		})
+
+		it("keeps the source's configuration for everything else", func() {
+			assert.Equal(t, 500, config.IOTimeMillis)
+			assert.Equal(t, 1*time.Second, config.Timeout)
+		})
+
+		it("records the class of the request", func() {
+			subject.RequestWith(RequestOverrides{CPUTimeMillis: 20})
+			assert.Equal(t, map[simulator.EntityName]string{request.Name(): "checkout"}, subject.RequestClasses())
+		})
+	})
+
+	describe("Send()", func() {
+		var request RequestEntity
+		var requestor *fakeRequestor
+
+		it.Before(func() {
+			requestor = &fakeRequestor{}
+			request = subject.Send(requestor)
+		})
+
+		it("remembers the requestor", func() {
+			assert.Equal(t, requestor, request.(*requestEntity).requestor)
+		})
+
+		it("schedules the request to arrive at the routing stock", func() {
+			assert.Len(t, envFake.Movements, 1)
+			assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), envFake.Movements[0].Kind())
+			assert.Equal(t, envFake.TheTime.Add(1*time.Nanosecond), envFake.Movements[0].OccursAt())
+			assert.Equal(t, simulator.StockName("RequestsRouting"), envFake.Movements[0].To().Name())
+			assert.Equal(t, simulator.Entity(request), *envFake.Movements[0].WhatToMove())
+		})
 	})
 }
@@ -0,0 +1,276 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+type closedLoop struct {
+	env          simulator.Environment
+	source       model.TrafficSource
+	routingStock model.RequestsRoutingStock
+	users        []VirtualUsersStep
+	thinkTime    model.Distribution
+	userSource   simulator.SourceStock
+	usersActive  simulator.ThroughStock
+	usersRetired simulator.SinkStock
+	requests     simulator.SourceStock
+	userNum      int
+}
+
+// VirtualUsersStep sets the number of virtual users from After onwards.
+type VirtualUsersStep struct {
+	After time.Duration `json:"after"`
+	Users int           `json:"users"`
+}
+
+type ClosedLoopConfig struct {
+	Users     []VirtualUsersStep       `json:"users"`
+	ThinkTime model.DistributionConfig `json:"think_time"`
+}
+
+func (*closedLoop) Name() string {
+	return "closed_loop"
+}
+
+func (cl *closedLoop) Generate() {
+	startAt := cl.env.CurrentMovementTime()
+	steps := make([]VirtualUsersStep, len(cl.users))
+	copy(steps, cl.users)
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].After < steps[j].After
+	})
+
+	currentUsers := 0
+	for _, s := range steps {
+		at := startAt.Add(s.After).Add(1 * time.Nanosecond)
+
+		for ; currentUsers < s.Users; currentUsers++ {
+			cl.env.AddToSchedule(simulator.NewMovement(
+				"add_virtual_user",
+				at,
+				cl.userSource,
+				cl.usersActive,
+				nil,
+			))
+		}
+
+		for ; currentUsers > s.Users; currentUsers-- {
+			cl.env.AddToSchedule(simulator.NewMovement(
+				"retire_virtual_user",
+				at,
+				cl.usersActive,
+				cl.usersRetired,
+				nil,
+			))
+		}
+	}
+}
+
+func (cl *closedLoop) sendAt(user *virtualUser, at time.Time) {
+	var entity simulator.Entity = user
+	cl.env.AddToSchedule(simulator.NewMovement(
+		"arrive_at_routing_stock",
+		at,
+		cl.requests,
+		cl.routingStock,
+		&entity,
+	))
+}
+
+// virtualUser sends a request, waits for it to finish, thinks and then repeats.
+type virtualUser struct {
+	number  int
+	loop    *closedLoop
+	retired bool
+}
+
+func (vu *virtualUser) Name() simulator.EntityName {
+	return simulator.EntityName(fmt.Sprintf("virtual-user-%d", vu.number))
+}
+
+func (vu *virtualUser) Kind() simulator.EntityKind {
+	return "User"
+}
+
+func (vu *virtualUser) RequestFinished(request model.RequestEntity, successful bool) {
+	if vu.retired {
+		return
+	}
+
+	thinkTime := vu.loop.thinkTime.Sample()
+	vu.loop.sendAt(vu, vu.loop.env.CurrentMovementTime().Add(thinkTime).Add(1*time.Nanosecond))
+}
+
+type virtualUserSource struct {
+	loop *closedLoop
+}
+
+func (vus *virtualUserSource) Name() simulator.StockName {
+	return "VirtualUserSource"
+}
+
+func (vus *virtualUserSource) KindStocked() simulator.EntityKind {
+	return "User"
+}
+
+func (vus *virtualUserSource) Count() uint64 {
+	return 0
+}
+
+func (vus *virtualUserSource) EntitiesInStock() []*simulator.Entity {
+	return []*simulator.Entity{}
+}
+
+func (vus *virtualUserSource) Remove(entity *simulator.Entity) simulator.Entity {
+	vus.loop.userNum++
+	return &virtualUser{
+		number: vus.loop.userNum,
+		loop:   vus.loop,
+	}
+}
+
+// virtualUsersActiveStock holds users that are waiting on a request or thinking.
+// Users start sending requests as soon as they arrive and stop once removed.
+type virtualUsersActiveStock struct {
+	loop     *closedLoop
+	delegate simulator.ThroughStock
+}
+
+func (vuas *virtualUsersActiveStock) Name() simulator.StockName {
+	return vuas.delegate.Name()
+}
+
+func (vuas *virtualUsersActiveStock) KindStocked() simulator.EntityKind {
+	return vuas.delegate.KindStocked()
+}
+
+func (vuas *virtualUsersActiveStock) Count() uint64 {
+	return vuas.delegate.Count()
+}
+
+func (vuas *virtualUsersActiveStock) EntitiesInStock() []*simulator.Entity {
+	return vuas.delegate.EntitiesInStock()
+}
+
+func (vuas *virtualUsersActiveStock) Add(entity simulator.Entity) error {
+	err := vuas.delegate.Add(entity)
+	if err != nil {
+		return err
+	}
+
+	vuas.loop.sendAt(entity.(*virtualUser), vuas.loop.env.CurrentMovementTime().Add(1*time.Nanosecond))
+	return nil
+}
+
+func (vuas *virtualUsersActiveStock) Remove(entity *simulator.Entity) simulator.Entity {
+	removed := vuas.delegate.Remove(entity)
+	if removed == nil {
+		return nil
+	}
+
+	removed.(*virtualUser).retired = true
+	return removed
+}
+
+// virtualUserRequests creates requests on behalf of the user being moved.
+// Users that retired while thinking send nothing.
+type virtualUserRequests struct {
+	source model.TrafficSource
+}
+
+func (vur *virtualUserRequests) Name() simulator.StockName {
+	return vur.source.Name()
+}
+
+func (vur *virtualUserRequests) KindStocked() simulator.EntityKind {
+	return vur.source.KindStocked()
+}
+
+func (vur *virtualUserRequests) Count() uint64 {
+	return 0
+}
+
+func (vur *virtualUserRequests) EntitiesInStock() []*simulator.Entity {
+	return []*simulator.Entity{}
+}
+
+func (vur *virtualUserRequests) Remove(entity *simulator.Entity) simulator.Entity {
+	if entity == nil {
+		return nil
+	}
+
+	user := (*entity).(*virtualUser)
+	if user.retired {
+		return nil
+	}
+
+	return vur.source.RequestFor(user)
+}
+
+func init() {
+	Register(Registration{
+		Name:        "closed_loop",
+		Description: "Virtual users who each send a request, wait for its response and think before sending the next.",
+		Config: func() interface{} {
+			return &ClosedLoopConfig{}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewClosedLoop(w.Env, w.Source, w.RoutingStock, *config.(*ClosedLoopConfig))
+		},
+	})
+}
+
+func (c ClosedLoopConfig) Validate() error {
+	for _, s := range c.Users {
+		if s.Users < 0 || s.After < 0 {
+			return fmt.Errorf("virtual user steps must not be negative, got %v", s)
+		}
+	}
+	if err := c.ThinkTime.Validate(); err != nil {
+		return fmt.Errorf("invalid think time: %s", err)
+	}
+	return nil
+}
+
+func NewClosedLoop(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config ClosedLoopConfig) Pattern {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	cl := &closedLoop{
+		env:          env,
+		source:       source,
+		routingStock: routingStock,
+		users:        config.Users,
+		thinkTime:    model.NewDistribution(config.ThinkTime),
+		usersRetired: simulator.NewSinkStock("VirtualUsersRetired", "User"),
+		requests:     &virtualUserRequests{source: source},
+	}
+	cl.userSource = &virtualUserSource{loop: cl}
+	cl.usersActive = &virtualUsersActiveStock{
+		loop:     cl,
+		delegate: simulator.NewArrayThroughStock("VirtualUsersActive", "User"),
+	}
+
+	return cl
+}
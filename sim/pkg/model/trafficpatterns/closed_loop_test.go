@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestClosedLoop(t *testing.T) {
+	spec.Run(t, "Closed loop traffic pattern", testClosedLoop, spec.Report(report.Terminal{}))
+}
+
+func testClosedLoop(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var config ClosedLoopConfig
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheHaltTime = envFake.TheTime.Add(20 * time.Second)
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+
+		config = ClosedLoopConfig{
+			Users: []VirtualUsersStep{
+				{After: 10 * time.Second, Users: 2},
+				{After: 0, Users: 5},
+			},
+			ThinkTime: model.DistributionConfig{Kind: model.DistributionConstant, Mean: 2 * time.Second},
+		}
+		subject = NewClosedLoop(envFake, trafficSource, routingStock, config)
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'closed_loop'", func() {
+			assert.Equal(t, "closed_loop", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		it.Before(func() {
+			subject.Generate()
+		})
+
+		it("schedules 8 movements", func() {
+			assert.Len(t, envFake.Movements, 8)
+		})
+
+		it("adds 5 virtual users at the start", func() {
+			for i := 0; i < 5; i++ {
+				assert.Equal(t, simulator.MovementKind("add_virtual_user"), envFake.Movements[i].Kind())
+				assert.Equal(t, envFake.TheTime.Add(1*time.Nanosecond), envFake.Movements[i].OccursAt())
+			}
+		})
+
+		it("retires 3 virtual users after the second step begins", func() {
+			for i := 5; i < 8; i++ {
+				assert.Equal(t, simulator.MovementKind("retire_virtual_user"), envFake.Movements[i].Kind())
+				assert.Equal(t, envFake.TheTime.Add(10*time.Second).Add(1*time.Nanosecond), envFake.Movements[i].OccursAt())
+			}
+		})
+	})
+
+	describe("virtual users", func() {
+		var cl *closedLoop
+		var user simulator.Entity
+
+		it.Before(func() {
+			cl = subject.(*closedLoop)
+			user = cl.userSource.Remove(nil)
+			err := cl.usersActive.Add(user)
+			assert.NoError(t, err)
+		})
+
+		describe("when a user becomes active", func() {
+			it("schedules a request to arrive at the routing stock", func() {
+				assert.Len(t, envFake.Movements, 1)
+				assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), envFake.Movements[0].Kind())
+				assert.Equal(t, envFake.TheTime.Add(1*time.Nanosecond), envFake.Movements[0].OccursAt())
+			})
+
+			it("creates a request on behalf of the user", func() {
+				request := cl.requests.Remove(envFake.Movements[0].WhatToMove())
+				assert.NotNil(t, request)
+				assert.Equal(t, simulator.EntityKind("Request"), request.Kind())
+			})
+		})
+
+		describe("when a request finishes", func() {
+			it.Before(func() {
+				user.(model.Requestor).RequestFinished(nil, true)
+			})
+
+			it("schedules the next request after the think time", func() {
+				assert.Len(t, envFake.Movements, 2)
+				assert.Equal(t, envFake.TheTime.Add(2*time.Second).Add(1*time.Nanosecond), envFake.Movements[1].OccursAt())
+			})
+		})
+
+		describe("when the user is retired", func() {
+			it.Before(func() {
+				retired := cl.usersActive.Remove(nil)
+				assert.Equal(t, user, retired)
+				user.(model.Requestor).RequestFinished(nil, true)
+			})
+
+			it("does not schedule further requests", func() {
+				assert.Len(t, envFake.Movements, 1)
+			})
+
+			it("does not create requests that were already scheduled", func() {
+				entity := user
+				assert.Nil(t, cl.requests.Remove(&entity))
+			})
+		})
+
+		it("numbers users within each loop", func() {
+			other := NewClosedLoop(envFake, trafficSource, routingStock, config).(*closedLoop)
+			first := other.userSource.Remove(nil)
+			assert.Equal(t, simulator.EntityName("virtual-user-1"), first.Name())
+		})
+	})
+
+	describe("Validate()", func() {
+		it("accepts the config", func() {
+			assert.NoError(t, config.Validate())
+		})
+
+		it("rejects negative user counts", func() {
+			config.Users = []VirtualUsersStep{{Users: -1}}
+			assert.Error(t, config.Validate())
+		})
+
+		it("rejects unknown think time kinds", func() {
+			config.ThinkTime.Kind = "bogus"
+			assert.Error(t, config.Validate())
+		})
+	})
+}
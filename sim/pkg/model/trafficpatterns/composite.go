@@ -0,0 +1,231 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+const (
+	// CompositeAdd overlays segments, each starting After the start of the composite.
+	CompositeAdd = "add"
+	// CompositeSequence plays segments one after another, each starting After the end of the one before.
+	CompositeSequence = "sequence"
+)
+
+type composite struct {
+	wiring   Wiring
+	operator string
+	segments []Segment
+	started  bool
+	built    []builtSegment
+}
+
+// builtSegment is a segment's pattern, built once the composite starts and placed in time.
+type builtSegment struct {
+	pattern   Pattern
+	startAt   time.Time
+	generated bool
+}
+
+type CompositeConfig struct {
+	Operator string    `json:"operator,omitempty"` // add (the default) or sequence
+	Segments []Segment `json:"segments"`
+}
+
+// Segment is one of the other patterns, with its usual configuration, placed in time and scaled.
+type Segment struct {
+	Pattern string          `json:"pattern"`
+	Config  json.RawMessage `json:"config,omitempty"`
+	After   time.Duration   `json:"after,omitempty"`
+	For     time.Duration   `json:"for,omitempty"`   // clips the segment; when 0 it runs until the composite ends
+	Scale   float64         `json:"scale,omitempty"` // multiplies the segment's requests; defaults to 1
+}
+
+func (*composite) Name() string {
+	return "composite"
+}
+
+func (c *composite) Generate() {
+	c.GenerateUntil(c.wiring.Env.HaltTime())
+}
+
+// GenerateUntil passes the window on to segments which generate incrementally. The others are
+// generated in full once the window reaches them.
+func (c *composite) GenerateUntil(until time.Time) {
+	if !c.started {
+		c.started = true
+		c.build()
+	}
+
+	for i := range c.built {
+		segment := &c.built[i]
+		if !segment.startAt.Before(until) {
+			continue
+		}
+
+		if incremental, ok := segment.pattern.(IncrementalPattern); ok {
+			incremental.GenerateUntil(until)
+		} else if !segment.generated {
+			segment.pattern.Generate()
+		}
+		segment.generated = true
+	}
+}
+
+func (c *composite) build() {
+	startAt := c.wiring.Env.CurrentMovementTime()
+	haltAt := c.wiring.Env.HaltTime()
+
+	segmentStart := startAt
+	for _, s := range c.segments {
+		if c.operator == CompositeSequence {
+			segmentStart = segmentStart.Add(s.After)
+		} else {
+			segmentStart = startAt.Add(s.After)
+		}
+
+		segmentHalt := haltAt
+		if s.For > 0 && segmentStart.Add(s.For).Before(haltAt) {
+			segmentHalt = segmentStart.Add(s.For)
+		}
+
+		if segmentStart.Before(segmentHalt) {
+			w := c.wiring
+			w.Env = &segmentEnvironment{
+				Environment: c.wiring.Env,
+				startAt:     segmentStart,
+				haltAt:      segmentHalt,
+				scale:       s.Scale,
+			}
+			c.built = append(c.built, builtSegment{pattern: Build(w, s.Pattern, s.Config), startAt: segmentStart})
+		}
+
+		if c.operator == CompositeSequence {
+			if s.For == 0 {
+				break // the segment runs until the end, leaving nothing for the rest
+			}
+			segmentStart = segmentStart.Add(s.For)
+		}
+	}
+}
+
+func init() {
+	Register(Registration{
+		Name:        "composite",
+		Description: "Other patterns added together or played in sequence, each placed in time and scaled.",
+		Config: func() interface{} {
+			return &CompositeConfig{Operator: CompositeAdd}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return newComposite(w, *config.(*CompositeConfig))
+		},
+	})
+}
+
+// Validate checks the composite and the configs of its segments.
+func (c CompositeConfig) Validate() error {
+	switch c.Operator {
+	case "", CompositeAdd, CompositeSequence:
+	default:
+		return fmt.Errorf("unknown composite operator '%s'", c.Operator)
+	}
+
+	for _, s := range c.Segments {
+		if s.Scale < 0 || s.For < 0 {
+			return fmt.Errorf("segment scale and duration must not be negative, got %v and %v", s.Scale, s.For)
+		}
+		if _, err := ReadConfig(s.Pattern, s.Config); err != nil {
+			return fmt.Errorf("the '%s' segment is not valid: %s", s.Pattern, err.Error())
+		}
+	}
+	return nil
+}
+
+func NewComposite(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config CompositeConfig) Pattern {
+	return newComposite(Wiring{Env: env, Source: source, RoutingStock: routingStock}, config)
+}
+
+func newComposite(w Wiring, config CompositeConfig) Pattern {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	operator := config.Operator
+	if operator == "" {
+		operator = CompositeAdd
+	}
+
+	return &composite{
+		wiring:   w,
+		operator: operator,
+		segments: config.Segments,
+	}
+}
+
+// segmentEnvironment shows a pattern a shorter run and scales the requests it schedules.
+// Only arrivals that leave the request to the traffic source are scaled; movements of
+// entities the pattern made itself, like virtual users, are kept as they are.
+type segmentEnvironment struct {
+	simulator.Environment
+	startAt time.Time
+	haltAt  time.Time
+	scale   float64
+}
+
+func (se *segmentEnvironment) CurrentMovementTime() time.Time {
+	current := se.Environment.CurrentMovementTime()
+	if current.Before(se.startAt) {
+		return se.startAt
+	}
+	return current
+}
+
+func (se *segmentEnvironment) HaltTime() time.Time {
+	return se.haltAt
+}
+
+func (se *segmentEnvironment) AddToSchedule(movement simulator.Movement) bool {
+	if movement.OccursAt().Before(se.startAt) || !movement.OccursAt().Before(se.haltAt) {
+		return false
+	}
+
+	if movement.WhatToMove() != nil || se.scale == 0 || se.scale == 1 {
+		return se.Environment.AddToSchedule(movement)
+	}
+
+	copies := int(math.Floor(se.scale))
+	if rand.Float64() < se.scale-float64(copies) {
+		copies++
+	}
+
+	added := false
+	for i := 0; i < copies; i++ {
+		if i == 0 {
+			added = se.Environment.AddToSchedule(movement)
+			continue
+		}
+		se.Environment.AddToSchedule(simulator.NewMovement(movement.Kind(), movement.OccursAt(), movement.From(), movement.To(), nil))
+	}
+	return added
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestComposite(t *testing.T) {
+	spec.Run(t, "Composite traffic pattern", testComposite, spec.Report(report.Terminal{}))
+}
+
+func testComposite(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	stepAt := func(rps int) json.RawMessage {
+		config, err := json.Marshal(StepConfig{RPS: rps})
+		assert.NoError(t, err)
+		return config
+	}
+
+	countBetween := func(from, until time.Duration) int {
+		count := 0
+		for _, mv := range envFake.Movements {
+			if !mv.OccursAt().Before(envFake.TheTime.Add(from)) && mv.OccursAt().Before(envFake.TheTime.Add(until)) {
+				count++
+			}
+		}
+		return count
+	}
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(20 * time.Second)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'composite'", func() {
+			subject = NewComposite(envFake, trafficSource, routingStock, CompositeConfig{})
+			assert.Equal(t, "composite", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		describe("adding segments", func() {
+			it.Before(func() {
+				subject = NewComposite(envFake, trafficSource, routingStock, CompositeConfig{
+					Segments: []Segment{
+						{Pattern: "step", Config: stepAt(10)},
+						{Pattern: "step", Config: stepAt(5), After: 10 * time.Second, For: 5 * time.Second},
+					},
+				})
+				subject.Generate()
+			})
+
+			it("overlays the segments", func() {
+				assert.Len(t, envFake.Movements, 200+25)
+				assert.Equal(t, 100, countBetween(0, 10*time.Second))
+				assert.Equal(t, 75, countBetween(10*time.Second, 15*time.Second))
+				assert.Equal(t, 50, countBetween(15*time.Second, 20*time.Second))
+			})
+		})
+
+		describe("sequencing segments", func() {
+			it.Before(func() {
+				subject = NewComposite(envFake, trafficSource, routingStock, CompositeConfig{
+					Operator: CompositeSequence,
+					Segments: []Segment{
+						{Pattern: "step", Config: stepAt(10), For: 5 * time.Second},
+						{Pattern: "step", Config: stepAt(20), After: 5 * time.Second, For: 5 * time.Second},
+						{Pattern: "step", Config: stepAt(1)},
+					},
+				})
+				subject.Generate()
+			})
+
+			it("starts each segment after the one before", func() {
+				assert.Equal(t, 50, countBetween(0, 5*time.Second))
+				assert.Equal(t, 0, countBetween(5*time.Second, 10*time.Second))
+				assert.Equal(t, 100, countBetween(10*time.Second, 15*time.Second))
+				assert.Equal(t, 5, countBetween(15*time.Second, 20*time.Second))
+			})
+		})
+
+		describe("scaling a segment", func() {
+			it.Before(func() {
+				subject = NewComposite(envFake, trafficSource, routingStock, CompositeConfig{
+					Segments: []Segment{{Pattern: "step", Config: stepAt(10), Scale: 2.5, For: 10 * time.Second}},
+				})
+				subject.Generate()
+			})
+
+			it("multiplies its requests", func() {
+				assert.InDelta(t, 250, len(envFake.Movements), 30)
+			})
+		})
+
+		describe("nesting composites", func() {
+			it.Before(func() {
+				inner, err := json.Marshal(CompositeConfig{Segments: []Segment{{Pattern: "step", Config: stepAt(10), For: 2 * time.Second}}})
+				assert.NoError(t, err)
+
+				subject = NewComposite(envFake, trafficSource, routingStock, CompositeConfig{
+					Segments: []Segment{{Pattern: "composite", Config: inner, After: 5 * time.Second}},
+				})
+				subject.Generate()
+			})
+
+			it("places the inner composite within the outer", func() {
+				assert.Len(t, envFake.Movements, 20)
+				assert.Equal(t, 20, countBetween(5*time.Second, 7*time.Second))
+			})
+		})
+
+		describe("segments without their own start time", func() {
+			it.Before(func() {
+				config, err := json.Marshal(UniformConfig{NumberOfRequests: 30})
+				assert.NoError(t, err)
+
+				subject = NewComposite(envFake, trafficSource, routingStock, CompositeConfig{
+					Segments: []Segment{{Pattern: "golang_rand_uniform", Config: config, After: 10 * time.Second, For: 3 * time.Second}},
+				})
+				subject.Generate()
+			})
+
+			it("runs them for the whole segment", func() {
+				assert.Len(t, envFake.Movements, 30)
+				assert.Equal(t, 30, countBetween(10*time.Second, 13*time.Second))
+			})
+		})
+	})
+
+	describe("NewComposite()", func() {
+		it("panics on unknown operators", func() {
+			assert.Panics(t, func() {
+				NewComposite(envFake, trafficSource, routingStock, CompositeConfig{Operator: "multiply"})
+			})
+		})
+
+		it("panics on unknown patterns, however deeply nested", func() {
+			inner, err := json.Marshal(CompositeConfig{Segments: []Segment{{Pattern: "bogus"}}})
+			assert.NoError(t, err)
+
+			assert.Panics(t, func() {
+				NewComposite(envFake, trafficSource, routingStock, CompositeConfig{Segments: []Segment{{Pattern: "composite", Config: inner}}})
+			})
+		})
+
+		it("panics on configs that do not fit the pattern", func() {
+			assert.Panics(t, func() {
+				NewComposite(envFake, trafficSource, routingStock, CompositeConfig{Segments: []Segment{{Pattern: "step", Config: json.RawMessage(`{"rps": "lots"}`)}}})
+			})
+		})
+	})
+}
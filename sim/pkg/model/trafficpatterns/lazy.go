@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"fmt"
+	"time"
+
+	"skenario/pkg/simulator"
+)
+
+// lazy generates an incremental pattern's traffic a window at a time. A "generate_traffic" movement
+// just before each window begins schedules that window's arrivals, so the schedule only ever holds
+// about one window of future traffic. Patterns that cannot generate incrementally are generated up front.
+type lazy struct {
+	env     simulator.Environment
+	pattern Pattern
+	window  time.Duration
+	startAt time.Time
+	windows int
+	stock   *trafficWindowStock
+}
+
+func (l *lazy) Name() string {
+	return l.pattern.Name()
+}
+
+func (l *lazy) Generate() {
+	incremental, ok := l.pattern.(IncrementalPattern)
+	if !ok {
+		l.pattern.Generate()
+		return
+	}
+
+	l.startAt = l.env.CurrentMovementTime()
+	incremental.GenerateUntil(l.startAt.Add(l.window))
+	l.scheduleNextWindow()
+}
+
+func (l *lazy) scheduleNextWindow() {
+	l.windows++
+	windowStart := l.startAt.Add(time.Duration(l.windows) * l.window)
+	if !windowStart.Before(l.env.HaltTime()) {
+		return
+	}
+
+	window := simulator.NewEntity(simulator.EntityName(fmt.Sprintf("traffic-window-%d", l.windows)), "TrafficWindow")
+	l.env.AddToSchedule(simulator.NewMovement(
+		"generate_traffic",
+		windowStart.Add(-1*time.Nanosecond),
+		l.stock,
+		l.stock,
+		&window,
+	))
+}
+
+func (l *lazy) generateNextWindow() {
+	l.pattern.(IncrementalPattern).GenerateUntil(l.startAt.Add(time.Duration(l.windows+1) * l.window))
+	l.scheduleNextWindow()
+}
+
+// trafficWindowStock generates the next window of traffic whenever a window moves through it.
+type trafficWindowStock struct {
+	lazy *lazy
+}
+
+func (tws *trafficWindowStock) Name() simulator.StockName {
+	return "TrafficGenerator"
+}
+
+func (tws *trafficWindowStock) KindStocked() simulator.EntityKind {
+	return "TrafficWindow"
+}
+
+func (tws *trafficWindowStock) Count() uint64 {
+	return 0
+}
+
+func (tws *trafficWindowStock) EntitiesInStock() []*simulator.Entity {
+	return []*simulator.Entity{}
+}
+
+func (tws *trafficWindowStock) Remove(entity *simulator.Entity) simulator.Entity {
+	if entity == nil {
+		return nil
+	}
+	return *entity
+}
+
+func (tws *trafficWindowStock) Add(entity simulator.Entity) error {
+	tws.lazy.generateNextWindow()
+	return nil
+}
+
+// NewLazy wraps a pattern so that it generates its traffic as the run reaches it, a window at a time.
+func NewLazy(env simulator.Environment, pattern Pattern, window time.Duration) Pattern {
+	if window <= 0 {
+		panic(fmt.Errorf("the traffic generation window must be positive, got %v", window))
+	}
+
+	l := &lazy{
+		env:     env,
+		pattern: pattern,
+		window:  window,
+	}
+	l.stock = &trafficWindowStock{lazy: l}
+	return l
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestLazy(t *testing.T) {
+	spec.Run(t, "Lazy traffic generation", testLazy, spec.Report(report.Terminal{}))
+}
+
+func testLazy(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	generateTrafficMovements := func() []simulator.Movement {
+		movements := make([]simulator.Movement, 0)
+		for _, mv := range envFake.Movements {
+			if mv.Kind() == "generate_traffic" {
+				movements = append(movements, mv)
+			}
+		}
+		return movements
+	}
+
+	// runWindows plays each "generate_traffic" movement in turn, as the environment would.
+	runWindows := func() {
+		for played := 0; ; played++ {
+			windows := generateTrafficMovements()
+			if played == len(windows) {
+				return
+			}
+
+			mv := windows[played]
+			envFake.TheTime = mv.OccursAt()
+			require.NoError(t, mv.To().Add(*mv.WhatToMove()))
+		}
+	}
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(10 * time.Second)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+	})
+
+	describe("Name()", func() {
+		it("uses the name of the pattern it wraps", func() {
+			subject = NewLazy(envFake, NewStep(envFake, trafficSource, routingStock, StepConfig{RPS: 1}), time.Second)
+			assert.Equal(t, "step", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		describe("an incremental pattern", func() {
+			it.Before(func() {
+				subject = NewLazy(envFake, NewStep(envFake, trafficSource, routingStock, StepConfig{RPS: 10}), time.Second)
+				subject.Generate()
+			})
+
+			it("only generates the first window up front", func() {
+				assert.Len(t, envFake.Movements, 10+1)
+			})
+
+			it("schedules the next window's generation just before it starts", func() {
+				windows := generateTrafficMovements()
+				require.Len(t, windows, 1)
+				assert.Equal(t, envFake.TheTime.Add(time.Second-time.Nanosecond), windows[0].OccursAt())
+				assert.Equal(t, simulator.StockName("TrafficGenerator"), windows[0].To().Name())
+			})
+
+			describe("as the run reaches each window", func() {
+				it.Before(func() {
+					runWindows()
+				})
+
+				it("generates the same traffic as generating it all up front", func() {
+					assert.Len(t, envFake.Movements, 100+9)
+				})
+
+				it("never schedules arrivals before the window is generated", func() {
+					generatedAt := envFake.TheTime.Add(-10 * time.Second)
+					for _, mv := range envFake.Movements {
+						if mv.Kind() == "generate_traffic" {
+							generatedAt = mv.OccursAt()
+							continue
+						}
+						assert.True(t, mv.OccursAt().After(generatedAt))
+					}
+				})
+			})
+		})
+
+		describe("a composite of incremental patterns", func() {
+			var config CompositeConfig
+			var upFront int
+
+			it.Before(func() {
+				config = CompositeConfig{Segments: []Segment{
+					{Pattern: "step", Config: []byte(`{"rps": 10, "step_after": 0}`)},
+					{Pattern: "step", Config: []byte(`{"rps": 5, "step_after": 0}`), After: 5 * time.Second},
+				}}
+
+				NewComposite(envFake, trafficSource, routingStock, config).Generate()
+				upFront = len(envFake.Movements)
+				envFake.Movements = nil
+
+				subject = NewLazy(envFake, NewComposite(envFake, trafficSource, routingStock, config), time.Second)
+				subject.Generate()
+			})
+
+			it("only generates the first window up front", func() {
+				assert.Len(t, envFake.Movements, 10+1)
+			})
+
+			it("generates the same traffic as generating it all up front", func() {
+				runWindows()
+				assert.Len(t, envFake.Movements, upFront+9)
+			})
+		})
+
+		describe("in a running environment", func() {
+			var arrivals, inThePast int
+
+			it.Before(func() {
+				var d dispatcher.Dispatcher = simulator.NewFakeDispatcher()
+				env := simulator.NewEnvironment(context.Background(), time.Unix(0, 0), 60*time.Second, &d)
+				routing := model.NewRequestsRoutingStock(env, model.NewReplicasActiveStock(env), simulator.NewSinkStock("Failed", "Request"))
+				source := model.NewTrafficSource(env, routing, model.RequestConfig{CPUTimeMillis: 1, IOTimeMillis: 1, Timeout: time.Second})
+
+				NewLazy(env, NewStep(env, source, routing, StepConfig{RPS: 50}), time.Second).Generate()
+				completed, ignored, err := env.Run()
+				require.NoError(t, err)
+
+				for _, c := range completed {
+					if c.Movement.Kind() == "arrive_at_routing_stock" {
+						arrivals++
+					}
+				}
+				for _, i := range ignored {
+					if i.Reason == simulator.OccursInPast {
+						inThePast++
+					}
+				}
+			})
+
+			it("delivers every second's arrivals", func() {
+				assert.Equal(t, 60*50, arrivals)
+				assert.Equal(t, 0, inThePast)
+			})
+		})
+
+		describe("a pattern which can only generate up front", func() {
+			it.Before(func() {
+				subject = NewLazy(envFake, NewClosedLoop(envFake, trafficSource, routingStock, ClosedLoopConfig{Users: []VirtualUsersStep{{Users: 2}}}), time.Second)
+				subject.Generate()
+			})
+
+			it("generates it up front", func() {
+				assert.Len(t, envFake.Movements, 2)
+				assert.Len(t, generateTrafficMovements(), 0)
+			})
+		})
+	})
+
+	describe("incremental patterns", func() {
+		var patterns map[string]IncrementalPattern
+
+		it.Before(func() {
+			patterns = map[string]IncrementalPattern{
+				"golang_rand_uniform": NewUniformRandom(envFake, trafficSource, routingStock, UniformConfig{NumberOfRequests: 50, StartAt: envFake.TheTime, RunFor: 10 * time.Second}).(IncrementalPattern),
+				"step":                NewStep(envFake, trafficSource, routingStock, StepConfig{RPS: 5}).(IncrementalPattern),
+				"ramp":                NewRamp(envFake, trafficSource, routingStock, RampConfig{DeltaV: 1, MaxRPS: 5}).(IncrementalPattern),
+				"sinusoidal":          NewSinusoidal(envFake, trafficSource, routingStock, SinusoidalConfig{Amplitude: 5, Period: 4 * time.Second}).(IncrementalPattern),
+				"replay":              NewReplay(envFake, trafficSource, routingStock, ReplayConfig{Format: ReplayFormatCSV, Data: "0\n1.5\n2.5\n9"}).(IncrementalPattern),
+				"renewal":             NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{Rate: []RatePoint{{RPS: 5}}}).(IncrementalPattern),
+				"mmpp":                NewMMPP(envFake, trafficSource, routingStock, MMPPConfig{States: []MMPPState{{RPS: 5}}}).(IncrementalPattern),
+				"pareto_on_off":       NewParetoOnOff(envFake, trafficSource, routingStock, ParetoOnOffConfig{Sources: 3, RPS: 5, MeanOn: time.Second, MeanOff: time.Second}).(IncrementalPattern),
+				"seasonal":            NewSeasonal(envFake, trafficSource, routingStock, SeasonalConfig{BaseRPS: 5}).(IncrementalPattern),
+				"composite": NewComposite(envFake, trafficSource, routingStock, CompositeConfig{Segments: []Segment{
+					{Pattern: "step", Config: []byte(`{"rps": 5, "step_after": 0}`)},
+					{Pattern: "renewal", Config: []byte(`{"rate": [{"rps": 5}]}`), After: 3 * time.Second, For: 4 * time.Second},
+				}}).(IncrementalPattern),
+			}
+		})
+
+		it("only schedule arrivals from where they left off", func() {
+			for name, pattern := range patterns {
+				envFake.Movements = nil
+				for i := 1; i <= 10; i++ {
+					until := envFake.TheTime.Add(time.Duration(i) * time.Second)
+					previous := len(envFake.Movements)
+					pattern.GenerateUntil(until)
+
+					for _, mv := range envFake.Movements[previous:] {
+						assert.True(t, mv.OccursAt().After(until.Add(-1*time.Second)), "%s scheduled %s while generating until %s", name, mv.OccursAt(), until)
+					}
+				}
+			}
+		})
+	})
+
+	describe("NewLazy()", func() {
+		it("panics without a window", func() {
+			assert.Panics(t, func() { NewLazy(envFake, NewNone(), 0) })
+		})
+	})
+}
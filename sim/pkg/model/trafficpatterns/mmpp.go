@@ -0,0 +1,206 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+// mmpp is a Markov-modulated Poisson process: it sends Poisson traffic at the rate of its current
+// state, stays for an exponentially distributed time, then jumps to another state.
+type mmpp struct {
+	env          simulator.Environment
+	source       model.TrafficSource
+	routingStock model.RequestsRoutingStock
+	states       []MMPPState
+	transitions  [][]float64
+	initialState int
+	started      bool
+	state        int
+	sojournEnd   time.Time
+	nextArrival  time.Time
+}
+
+type MMPPState struct {
+	Name        string        `json:"name,omitempty"`
+	RPS         float64       `json:"rps"`
+	MeanSojourn time.Duration `json:"mean_sojourn"` // the state is kept until the run ends when 0
+}
+
+type MMPPConfig struct {
+	States []MMPPState `json:"states"`
+	// Transitions[i][j] is the weight of jumping from state i to state j when leaving state i.
+	// Rows are normalised, and weights on the diagonal are ignored.
+	Transitions  [][]float64 `json:"transitions"`
+	InitialState int         `json:"initial_state,omitempty"`
+}
+
+func (*mmpp) Name() string {
+	return "mmpp"
+}
+
+func (m *mmpp) Generate() {
+	m.GenerateUntil(m.env.HaltTime())
+}
+
+func (m *mmpp) GenerateUntil(until time.Time) {
+	if !m.started {
+		m.started = true
+		m.state = m.initialState
+		m.enter(m.env.CurrentMovementTime())
+	}
+
+	for {
+		if m.nextArrival.Before(m.sojournEnd) {
+			if !m.nextArrival.Before(until) {
+				return
+			}
+
+			m.env.AddToSchedule(simulator.NewMovement(
+				"arrive_at_routing_stock",
+				m.nextArrival.Add(1*time.Nanosecond),
+				m.source,
+				m.routingStock,
+				nil,
+			))
+			m.nextArrival = m.nextArrival.Add(m.gap())
+			continue
+		}
+
+		if !m.sojournEnd.Before(until) || !m.sojournEnd.Before(m.env.HaltTime()) {
+			return
+		}
+		m.state = m.next(m.state)
+		m.enter(m.sojournEnd)
+	}
+}
+
+// enter starts a stay in the current state at the given time.
+func (m *mmpp) enter(at time.Time) {
+	m.sojournEnd = m.env.HaltTime()
+	if m.states[m.state].MeanSojourn > 0 {
+		sojourn := time.Duration(rand.ExpFloat64() * float64(m.states[m.state].MeanSojourn))
+		if at.Add(sojourn).Before(m.sojournEnd) {
+			m.sojournEnd = at.Add(sojourn)
+		}
+	}
+
+	m.nextArrival = at.Add(m.gap())
+}
+
+// gap is the time to the next arrival in the current state, or forever when it sends nothing.
+func (m *mmpp) gap() time.Duration {
+	rps := m.states[m.state].RPS
+	if rps <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(rand.ExpFloat64() * float64(time.Second) / rps)
+}
+
+func (m *mmpp) next(state int) int {
+	var total float64
+	for j, weight := range m.transitions[state] {
+		if j != state {
+			total += weight
+		}
+	}
+	if total == 0 {
+		return state
+	}
+
+	pick := rand.Float64() * total
+	for j, weight := range m.transitions[state] {
+		if j == state {
+			continue
+		}
+		if pick < weight {
+			return j
+		}
+		pick -= weight
+	}
+	return state
+}
+
+func init() {
+	Register(Registration{
+		Name:        "mmpp",
+		Description: "A Markov-modulated Poisson process that jumps between states with their own rates.",
+		Config: func() interface{} {
+			return &MMPPConfig{}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewMMPP(w.Env, w.Source, w.RoutingStock, *config.(*MMPPConfig))
+		},
+	})
+}
+
+func (c MMPPConfig) Validate() error {
+	if len(c.States) == 0 {
+		return fmt.Errorf("an MMPP needs at least one state")
+	}
+	if c.InitialState < 0 || c.InitialState >= len(c.States) {
+		return fmt.Errorf("initial state %d is not one of the %d states", c.InitialState, len(c.States))
+	}
+
+	if len(c.Transitions) != 0 && len(c.Transitions) != len(c.States) {
+		return fmt.Errorf("the transitions need a row for each of the %d states, got %d", len(c.States), len(c.Transitions))
+	}
+	for i, row := range c.Transitions {
+		if len(row) != len(c.States) {
+			return fmt.Errorf("transitions row %d needs a weight for each of the %d states, got %d", i, len(c.States), len(row))
+		}
+		for _, weight := range row {
+			if weight < 0 {
+				return fmt.Errorf("transition weights must not be negative, got %f in row %d", weight, i)
+			}
+		}
+	}
+	for _, s := range c.States {
+		if s.RPS < 0 || s.MeanSojourn < 0 {
+			return fmt.Errorf("state rates and sojourns must not be negative, got %v", s)
+		}
+	}
+	return nil
+}
+
+func NewMMPP(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config MMPPConfig) Pattern {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	transitions := config.Transitions
+	if len(transitions) == 0 {
+		transitions = make([][]float64, len(config.States))
+		for i := range transitions {
+			transitions[i] = make([]float64, len(config.States))
+		}
+	}
+
+	return &mmpp{
+		env:          env,
+		source:       source,
+		routingStock: routingStock,
+		states:       config.States,
+		transitions:  transitions,
+		initialState: config.InitialState,
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestMMPP(t *testing.T) {
+	spec.Run(t, "MMPP traffic pattern", testMMPP, spec.Report(report.Terminal{}))
+}
+
+func testMMPP(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(100 * time.Second)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'mmpp'", func() {
+			subject = NewMMPP(envFake, trafficSource, routingStock, MMPPConfig{States: []MMPPState{{RPS: 1}}})
+			assert.Equal(t, "mmpp", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		describe("a single state", func() {
+			it.Before(func() {
+				subject = NewMMPP(envFake, trafficSource, routingStock, MMPPConfig{States: []MMPPState{{RPS: 20}}})
+				subject.Generate()
+			})
+
+			it("is a Poisson process at that state's rate", func() {
+				assert.InDelta(t, 2000, len(envFake.Movements), 200)
+			})
+
+			it("sends requests to the routing stock within the run", func() {
+				for _, mv := range envFake.Movements {
+					assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), mv.Kind())
+					assert.True(t, mv.OccursAt().After(envFake.TheTime))
+					assert.True(t, mv.OccursAt().Before(envFake.TheHaltTime.Add(1*time.Nanosecond)))
+				}
+			})
+		})
+
+		describe("an idle state that jumps to an absorbing busy state", func() {
+			it.Before(func() {
+				subject = NewMMPP(envFake, trafficSource, routingStock, MMPPConfig{
+					States: []MMPPState{
+						{Name: "idle", RPS: 0, MeanSojourn: 10 * time.Second},
+						{Name: "busy", RPS: 50},
+					},
+					Transitions: [][]float64{{0, 1}, {1, 0}},
+				})
+				subject.Generate()
+			})
+
+			it("sends nothing until it leaves the idle state, then stays busy", func() {
+				first := envFake.Movements[0].OccursAt().Sub(envFake.TheTime)
+				expected := float64(envFake.TheHaltTime.Sub(envFake.TheTime)-first) / float64(time.Second) * 50
+				assert.InDelta(t, expected, len(envFake.Movements), expected*0.2+20)
+			})
+		})
+
+		describe("alternating states", func() {
+			it.Before(func() {
+				envFake.TheHaltTime = envFake.TheTime.Add(5000 * time.Second)
+				subject = NewMMPP(envFake, trafficSource, routingStock, MMPPConfig{
+					States: []MMPPState{
+						{RPS: 0, MeanSojourn: 5 * time.Second},
+						{RPS: 10, MeanSojourn: 5 * time.Second},
+					},
+					Transitions: [][]float64{{0, 1}, {1, 0}},
+				})
+				subject.Generate()
+			})
+
+			it("averages the rates by the time spent in each state", func() {
+				assert.InDelta(t, 25000, len(envFake.Movements), 5000)
+			})
+		})
+	})
+
+	describe("NewMMPP()", func() {
+		it("panics without states", func() {
+			assert.Panics(t, func() {
+				NewMMPP(envFake, trafficSource, routingStock, MMPPConfig{})
+			})
+		})
+
+		it("panics when the transitions do not match the states", func() {
+			assert.Panics(t, func() {
+				NewMMPP(envFake, trafficSource, routingStock, MMPPConfig{States: []MMPPState{{RPS: 1}, {RPS: 2}}, Transitions: [][]float64{{0, 1}}})
+			})
+		})
+
+		it("panics on an initial state that does not exist", func() {
+			assert.Panics(t, func() {
+				NewMMPP(envFake, trafficSource, routingStock, MMPPConfig{States: []MMPPState{{RPS: 1}}, InitialState: 1})
+			})
+		})
+	})
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+)
+
+func TestNone(t *testing.T) {
+	spec.Run(t, "None traffic pattern", testNone, spec.Report(report.Terminal{}))
+}
+
+func testNone(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var envFake *model.FakeEnvironment
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheHaltTime = envFake.TheTime.Add(20 * time.Second)
+		subject = NewNone()
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'none'", func() {
+			assert.Equal(t, "none", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		it("schedules nothing", func() {
+			subject.Generate()
+			assert.Empty(t, envFake.Movements)
+		})
+	})
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+// paretoOnOff adds up sources that alternate between sending Poisson traffic and staying quiet,
+// for heavy-tailed lengths of time. Together they give self-similar load, with a Hurst
+// parameter of (3 - Shape) / 2.
+type paretoOnOff struct {
+	env          simulator.Environment
+	source       model.TrafficSource
+	routingStock model.RequestsRoutingStock
+	sources      int
+	rps          float64
+	meanOn       time.Duration
+	meanOff      time.Duration
+	onPeriods    model.Distribution
+	offPeriods   model.Distribution
+	states       []onOffSource
+}
+
+type onOffSource struct {
+	on          bool
+	periodEnd   time.Time
+	nextArrival time.Time
+}
+
+type ParetoOnOffConfig struct {
+	Sources int           `json:"sources"`
+	RPS     float64       `json:"rps"` // sent by each source while on
+	MeanOn  time.Duration `json:"mean_on"`
+	MeanOff time.Duration `json:"mean_off"`
+	Shape   float64       `json:"shape,omitempty"` // between 1 and 2 for self-similar load; defaults to 1.5
+}
+
+func (*paretoOnOff) Name() string {
+	return "pareto_on_off"
+}
+
+func (po *paretoOnOff) Generate() {
+	po.GenerateUntil(po.env.HaltTime())
+}
+
+func (po *paretoOnOff) GenerateUntil(until time.Time) {
+	if po.states == nil {
+		startAt := po.env.CurrentMovementTime()
+		onShare := float64(po.meanOn) / float64(po.meanOn+po.meanOff)
+
+		po.states = make([]onOffSource, po.sources)
+		for i := range po.states {
+			po.states[i].on = rand.Float64() >= onShare // flipped on entering the first period
+			po.enter(&po.states[i], startAt)
+		}
+	}
+
+	for i := range po.states {
+		s := &po.states[i]
+		for {
+			if s.on && s.nextArrival.Before(s.periodEnd) {
+				if !s.nextArrival.Before(until) {
+					break
+				}
+
+				po.env.AddToSchedule(simulator.NewMovement(
+					"arrive_at_routing_stock",
+					s.nextArrival.Add(1*time.Nanosecond),
+					po.source,
+					po.routingStock,
+					nil,
+				))
+				s.nextArrival = s.nextArrival.Add(po.gap())
+				continue
+			}
+
+			if !s.periodEnd.Before(until) || !s.periodEnd.Before(po.env.HaltTime()) {
+				break
+			}
+			po.enter(s, s.periodEnd)
+		}
+	}
+}
+
+// enter switches the source on or off for its next period, starting at the given time.
+func (po *paretoOnOff) enter(s *onOffSource, at time.Time) {
+	s.on = !s.on
+	if s.on {
+		s.periodEnd = at.Add(po.onPeriods.Sample())
+		s.nextArrival = at.Add(po.gap())
+	} else {
+		s.periodEnd = at.Add(po.offPeriods.Sample())
+	}
+}
+
+func (po *paretoOnOff) gap() time.Duration {
+	if po.rps <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(rand.ExpFloat64() * float64(time.Second) / po.rps)
+}
+
+func init() {
+	Register(Registration{
+		Name:        "pareto_on_off",
+		Description: "Sources that switch on and off for Pareto-distributed periods, sending Poisson traffic while on.",
+		Config: func() interface{} {
+			return &ParetoOnOffConfig{Shape: 1.5}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewParetoOnOff(w.Env, w.Source, w.RoutingStock, *config.(*ParetoOnOffConfig))
+		},
+	})
+}
+
+func (c ParetoOnOffConfig) Validate() error {
+	if c.Sources < 0 || c.RPS < 0 {
+		return fmt.Errorf("sources and rps must not be negative, got %d and %f", c.Sources, c.RPS)
+	}
+	if c.MeanOn <= 0 || c.MeanOff <= 0 {
+		return fmt.Errorf("mean on and off periods must be positive, got %v and %v", c.MeanOn, c.MeanOff)
+	}
+	if c.Shape != 0 && c.Shape <= 1 {
+		return fmt.Errorf("the shape must be greater than 1 for the periods to have a mean, got %f", c.Shape)
+	}
+	return nil
+}
+
+func NewParetoOnOff(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config ParetoOnOffConfig) Pattern {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	shape := config.Shape
+	if shape == 0 {
+		shape = 1.5
+	}
+
+	return &paretoOnOff{
+		env:          env,
+		source:       source,
+		routingStock: routingStock,
+		sources:      config.Sources,
+		rps:          config.RPS,
+		meanOn:       config.MeanOn,
+		meanOff:      config.MeanOff,
+		onPeriods:    model.NewDistribution(model.DistributionConfig{Kind: model.DistributionPareto, Mean: config.MeanOn, Shape: shape}),
+		offPeriods:   model.NewDistribution(model.DistributionConfig{Kind: model.DistributionPareto, Mean: config.MeanOff, Shape: shape}),
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestParetoOnOff(t *testing.T) {
+	spec.Run(t, "Pareto on/off traffic pattern", testParetoOnOff, spec.Report(report.Terminal{}))
+}
+
+func testParetoOnOff(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var config ParetoOnOffConfig
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(1000 * time.Second)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+
+		config = ParetoOnOffConfig{
+			Sources: 20,
+			RPS:     2,
+			MeanOn:  2 * time.Second,
+			MeanOff: 6 * time.Second,
+			Shape:   1.9,
+		}
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'pareto_on_off'", func() {
+			subject = NewParetoOnOff(envFake, trafficSource, routingStock, config)
+			assert.Equal(t, "pareto_on_off", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		it.Before(func() {
+			subject = NewParetoOnOff(envFake, trafficSource, routingStock, config)
+			subject.Generate()
+		})
+
+		it("sends roughly sources * rps * the share of time spent on", func() {
+			// 20 sources * 2 RPS * 1/4 of the time * 1000s
+			assert.InDelta(t, 10000, len(envFake.Movements), 3000)
+		})
+
+		it("keeps requests within the run", func() {
+			for _, mv := range envFake.Movements {
+				assert.True(t, mv.OccursAt().After(envFake.TheTime))
+				assert.True(t, mv.OccursAt().Before(envFake.TheHaltTime.Add(1*time.Nanosecond)))
+			}
+		})
+
+		it("has quiet seconds and busy seconds", func() {
+			perSecond := make([]int, 1000)
+			for _, mv := range envFake.Movements {
+				perSecond[int(mv.OccursAt().Sub(envFake.TheTime)/time.Second)%1000]++
+			}
+
+			min, max := perSecond[0], perSecond[0]
+			for _, count := range perSecond {
+				if count < min {
+					min = count
+				}
+				if count > max {
+					max = count
+				}
+			}
+			assert.True(t, max-min > 10)
+		})
+	})
+
+	describe("NewParetoOnOff()", func() {
+		it("panics without on and off periods", func() {
+			assert.Panics(t, func() {
+				NewParetoOnOff(envFake, trafficSource, routingStock, ParetoOnOffConfig{Sources: 1, RPS: 1})
+			})
+		})
+
+		it("panics on a shape that leaves the periods without a mean", func() {
+			config.Shape = 0.5
+			assert.Panics(t, func() {
+				NewParetoOnOff(envFake, trafficSource, routingStock, config)
+			})
+		})
+	})
+}
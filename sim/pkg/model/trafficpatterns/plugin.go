@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+// pluginPattern asks an out-of-process traffic generator for its arrivals, a piece at a time.
+type pluginPattern struct {
+	env          simulator.Environment
+	source       model.TrafficSource
+	routingStock model.RequestsRoutingStock
+	generator    skplug.TrafficGenerator
+	partition    string
+	config       PluginConfig
+	started      bool
+	done         bool
+}
+
+type PluginConfig struct {
+	// Generator is the type reported by one of the traffic generator plugins the server was started with.
+	Generator string `json:"generator"`
+	// Config is passed through to the generator unchanged.
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+var trafficPartitionSequence int32 = 0
+
+func (*pluginPattern) Name() string {
+	return "plugin"
+}
+
+func (p *pluginPattern) Generate() {
+	p.GenerateUntil(p.env.HaltTime())
+}
+
+func (p *pluginPattern) GenerateUntil(until time.Time) {
+	if p.done {
+		return
+	}
+
+	now := p.env.CurrentMovementTime()
+	if !p.started {
+		p.started = true
+		err := p.generator.Init(p.partition, now.UnixNano(), p.env.HaltTime().UnixNano(), string(p.config.Config))
+		if err != nil {
+			panic(fmt.Errorf("traffic generator '%s' could not start: %s", p.config.Generator, err.Error()))
+		}
+	}
+
+	arrivals, done, err := p.generator.Generate(p.partition, until.UnixNano())
+	if err != nil {
+		panic(fmt.Errorf("traffic generator '%s' failed: %s", p.config.Generator, err.Error()))
+	}
+
+	for _, arrival := range arrivals {
+		at := time.Unix(0, arrival.TimeNanos).Add(1 * time.Nanosecond)
+		if !at.After(now) || !at.Before(p.env.HaltTime()) {
+			continue
+		}
+
+		var entity *simulator.Entity
+		overrides := model.RequestOverrides{
+			CPUTimeMillis: int(arrival.CpuTimeMillis),
+			IOTimeMillis:  int(arrival.IoTimeMillis),
+			Class:         arrival.Class,
+		}
+		if overrides != (model.RequestOverrides{}) {
+			var e simulator.Entity = p.source.RequestWith(overrides)
+			entity = &e
+		}
+
+		p.env.AddToSchedule(simulator.NewMovement("arrive_at_routing_stock", at, p.source, p.routingStock, entity))
+	}
+
+	if done || !until.Before(p.env.HaltTime()) {
+		p.done = true
+		err = p.generator.Finish(p.partition)
+		if err != nil {
+			panic(fmt.Errorf("traffic generator '%s' could not finish: %s", p.config.Generator, err.Error()))
+		}
+	}
+}
+
+func init() {
+	Register(Registration{
+		Name:        "plugin",
+		Description: "Traffic from an out-of-process traffic generator plugin.",
+		Config: func() interface{} {
+			return &PluginConfig{}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			c := *config.(*PluginConfig)
+			var generator skplug.TrafficGenerator
+			if w.TrafficGenerator != nil {
+				generator = w.TrafficGenerator(c.Generator)
+			}
+			return NewPlugin(w.Env, w.Source, w.RoutingStock, generator, c)
+		},
+	})
+}
+
+func NewPlugin(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, generator skplug.TrafficGenerator, config PluginConfig) Pattern {
+	if generator == nil {
+		panic(fmt.Errorf("there is no traffic generator plugin of type '%s'", config.Generator))
+	}
+
+	return &pluginPattern{
+		env:          env,
+		source:       source,
+		routingStock: routingStock,
+		generator:    generator,
+		partition:    "traffic-" + strconv.Itoa(int(atomic.AddInt32(&trafficPartitionSequence, 1))),
+		config:       config,
+	}
+}
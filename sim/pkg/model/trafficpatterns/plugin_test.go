@@ -0,0 +1,207 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+// fakeGenerator sends one request a second, the first with a class, and records how it was called.
+type fakeGenerator struct {
+	partition string
+	startTime int64
+	haltTime  int64
+	config    string
+	untils    []int64
+	next      int64
+	finished  bool
+	err       error
+}
+
+func (fg *fakeGenerator) Init(partition string, startTime int64, haltTime int64, config string) error {
+	fg.partition = partition
+	fg.startTime = startTime
+	fg.haltTime = haltTime
+	fg.config = config
+	fg.next = startTime
+	return fg.err
+}
+
+func (fg *fakeGenerator) Generate(partition string, until int64) (arrivals []*proto.Arrival, done bool, err error) {
+	fg.untils = append(fg.untils, until)
+	for ; fg.next < until && fg.next < fg.haltTime; fg.next += int64(time.Second) {
+		arrival := &proto.Arrival{TimeNanos: fg.next}
+		if fg.next == fg.startTime {
+			arrival.Class = "first"
+		}
+		arrivals = append(arrivals, arrival)
+	}
+	return arrivals, fg.next >= fg.haltTime, nil
+}
+
+func (fg *fakeGenerator) Finish(partition string) error {
+	fg.finished = true
+	return nil
+}
+
+func (fg *fakeGenerator) GeneratorType() (rec string, err error) {
+	return "fake", nil
+}
+
+func TestPlugin(t *testing.T) {
+	spec.Run(t, "Plugin traffic pattern", testPlugin, spec.Report(report.Terminal{}))
+}
+
+func testPlugin(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var generator *fakeGenerator
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(10 * time.Second)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+		generator = new(fakeGenerator)
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'plugin'", func() {
+			subject = NewPlugin(envFake, trafficSource, routingStock, generator, PluginConfig{Generator: "fake"})
+			assert.Equal(t, "plugin", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		it.Before(func() {
+			subject = NewPlugin(envFake, trafficSource, routingStock, generator, PluginConfig{
+				Generator: "fake",
+				Config:    json.RawMessage(`{"users":10}`),
+			})
+			subject.Generate()
+		})
+
+		it("gives the generator the scenario window and its config", func() {
+			assert.NotEmpty(t, generator.partition)
+			assert.Equal(t, envFake.TheTime.UnixNano(), generator.startTime)
+			assert.Equal(t, envFake.TheHaltTime.UnixNano(), generator.haltTime)
+			assert.Equal(t, `{"users":10}`, generator.config)
+		})
+
+		it("asks for the whole run at once", func() {
+			assert.Equal(t, []int64{envFake.TheHaltTime.UnixNano()}, generator.untils)
+		})
+
+		it("schedules an arrival for each arrival the generator returned", func() {
+			assert.Len(t, envFake.Movements, 10)
+			for i, mv := range envFake.Movements {
+				assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), mv.Kind())
+				assert.Equal(t, envFake.TheTime.Add(time.Duration(i)*time.Second).Add(1*time.Nanosecond), mv.OccursAt())
+			}
+		})
+
+		it("only creates requests up front for arrivals that override the request", func() {
+			assert.NotNil(t, envFake.Movements[0].WhatToMove())
+			assert.Nil(t, envFake.Movements[1].WhatToMove())
+		})
+
+		it("carries the class of each arrival onto its request", func() {
+			classed := (*envFake.Movements[0].WhatToMove()).Name()
+			assert.Equal(t, map[simulator.EntityName]string{classed: "first"}, trafficSource.RequestClasses())
+		})
+
+		it("finishes the generator", func() {
+			assert.True(t, generator.finished)
+		})
+	})
+
+	describe("GenerateUntil()", func() {
+		it.Before(func() {
+			subject = NewPlugin(envFake, trafficSource, routingStock, generator, PluginConfig{Generator: "fake"})
+			subject.(IncrementalPattern).GenerateUntil(envFake.TheTime.Add(3 * time.Second))
+		})
+
+		it("only asks the generator for the window", func() {
+			assert.Len(t, envFake.Movements, 3)
+			assert.False(t, generator.finished)
+		})
+
+		describe("later windows", func() {
+			it.Before(func() {
+				envFake.TheTime = envFake.TheTime.Add(3 * time.Second)
+				subject.(IncrementalPattern).GenerateUntil(envFake.TheTime.Add(20 * time.Second))
+			})
+
+			it("carries on from where the generator left off", func() {
+				assert.Len(t, envFake.Movements, 10)
+				assert.Len(t, generator.untils, 2)
+			})
+
+			it("does not initialise the generator again", func() {
+				assert.Equal(t, time.Unix(0, 0).UnixNano(), generator.startTime)
+			})
+
+			it("finishes the generator once it is done", func() {
+				assert.True(t, generator.finished)
+			})
+
+			it("does not ask for more once it is done", func() {
+				subject.(IncrementalPattern).GenerateUntil(envFake.TheHaltTime)
+				assert.Len(t, generator.untils, 2)
+			})
+		})
+	})
+
+	describe("NewPlugin()", func() {
+		it("panics when there is no generator", func() {
+			assert.Panics(t, func() {
+				NewPlugin(envFake, trafficSource, routingStock, nil, PluginConfig{Generator: "missing"})
+			})
+		})
+
+		it("gives each pattern its own partition", func() {
+			other := new(fakeGenerator)
+			NewPlugin(envFake, trafficSource, routingStock, generator, PluginConfig{}).Generate()
+			NewPlugin(envFake, trafficSource, routingStock, other, PluginConfig{}).Generate()
+			assert.NotEqual(t, generator.partition, other.partition)
+		})
+	})
+
+	describe("when the generator fails", func() {
+		it("panics", func() {
+			generator.err = fmt.Errorf("no such workload")
+			subject = NewPlugin(envFake, trafficSource, routingStock, generator, PluginConfig{Generator: "fake"})
+			assert.Panics(t, func() {
+				subject.Generate()
+			})
+		})
+	})
+}
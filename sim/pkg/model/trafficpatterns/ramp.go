@@ -29,6 +29,10 @@ type ramp struct {
 	sink         model.RequestsProcessingStock
 	deltaV       float64
 	maxRPS       int
+	started      bool
+	next         time.Time
+	nextRPS      float64
+	falling      bool
 }
 
 type RampConfig struct {
@@ -41,31 +45,55 @@ func (*ramp) Name() string {
 }
 
 func (r *ramp) Generate() {
-	var t time.Time
-	nextRPS := r.deltaV
-	startAt := r.env.CurrentMovementTime()
+	r.GenerateUntil(r.env.HaltTime())
+}
 
-	for t = startAt; int(nextRPS) <= r.maxRPS; t = t.Add(1 * time.Second) {
-		uniRand := NewUniformRandom(r.env, r.source, r.routingStock, UniformConfig{
-			NumberOfRequests: int(nextRPS),
-			StartAt:          t,
-			RunFor:           time.Second,
-		})
-		uniRand.Generate()
-		nextRPS = nextRPS + r.deltaV
+// GenerateUntil ramps up by deltaV each second until maxRPS, then back down to zero.
+func (r *ramp) GenerateUntil(until time.Time) {
+	if !r.started {
+		r.started = true
+		r.next = r.env.CurrentMovementTime()
+		r.nextRPS = r.deltaV
 	}
 
-	for ; nextRPS > 0; t = t.Add(1 * time.Second) {
-		nextRPS = nextRPS - r.deltaV
+	for ; r.next.Before(until); r.next = r.next.Add(1 * time.Second) {
+		if !r.falling && int(r.nextRPS) > r.maxRPS {
+			r.falling = true
+		}
+
+		if r.falling {
+			if r.nextRPS <= 0 {
+				return
+			}
+			r.nextRPS = r.nextRPS - r.deltaV
+		}
+
 		uniRand := NewUniformRandom(r.env, r.source, r.routingStock, UniformConfig{
-			NumberOfRequests: int(nextRPS),
-			StartAt:          t,
+			NumberOfRequests: int(r.nextRPS),
+			StartAt:          r.next,
 			RunFor:           time.Second,
 		})
 		uniRand.Generate()
+
+		if !r.falling {
+			r.nextRPS = r.nextRPS + r.deltaV
+		}
 	}
 }
 
+func init() {
+	Register(Registration{
+		Name:        "ramp",
+		Description: "Ramps the rate up by delta_v each second to max_rps, then back down to zero.",
+		Config: func() interface{} {
+			return &RampConfig{DeltaV: 1, MaxRPS: 50}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewRamp(w.Env, w.Source, w.RoutingStock, *config.(*RampConfig))
+		},
+	})
+}
+
 func NewRamp(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config RampConfig) Pattern {
 	return &ramp{
 		env:          env,
@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+// Registration describes a traffic pattern that runs can ask for by name.
+type Registration struct {
+	Name        string
+	Description string
+	// Config returns a pointer to the pattern's config holding its defaults, which requests are read over.
+	// It is nil for patterns that take no config.
+	Config func() interface{}
+	// New builds the pattern from a config returned by Config.
+	New func(w Wiring, config interface{}) Pattern
+}
+
+// Wiring is what a pattern is built against.
+type Wiring struct {
+	Env          simulator.Environment
+	Source       model.TrafficSource
+	RoutingStock model.RequestsRoutingStock
+	// TrafficGenerator finds an out-of-process traffic generator by type, or returns nil. It may be nil.
+	TrafficGenerator func(generatorType string) skplug.TrafficGenerator
+}
+
+// PatternDescription is how a registered pattern describes itself to clients.
+type PatternDescription struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Config      *ConfigSchema `json:"config,omitempty"`
+}
+
+// ConfigSchema describes a config or one of its fields. Durations are in nanoseconds and times are RFC3339.
+type ConfigSchema struct {
+	Name    string         `json:"name,omitempty"`
+	Type    string         `json:"type"` // object, array, string, integer, number, boolean, duration, time or json
+	Default interface{}    `json:"default,omitempty"`
+	Fields  []ConfigSchema `json:"fields,omitempty"` // of an object
+	Items   *ConfigSchema  `json:"items,omitempty"`  // of an array
+}
+
+type validator interface {
+	Validate() error
+}
+
+var registry = make(map[string]Registration)
+
+// Register makes a pattern available by name. Panics if the name is taken.
+func Register(r Registration) {
+	if _, ok := registry[r.Name]; ok {
+		panic(fmt.Errorf("traffic pattern '%s' is already registered", r.Name))
+	}
+	registry[r.Name] = r
+}
+
+// Registrations lists the registered patterns by name.
+func Registrations() []Registration {
+	registrations := make([]Registration, 0, len(registry))
+	for _, r := range registry {
+		registrations = append(registrations, r)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].Name < registrations[j].Name
+	})
+	return registrations
+}
+
+// ReadConfig reads a pattern's config over its defaults and validates it.
+func ReadConfig(name string, raw json.RawMessage) (interface{}, error) {
+	r, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown traffic pattern '%s'", name)
+	}
+	if r.Config == nil {
+		return nil, nil
+	}
+
+	config := r.Config()
+	if len(raw) > 0 {
+		err := json.Unmarshal(raw, config)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the config of '%s': %s", name, err.Error())
+		}
+	}
+
+	if v, ok := config.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// Build reads a pattern's config and builds the pattern. Panics if the pattern is unknown or its config is not valid.
+func Build(w Wiring, name string, raw json.RawMessage) Pattern {
+	config, err := ReadConfig(name, raw)
+	if err != nil {
+		panic(err)
+	}
+	return registry[name].New(w, config)
+}
+
+// Describe lists the registered patterns with the schemas and defaults of their configs.
+func Describe() []PatternDescription {
+	descriptions := make([]PatternDescription, 0, len(registry))
+	for _, r := range Registrations() {
+		description := PatternDescription{Name: r.Name, Description: r.Description}
+		if r.Config != nil {
+			schema := describeValue(reflect.ValueOf(r.Config()).Elem())
+			description.Config = &schema
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions
+}
+
+var (
+	durationType   = reflect.TypeOf(time.Duration(0))
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func describeValue(v reflect.Value) ConfigSchema {
+	schema := ConfigSchema{}
+	switch t := v.Type(); {
+	case t == durationType:
+		schema.Type = "duration"
+	case t == timeType:
+		schema.Type = "time"
+	case t == rawMessageType:
+		schema.Type = "json"
+	case t.Kind() == reflect.Struct:
+		schema.Type = "object"
+		for i := 0; i < t.NumField(); i++ {
+			name := jsonName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			field := describeValue(v.Field(i))
+			field.Name = name
+			schema.Fields = append(schema.Fields, field)
+		}
+		return schema
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		schema.Type = "array"
+		items := describeValue(reflect.New(t.Elem()).Elem())
+		schema.Items = &items
+	case t.Kind() == reflect.String:
+		schema.Type = "string"
+	case t.Kind() == reflect.Bool:
+		schema.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema.Type = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema.Type = "number"
+	default:
+		panic(fmt.Errorf("cannot describe config values of type %s", t))
+	}
+
+	if !v.IsZero() {
+		schema.Default = v.Interface()
+	}
+	return schema
+}
+
+func jsonName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
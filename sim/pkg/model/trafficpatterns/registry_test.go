@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestRegistry(t *testing.T) {
+	spec.Run(t, "Traffic pattern registry", testRegistry, spec.Report(report.Terminal{}))
+}
+
+func testRegistry(t *testing.T, describe spec.G, it spec.S) {
+	var envFake *model.FakeEnvironment
+	var wiring Wiring
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(100 * time.Second)
+
+		routingStock := model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		wiring = Wiring{
+			Env:          envFake,
+			Source:       model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second}),
+			RoutingStock: routingStock,
+		}
+	})
+
+	describe("Registrations()", func() {
+		it("has every pattern, sorted by name", func() {
+			names := make([]string, 0)
+			for _, r := range Registrations() {
+				names = append(names, r.Name)
+			}
+			assert.Equal(t, []string{
+				"closed_loop", "composite", "golang_rand_uniform", "mmpp", "none", "pareto_on_off", "plugin",
+				"poisson", "ramp", "renewal", "replay", "seasonal", "sinusoidal", "spike", "step",
+			}, names)
+		})
+
+		it("builds patterns that call themselves by their registered names", func() {
+			wiring.TrafficGenerator = func(generatorType string) skplug.TrafficGenerator {
+				return new(fakeGenerator)
+			}
+			for _, r := range Registrations() {
+				if r.Config != nil {
+					if _, ok := r.Config().(validator); ok {
+						continue // these need more config than their defaults
+					}
+				}
+				assert.Equal(t, r.Name, Build(wiring, r.Name, nil).Name())
+			}
+		})
+	})
+
+	describe("Register()", func() {
+		it("panics when the name is taken", func() {
+			assert.Panics(t, func() {
+				Register(Registration{Name: "step"})
+			})
+		})
+	})
+
+	describe("ReadConfig()", func() {
+		it("uses the defaults when there is no config", func() {
+			config, err := ReadConfig("step", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, &StepConfig{RPS: 10, StepAfter: 10 * time.Second}, config)
+		})
+
+		it("reads the config over the defaults", func() {
+			config, err := ReadConfig("step", json.RawMessage(`{"rps": 20}`))
+			assert.NoError(t, err)
+			assert.Equal(t, &StepConfig{RPS: 20, StepAfter: 10 * time.Second}, config)
+		})
+
+		it("validates the config", func() {
+			_, err := ReadConfig("pareto_on_off", json.RawMessage(`{"sources": -1}`))
+			assert.Error(t, err)
+		})
+
+		it("returns distribution errors rather than panicking", func() {
+			_, err := ReadConfig("renewal", json.RawMessage(`{"inter_arrival": "pareto", "shape": 0.5}`))
+			assert.Error(t, err)
+			_, err = ReadConfig("renewal", json.RawMessage(`{"inter_arrival": "hyperexponential", "shape": 0.5}`))
+			assert.Error(t, err)
+			_, err = ReadConfig("renewal", json.RawMessage(`{"inter_arrival": "erlang", "shape": 1.5}`))
+			assert.Error(t, err)
+		})
+
+		it("validates the segments of composites", func() {
+			_, err := ReadConfig("composite", json.RawMessage(`{"segments": [{"pattern": "seasonal", "config": {"weekly": [1]}}]}`))
+			assert.Error(t, err)
+		})
+
+		it("returns an error for configs it cannot read", func() {
+			_, err := ReadConfig("step", json.RawMessage(`{"rps": "lots"}`))
+			assert.Error(t, err)
+		})
+
+		it("returns an error for unknown patterns", func() {
+			_, err := ReadConfig("fractal", nil)
+			assert.Error(t, err)
+		})
+
+		it("has no config for patterns that take none", func() {
+			config, err := ReadConfig("none", json.RawMessage(`{}`))
+			assert.NoError(t, err)
+			assert.Nil(t, config)
+		})
+	})
+
+	describe("Build()", func() {
+		it("builds the pattern from its config", func() {
+			Build(wiring, "step", json.RawMessage(`{"rps": 2, "step_after": 0}`)).Generate()
+			assert.Len(t, envFake.Movements, 200)
+		})
+
+		it("spreads uniform requests over the whole run by default", func() {
+			Build(wiring, "golang_rand_uniform", nil).Generate()
+			assert.Len(t, envFake.Movements, 100)
+		})
+
+		it("panics for unknown patterns", func() {
+			assert.Panics(t, func() {
+				Build(wiring, "fractal", nil)
+			})
+		})
+
+		it("panics for configs that are not valid", func() {
+			assert.Panics(t, func() {
+				Build(wiring, "mmpp", json.RawMessage(`{"states": []}`))
+			})
+		})
+	})
+
+	describe("Describe()", func() {
+		var descriptions map[string]PatternDescription
+
+		it.Before(func() {
+			descriptions = make(map[string]PatternDescription)
+			for _, d := range Describe() {
+				descriptions[d.Name] = d
+			}
+		})
+
+		it("describes every pattern", func() {
+			assert.Len(t, descriptions, len(Registrations()))
+			for _, d := range descriptions {
+				assert.NotEmpty(t, d.Description)
+			}
+		})
+
+		it("has no config for patterns that take none", func() {
+			assert.Nil(t, descriptions["none"].Config)
+		})
+
+		it("describes fields by their JSON names, types and defaults", func() {
+			assert.Equal(t, &ConfigSchema{
+				Type: "object",
+				Fields: []ConfigSchema{
+					{Name: "amplitude", Type: "integer", Default: 1},
+					{Name: "period", Type: "duration", Default: 50 * time.Second},
+				},
+			}, descriptions["sinusoidal"].Config)
+		})
+
+		it("describes arrays by their items", func() {
+			segments := descriptions["composite"].Config.Fields[1]
+			assert.Equal(t, "segments", segments.Name)
+			assert.Equal(t, "array", segments.Type)
+			assert.Equal(t, "object", segments.Items.Type)
+			assert.Equal(t, ConfigSchema{Name: "config", Type: "json"}, segments.Items.Fields[1])
+		})
+
+		it("describes times", func() {
+			assert.Equal(t, ConfigSchema{Name: "start_at", Type: "time"}, descriptions["golang_rand_uniform"].Config.Fields[1])
+		})
+	})
+}
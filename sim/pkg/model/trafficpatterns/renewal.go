@@ -0,0 +1,232 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+// renewal draws inter-arrival times from a distribution with a mean of one arrival, then stretches
+// them to follow the rate: an arrival lands once the integral of the rate reaches the draw.
+type renewal struct {
+	name         string
+	env          simulator.Environment
+	source       model.TrafficSource
+	routingStock model.RequestsRoutingStock
+	interArrival model.Distribution
+	rate         []RatePoint
+	started      bool
+	segs         []rateSegment
+	segment      int     // the segment being generated
+	area         float64 // the expected arrivals in the segment before the last arrival
+	need         float64 // the expected arrivals until the next arrival
+}
+
+// RatePoint sets the arrival rate at After. The rate is interpolated linearly between points
+// and held constant before the first and after the last.
+type RatePoint struct {
+	After time.Duration `json:"after"`
+	RPS   float64       `json:"rps"`
+}
+
+type RenewalConfig struct {
+	InterArrival model.DistributionKind `json:"inter_arrival"`   // constant, exponential, erlang or hyperexponential; defaults to exponential
+	Shape        float64                `json:"shape,omitempty"` // Erlang phases, or hyperexponential squared coefficient of variation
+	Rate         []RatePoint            `json:"rate"`
+}
+
+// PoissonConfig is a RenewalConfig with exponential inter-arrival times.
+type PoissonConfig struct {
+	Rate []RatePoint `json:"rate"`
+}
+
+type rateSegment struct {
+	from     time.Time
+	duration time.Duration
+	fromRPS  float64
+	toRPS    float64
+}
+
+func (r *renewal) Name() string {
+	return r.name
+}
+
+func (r *renewal) Generate() {
+	r.GenerateUntil(r.env.HaltTime())
+}
+
+func (r *renewal) GenerateUntil(until time.Time) {
+	if !r.started {
+		r.started = true
+		r.segs = r.segments(r.env.CurrentMovementTime(), r.env.HaltTime())
+		r.need = r.interArrival.Sample().Seconds()
+	}
+
+	for ; r.segment < len(r.segs); r.segment++ {
+		seg := r.segs[r.segment]
+		total := seg.area()
+		for total > 0 && r.area+r.need <= total {
+			offset := seg.offsetFor(r.area + r.need)
+			at := seg.from.Add(time.Duration(offset * float64(time.Second))).Add(1 * time.Nanosecond)
+			if !at.Before(until) {
+				return
+			}
+
+			r.env.AddToSchedule(simulator.NewMovement(
+				"arrive_at_routing_stock",
+				at,
+				r.source,
+				r.routingStock,
+				nil,
+			))
+
+			r.area += r.need
+			r.need = r.interArrival.Sample().Seconds()
+		}
+		r.need -= total - r.area
+		r.area = 0
+	}
+}
+
+func (r *renewal) segments(startAt, haltAt time.Time) []rateSegment {
+	segments := make([]rateSegment, 0, len(r.rate)+1)
+	if len(r.rate) == 0 {
+		return segments
+	}
+
+	from := startAt
+	fromRPS := r.rate[0].RPS
+	for _, p := range r.rate {
+		at := startAt.Add(p.After)
+		if at.After(haltAt) {
+			at = haltAt
+			p.RPS = fromRPS + (p.RPS-fromRPS)*float64(haltAt.Sub(from))/float64(startAt.Add(p.After).Sub(from))
+		}
+		if at.After(from) {
+			segments = append(segments, rateSegment{from: from, duration: at.Sub(from), fromRPS: fromRPS, toRPS: p.RPS})
+		}
+		from, fromRPS = at, p.RPS
+	}
+
+	if haltAt.After(from) {
+		segments = append(segments, rateSegment{from: from, duration: haltAt.Sub(from), fromRPS: fromRPS, toRPS: fromRPS})
+	}
+
+	return segments
+}
+
+// area is the expected number of arrivals in the segment.
+func (rs rateSegment) area() float64 {
+	return (rs.fromRPS + rs.toRPS) / 2 * rs.duration.Seconds()
+}
+
+// offsetFor gives the seconds into the segment at which the expected number of arrivals reaches area.
+func (rs rateSegment) offsetFor(area float64) float64 {
+	slope := (rs.toRPS - rs.fromRPS) / rs.duration.Seconds()
+	if math.Abs(slope) < 1e-12 {
+		return area / rs.fromRPS
+	}
+
+	// solve fromRPS*t + slope/2*t^2 = area
+	return (-rs.fromRPS + math.Sqrt(math.Max(0, rs.fromRPS*rs.fromRPS+2*slope*area))) / slope
+}
+
+func init() {
+	Register(Registration{
+		Name:        "renewal",
+		Description: "A renewal process whose inter-arrival times follow a distribution, at a piecewise-linear rate.",
+		Config: func() interface{} {
+			return &RenewalConfig{InterArrival: model.DistributionExponential}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewRenewal(w.Env, w.Source, w.RoutingStock, *config.(*RenewalConfig))
+		},
+	})
+	Register(Registration{
+		Name:        "poisson",
+		Description: "A Poisson process at a piecewise-linear rate.",
+		Config: func() interface{} {
+			return &PoissonConfig{}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewPoisson(w.Env, w.Source, w.RoutingStock, config.(*PoissonConfig).Rate)
+		},
+	})
+}
+
+func (c RenewalConfig) Validate() error {
+	switch c.InterArrival {
+	case "", model.DistributionConstant, model.DistributionExponential, model.DistributionErlang, model.DistributionHyperexponential:
+	default:
+		return fmt.Errorf("'%s' cannot be used for inter-arrival times", c.InterArrival)
+	}
+	if err := c.interArrival().Validate(); err != nil {
+		return err
+	}
+	return PoissonConfig{Rate: c.Rate}.Validate()
+}
+
+// interArrival has a mean of one arrival, for the rate to stretch.
+func (c RenewalConfig) interArrival() model.DistributionConfig {
+	kind := c.InterArrival
+	if kind == "" {
+		kind = model.DistributionExponential
+	}
+	return model.DistributionConfig{Kind: kind, Mean: time.Second, Shape: c.Shape}
+}
+
+func (c PoissonConfig) Validate() error {
+	for _, p := range c.Rate {
+		if p.RPS < 0 || p.After < 0 {
+			return fmt.Errorf("rate points must not be negative, got %v", p)
+		}
+	}
+	return nil
+}
+
+func NewRenewal(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config RenewalConfig) Pattern {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	rate := make([]RatePoint, len(config.Rate))
+	copy(rate, config.Rate)
+	sort.SliceStable(rate, func(i, j int) bool {
+		return rate[i].After < rate[j].After
+	})
+
+	return &renewal{
+		name:         "renewal",
+		env:          env,
+		source:       source,
+		routingStock: routingStock,
+		interArrival: model.NewDistribution(config.interArrival()),
+		rate:         rate,
+	}
+}
+
+// NewPoisson is a renewal process with exponential inter-arrival times.
+func NewPoisson(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, rate []RatePoint) Pattern {
+	p := NewRenewal(env, source, routingStock, RenewalConfig{InterArrival: model.DistributionExponential, Rate: rate})
+	p.(*renewal).name = "poisson"
+	return p
+}
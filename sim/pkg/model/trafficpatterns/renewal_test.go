@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestRenewal(t *testing.T) {
+	spec.Run(t, "Renewal traffic patterns", testRenewal, spec.Report(report.Terminal{}))
+}
+
+func testRenewal(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(100 * time.Second)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'renewal'", func() {
+			subject = NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{})
+			assert.Equal(t, "renewal", subject.Name())
+		})
+
+		it("calls the Poisson process 'poisson'", func() {
+			subject = NewPoisson(envFake, trafficSource, routingStock, nil)
+			assert.Equal(t, "poisson", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		describe("deterministic inter-arrivals at a constant rate", func() {
+			it.Before(func() {
+				subject = NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{
+					InterArrival: model.DistributionConstant,
+					Rate:         []RatePoint{{RPS: 2}},
+				})
+				subject.Generate()
+			})
+
+			it("spaces requests evenly", func() {
+				// the 200th would land just after the halt time
+				require.Len(t, envFake.Movements, 199)
+				assert.Equal(t, envFake.TheTime.Add(500*time.Millisecond+1*time.Nanosecond), envFake.Movements[0].OccursAt())
+				assert.Equal(t, envFake.TheTime.Add(1*time.Second+1*time.Nanosecond), envFake.Movements[1].OccursAt())
+			})
+
+			it("sends requests to the routing stock", func() {
+				assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), envFake.Movements[0].Kind())
+				assert.Equal(t, routingStock, envFake.Movements[0].To())
+			})
+		})
+
+		describe("deterministic inter-arrivals at a rising rate", func() {
+			it.Before(func() {
+				subject = NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{
+					InterArrival: model.DistributionConstant,
+					Rate:         []RatePoint{{After: 0, RPS: 0}, {After: 10 * time.Second, RPS: 2}},
+				})
+				subject.Generate()
+			})
+
+			it("follows the integral of the rate", func() {
+				// 0.1*t^2 = 1 arrival after sqrt(10) seconds
+				assert.WithinDuration(t, envFake.TheTime.Add(3162*time.Millisecond), envFake.Movements[0].OccursAt(), time.Millisecond)
+				// 10 arrivals by the end of the ramp
+				assert.WithinDuration(t, envFake.TheTime.Add(10*time.Second), envFake.Movements[9].OccursAt(), time.Millisecond)
+			})
+
+			it("holds the last rate until the halt time", func() {
+				assert.Len(t, envFake.Movements, 10+179)
+			})
+		})
+
+		describe("Poisson arrivals", func() {
+			it.Before(func() {
+				subject = NewPoisson(envFake, trafficSource, routingStock, []RatePoint{{RPS: 50}})
+				subject.Generate()
+			})
+
+			it("has roughly the expected number of requests", func() {
+				assert.InDelta(t, 5000, len(envFake.Movements), 300)
+			})
+		})
+
+		describe("hyperexponential arrivals", func() {
+			it.Before(func() {
+				subject = NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{
+					InterArrival: model.DistributionHyperexponential,
+					Shape:        4,
+					Rate:         []RatePoint{{RPS: 50}},
+				})
+				subject.Generate()
+			})
+
+			it("has roughly the expected number of requests", func() {
+				assert.InDelta(t, 5000, len(envFake.Movements), 600)
+			})
+		})
+
+		describe("a rate of zero", func() {
+			it.Before(func() {
+				subject = NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{Rate: []RatePoint{{RPS: 0}}})
+				subject.Generate()
+			})
+
+			it("schedules nothing", func() {
+				assert.Len(t, envFake.Movements, 0)
+			})
+		})
+	})
+
+	describe("NewRenewal()", func() {
+		it("panics on distributions that do not suit inter-arrival times", func() {
+			assert.Panics(t, func() {
+				NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{InterArrival: model.DistributionUniform})
+			})
+		})
+
+		it("panics on shapes the inter-arrival distribution can't take", func() {
+			assert.Panics(t, func() {
+				NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{InterArrival: model.DistributionHyperexponential, Shape: 0.5})
+			})
+		})
+
+		it("panics on negative rates", func() {
+			assert.Panics(t, func() {
+				NewRenewal(envFake, trafficSource, routingStock, RenewalConfig{Rate: []RatePoint{{RPS: -1}}})
+			})
+		})
+	})
+}
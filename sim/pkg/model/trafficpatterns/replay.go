@@ -0,0 +1,384 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+const (
+	// ReplayFormatCSV is one request per row: timestamp[,cpu_time_millis,io_time_millis,class].
+	// A header row with those names may give the columns in any order.
+	ReplayFormatCSV = "csv"
+	// ReplayFormatJSONL is one request per line, e.g. {"timestamp": "2019-05-01T10:00:00Z", "class": "checkout"}.
+	ReplayFormatJSONL = "jsonl"
+	// ReplayFormatRPS is one requests-per-second value per line, starting from the first second.
+	ReplayFormatRPS = "rps"
+)
+
+type replay struct {
+	env          simulator.Environment
+	source       model.TrafficSource
+	routingStock model.RequestsRoutingStock
+	requests     []replayedRequest
+	rps          []int
+	timeScale    float64
+	offset       time.Duration
+	started      bool
+	startAt      time.Time
+	nextRequest  int
+	nextSecond   int
+}
+
+// TraceDir is the directory that replay traces are read from, with ReplayConfig.Path relative to it.
+// While it is empty, traces can only be given inline, so that runs submitted over HTTP cannot read
+// arbitrary files from the server.
+var TraceDir string
+
+// ReplayConfig replays recorded traffic, read from Path or given inline as Data.
+type ReplayConfig struct {
+	Format    string        `json:"format"`
+	Path      string        `json:"path,omitempty"`
+	Data      string        `json:"data,omitempty"`
+	TimeScale float64       `json:"time_scale,omitempty"` // stretches (> 1) or compresses (< 1) the trace; defaults to 1
+	Offset    time.Duration `json:"offset,omitempty"`     // shifts the trace later, or earlier when negative
+}
+
+type replayedRequest struct {
+	at        time.Duration // since the first request in the trace
+	overrides model.RequestOverrides
+}
+
+type replayedLine struct {
+	Timestamp     json.RawMessage `json:"timestamp"`
+	CPUTimeMillis int             `json:"cpu_time_millis"`
+	IOTimeMillis  int             `json:"io_time_millis"`
+	Class         string          `json:"class"`
+}
+
+func (*replay) Name() string {
+	return "replay"
+}
+
+func (r *replay) Generate() {
+	r.GenerateUntil(r.env.HaltTime())
+}
+
+func (r *replay) GenerateUntil(until time.Time) {
+	if !r.started {
+		r.started = true
+		r.startAt = r.env.CurrentMovementTime()
+	}
+
+	for ; r.nextRequest < len(r.requests); r.nextRequest++ {
+		req := r.requests[r.nextRequest]
+		at := r.startAt.Add(r.offset).Add(r.scale(req.at)).Add(1 * time.Nanosecond)
+		if !at.Before(until) || !at.Before(r.env.HaltTime()) {
+			break
+		}
+		if !at.After(r.startAt) {
+			continue
+		}
+
+		var entity *simulator.Entity
+		if req.overrides != (model.RequestOverrides{}) {
+			var e simulator.Entity = r.source.RequestWith(req.overrides)
+			entity = &e
+		}
+
+		r.env.AddToSchedule(simulator.NewMovement("arrive_at_routing_stock", at, r.source, r.routingStock, entity))
+	}
+
+	second := r.scale(time.Second)
+	if second <= 0 {
+		second = 1 * time.Nanosecond
+	}
+	for ; r.nextSecond < len(r.rps); r.nextSecond++ {
+		secondStart := r.startAt.Add(r.offset).Add(time.Duration(r.nextSecond) * second)
+		if !secondStart.Before(until) {
+			break
+		}
+
+		for j := 0; j < r.rps[r.nextSecond]; j++ {
+			at := secondStart.Add(time.Duration(rand.Int63n(second.Nanoseconds()))).Add(1 * time.Nanosecond)
+			if !at.After(r.startAt) || !at.Before(r.env.HaltTime()) {
+				continue
+			}
+
+			r.env.AddToSchedule(simulator.NewMovement("arrive_at_routing_stock", at, r.source, r.routingStock, nil))
+		}
+	}
+}
+
+func (r *replay) scale(d time.Duration) time.Duration {
+	return time.Duration(math.Round(float64(d) * r.timeScale))
+}
+
+func init() {
+	Register(Registration{
+		Name:        "replay",
+		Description: "Replays a recorded trace of requests, or of requests per second.",
+		Config: func() interface{} {
+			return &ReplayConfig{Format: ReplayFormatCSV, TimeScale: 1}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewReplay(w.Env, w.Source, w.RoutingStock, *config.(*ReplayConfig))
+		},
+	})
+}
+
+func (c ReplayConfig) Validate() error {
+	switch c.Format {
+	case ReplayFormatCSV, ReplayFormatJSONL, ReplayFormatRPS:
+	default:
+		return fmt.Errorf("unknown replay format '%s'", c.Format)
+	}
+	if c.TimeScale < 0 {
+		return fmt.Errorf("replay time scale must not be negative, got %f", c.TimeScale)
+	}
+	if c.Data == "" && c.Path == "" {
+		return fmt.Errorf("replay needs a trace, given either inline as data or as a path")
+	}
+	if c.Data == "" && TraceDir == "" {
+		return fmt.Errorf("replay traces cannot be read from a path here; give the trace inline as data")
+	}
+	return nil
+}
+
+func NewReplay(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config ReplayConfig) Pattern {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	timeScale := config.TimeScale
+	if timeScale == 0 {
+		timeScale = 1
+	}
+
+	r := &replay{
+		env:          env,
+		source:       source,
+		routingStock: routingStock,
+		timeScale:    timeScale,
+		offset:       config.Offset,
+	}
+
+	trace := readTrace(config)
+	var err error
+	switch config.Format {
+	case ReplayFormatCSV:
+		r.requests, err = parseCSVTrace(trace)
+	case ReplayFormatJSONL:
+		r.requests, err = parseJSONLTrace(trace)
+	case ReplayFormatRPS:
+		r.rps, err = parseRPSTrace(trace)
+	}
+	if err != nil {
+		panic(fmt.Errorf("could not read replay trace: %s", err.Error()))
+	}
+
+	return r
+}
+
+func readTrace(config ReplayConfig) string {
+	if config.Data != "" {
+		return config.Data
+	}
+
+	// cleaning the path as if it were absolute keeps it from climbing out of the trace directory
+	contents, err := ioutil.ReadFile(filepath.Join(TraceDir, filepath.Clean("/"+config.Path)))
+	if err != nil {
+		panic(fmt.Errorf("could not read replay trace '%s': %s", config.Path, err.Error()))
+	}
+	return string(contents)
+}
+
+func parseCSVTrace(trace string) ([]replayedRequest, error) {
+	reader := csv.NewReader(strings.NewReader(trace))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns := map[string]int{"timestamp": 0, "cpu_time_millis": 1, "io_time_millis": 2, "class": 3}
+	timestamps := make([]time.Time, 0)
+	overrides := make([]model.RequestOverrides, 0)
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if row == 0 {
+			if _, err := parseTimestamp(record[0]); err != nil {
+				columns = make(map[string]int)
+				for i, name := range record {
+					columns[strings.TrimSpace(name)] = i
+				}
+				if _, ok := columns["timestamp"]; !ok {
+					return nil, fmt.Errorf("the header has no 'timestamp' column")
+				}
+				continue
+			}
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		ts, err := parseTimestamp(field("timestamp"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %s", row+1, err.Error())
+		}
+
+		var o model.RequestOverrides
+		if o.CPUTimeMillis, err = optionalInt(field("cpu_time_millis")); err != nil {
+			return nil, fmt.Errorf("row %d: %s", row+1, err.Error())
+		}
+		if o.IOTimeMillis, err = optionalInt(field("io_time_millis")); err != nil {
+			return nil, fmt.Errorf("row %d: %s", row+1, err.Error())
+		}
+		o.Class = field("class")
+
+		timestamps = append(timestamps, ts)
+		overrides = append(overrides, o)
+	}
+
+	return relativeToFirst(timestamps, overrides), nil
+}
+
+func parseJSONLTrace(trace string) ([]replayedRequest, error) {
+	timestamps := make([]time.Time, 0)
+	overrides := make([]model.RequestOverrides, 0)
+
+	scanner := bufio.NewScanner(strings.NewReader(trace))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var line replayedLine
+		if err := json.Unmarshal([]byte(text), &line); err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err.Error())
+		}
+
+		ts, err := parseTimestamp(strings.Trim(string(line.Timestamp), `"`))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err.Error())
+		}
+
+		timestamps = append(timestamps, ts)
+		overrides = append(overrides, model.RequestOverrides{
+			CPUTimeMillis: line.CPUTimeMillis,
+			IOTimeMillis:  line.IOTimeMillis,
+			Class:         line.Class,
+		})
+	}
+
+	return relativeToFirst(timestamps, overrides), scanner.Err()
+}
+
+func parseRPSTrace(trace string) ([]int, error) {
+	series := make([]int, 0)
+
+	scanner := bufio.NewScanner(strings.NewReader(trace))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		rps, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			if len(series) == 0 && lineNum == 1 {
+				continue // a header
+			}
+			return nil, fmt.Errorf("line %d: %s", lineNum, err.Error())
+		}
+		if rps < 0 {
+			return nil, fmt.Errorf("line %d: requests per second must not be negative", lineNum)
+		}
+
+		series = append(series, int(math.Round(rps)))
+	}
+
+	return series, scanner.Err()
+}
+
+// parseTimestamp accepts RFC 3339 times or seconds, e.g. a Unix timestamp like 1556704800.25.
+func parseTimestamp(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		whole, frac := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(math.Round(frac*1e9))), nil
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse timestamp '%s'", s)
+	}
+	return ts, nil
+}
+
+func optionalInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func relativeToFirst(timestamps []time.Time, overrides []model.RequestOverrides) []replayedRequest {
+	requests := make([]replayedRequest, 0, len(timestamps))
+	if len(timestamps) == 0 {
+		return requests
+	}
+
+	first := timestamps[0]
+	for _, ts := range timestamps {
+		if ts.Before(first) {
+			first = ts
+		}
+	}
+
+	for i, ts := range timestamps {
+		requests = append(requests, replayedRequest{at: ts.Sub(first), overrides: overrides[i]})
+	}
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].at < requests[j].at
+	})
+
+	return requests
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestReplay(t *testing.T) {
+	spec.Run(t, "Replay traffic pattern", testReplay, spec.Report(report.Terminal{}))
+}
+
+func testReplay(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var config ReplayConfig
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(30 * time.Second)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'replay'", func() {
+			subject = NewReplay(envFake, trafficSource, routingStock, ReplayConfig{Format: ReplayFormatRPS, Data: "1"})
+			assert.Equal(t, "replay", subject.Name())
+		})
+	})
+
+	describe("Generate()", func() {
+		describe("a CSV trace", func() {
+			it.Before(func() {
+				config = ReplayConfig{
+					Format: ReplayFormatCSV,
+					Data:   "1556704802.5\n1556704800,20,,checkout\n1556704801",
+				}
+				subject = NewReplay(envFake, trafficSource, routingStock, config)
+				subject.Generate()
+			})
+
+			it("schedules a request for each row, relative to the earliest", func() {
+				require.Len(t, envFake.Movements, 3)
+				assert.Equal(t, envFake.TheTime.Add(1*time.Nanosecond), envFake.Movements[0].OccursAt())
+				assert.Equal(t, envFake.TheTime.Add(1*time.Second+1*time.Nanosecond), envFake.Movements[1].OccursAt())
+				assert.Equal(t, envFake.TheTime.Add(2500*time.Millisecond+1*time.Nanosecond), envFake.Movements[2].OccursAt())
+			})
+
+			it("sends requests to the routing stock", func() {
+				assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), envFake.Movements[0].Kind())
+				assert.Equal(t, routingStock, envFake.Movements[0].To())
+			})
+
+			it("creates the requests with their own costs up front", func() {
+				assert.NotNil(t, envFake.Movements[0].WhatToMove())
+			})
+
+			it("leaves the others to the traffic source", func() {
+				assert.Nil(t, envFake.Movements[1].WhatToMove())
+			})
+
+			it("records the class of requests which have one", func() {
+				classed := (*envFake.Movements[0].WhatToMove()).Name()
+				assert.Equal(t, map[simulator.EntityName]string{classed: "checkout"}, trafficSource.RequestClasses())
+			})
+		})
+
+		describe("a CSV trace with a header", func() {
+			it.Before(func() {
+				config = ReplayConfig{
+					Format: ReplayFormatCSV,
+					Data:   "io_time_millis,timestamp\n10,2019-05-01T10:00:00Z\n20,2019-05-01T10:00:03Z",
+				}
+				subject = NewReplay(envFake, trafficSource, routingStock, config)
+				subject.Generate()
+			})
+
+			it("finds the columns by name", func() {
+				require.Len(t, envFake.Movements, 2)
+				assert.Equal(t, envFake.TheTime.Add(3*time.Second+1*time.Nanosecond), envFake.Movements[1].OccursAt())
+				assert.NotNil(t, envFake.Movements[1].WhatToMove())
+			})
+		})
+
+		describe("a JSONL trace", func() {
+			it.Before(func() {
+				config = ReplayConfig{
+					Format: ReplayFormatJSONL,
+					Data:   `{"timestamp": "2019-05-01T10:00:00Z", "class": "browse"}` + "\n\n" + `{"timestamp": 1556704804, "io_time_millis": 10}`,
+				}
+				subject = NewReplay(envFake, trafficSource, routingStock, config)
+				subject.Generate()
+			})
+
+			it("schedules a request for each line", func() {
+				require.Len(t, envFake.Movements, 2)
+				assert.Equal(t, envFake.TheTime.Add(4*time.Second+1*time.Nanosecond), envFake.Movements[1].OccursAt())
+			})
+
+			it("reads the class of each request", func() {
+				classed := (*envFake.Movements[0].WhatToMove()).Name()
+				assert.Equal(t, map[simulator.EntityName]string{classed: "browse"}, trafficSource.RequestClasses())
+			})
+		})
+
+		describe("an RPS series", func() {
+			it.Before(func() {
+				config = ReplayConfig{
+					Format: ReplayFormatRPS,
+					Data:   "rps\n3\n0\n5",
+				}
+				subject = NewReplay(envFake, trafficSource, routingStock, config)
+				subject.Generate()
+			})
+
+			it("schedules each second's requests within that second", func() {
+				require.Len(t, envFake.Movements, 8)
+				for _, mv := range envFake.Movements[:3] {
+					assert.True(t, mv.OccursAt().Before(envFake.TheTime.Add(1*time.Second+1*time.Nanosecond)))
+				}
+				for _, mv := range envFake.Movements[3:] {
+					assert.True(t, mv.OccursAt().After(envFake.TheTime.Add(2*time.Second)))
+				}
+			})
+		})
+
+		describe("time scale and offset", func() {
+			it.Before(func() {
+				config = ReplayConfig{
+					Format:    ReplayFormatCSV,
+					Data:      "0\n4\n10",
+					TimeScale: 0.5,
+					Offset:    -1 * time.Second,
+				}
+				subject = NewReplay(envFake, trafficSource, routingStock, config)
+				subject.Generate()
+			})
+
+			it("scales the trace, then shifts it, skipping whatever lands before the start", func() {
+				require.Len(t, envFake.Movements, 2)
+				assert.Equal(t, envFake.TheTime.Add(1*time.Second+1*time.Nanosecond), envFake.Movements[0].OccursAt())
+				assert.Equal(t, envFake.TheTime.Add(4*time.Second+1*time.Nanosecond), envFake.Movements[1].OccursAt())
+			})
+		})
+
+		describe("requests after the halt time", func() {
+			it.Before(func() {
+				subject = NewReplay(envFake, trafficSource, routingStock, ReplayConfig{Format: ReplayFormatCSV, Data: "0\n40"})
+				subject.Generate()
+			})
+
+			it("does not schedule them", func() {
+				assert.Len(t, envFake.Movements, 1)
+			})
+		})
+	})
+
+	describe("NewReplay()", func() {
+		describe("reading the trace from a file", func() {
+			var dir string
+
+			it.Before(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "replay")
+				require.NoError(t, err)
+				require.NoError(t, os.Mkdir(filepath.Join(dir, "traces"), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "traces", "trace.csv"), []byte("0\n1\n"), 0644))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret.csv"), []byte("0\n"), 0644))
+				TraceDir = filepath.Join(dir, "traces")
+			})
+
+			it.After(func() {
+				TraceDir = ""
+				os.RemoveAll(dir)
+			})
+
+			it("reads it relative to the trace directory", func() {
+				NewReplay(envFake, trafficSource, routingStock, ReplayConfig{Format: ReplayFormatCSV, Path: "trace.csv"}).Generate()
+				assert.Len(t, envFake.Movements, 2)
+			})
+
+			it("does not leave the trace directory", func() {
+				assert.Panics(t, func() {
+					NewReplay(envFake, trafficSource, routingStock, ReplayConfig{Format: ReplayFormatCSV, Path: "../secret.csv"})
+				})
+			})
+
+			it("is refused without a trace directory", func() {
+				TraceDir = ""
+				assert.Error(t, ReplayConfig{Format: ReplayFormatCSV, Path: "trace.csv"}.Validate())
+			})
+		})
+
+		it("panics without a trace", func() {
+			assert.Panics(t, func() {
+				NewReplay(envFake, trafficSource, routingStock, ReplayConfig{Format: ReplayFormatCSV})
+			})
+		})
+
+		it("panics on an unknown format", func() {
+			assert.Panics(t, func() {
+				NewReplay(envFake, trafficSource, routingStock, ReplayConfig{Format: "xml"})
+			})
+		})
+
+		it("panics on a timestamp it cannot parse", func() {
+			assert.Panics(t, func() {
+				NewReplay(envFake, trafficSource, routingStock, ReplayConfig{Format: ReplayFormatCSV, Data: "0\nyesterday"})
+			})
+		})
+	})
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+// seasonal follows the calendar: a base rate shaped by the time of day, the day of the week
+// and holidays, with multiplicative noise on top. It needs the run to start at a realistic
+// wall-clock time to be useful.
+type seasonal struct {
+	env          simulator.Environment
+	source       model.TrafficSource
+	routingStock model.RequestsRoutingStock
+	baseRPS      float64
+	daily        []float64
+	weekly       []float64
+	holidays     map[string]float64
+	noise        float64
+	location     *time.Location
+	started      bool
+	next         time.Time
+}
+
+type SeasonalConfig struct {
+	BaseRPS float64 `json:"base_rps"`
+	// Daily multipliers, evenly spaced over the day from midnight and interpolated linearly,
+	// e.g. 24 hourly values.
+	Daily []float64 `json:"daily,omitempty"`
+	// Weekly multipliers for each day, starting on Sunday.
+	Weekly   []float64 `json:"weekly,omitempty"`
+	Holidays []Holiday `json:"holidays,omitempty"`
+	// Noise is the standard deviation of the log of a multiplier drawn for every second, with a mean of 1.
+	Noise    float64 `json:"noise,omitempty"`
+	Location string  `json:"location,omitempty"` // the time zone the profiles are in, defaults to UTC
+}
+
+type Holiday struct {
+	Date       string  `json:"date"` // e.g. 2019-12-25
+	Multiplier float64 `json:"multiplier"`
+}
+
+func (*seasonal) Name() string {
+	return "seasonal"
+}
+
+func (s *seasonal) Generate() {
+	s.GenerateUntil(s.env.HaltTime())
+}
+
+func (s *seasonal) GenerateUntil(until time.Time) {
+	if !s.started {
+		s.started = true
+		s.next = s.env.CurrentMovementTime()
+	}
+
+	for ; s.next.Before(until) && s.next.Before(s.env.HaltTime()); s.next = s.next.Add(1 * time.Second) {
+		secondEnd := s.next.Add(1 * time.Second)
+		if secondEnd.After(s.env.HaltTime()) {
+			secondEnd = s.env.HaltTime()
+		}
+
+		schedulePoisson(s.env, s.source, s.routingStock, s.next, secondEnd, s.rateAt(s.next)*s.noiseMultiplier())
+	}
+}
+
+// rateAt is the rate without noise.
+func (s *seasonal) rateAt(t time.Time) float64 {
+	local := t.In(s.location)
+	rate := s.baseRPS
+
+	if len(s.daily) > 0 {
+		midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+		position := local.Sub(midnight).Hours() / 24 * float64(len(s.daily))
+		slot := int(math.Floor(position)) % len(s.daily)
+		next := (slot + 1) % len(s.daily)
+		fraction := position - math.Floor(position)
+		rate *= s.daily[slot] + (s.daily[next]-s.daily[slot])*fraction
+	}
+
+	if len(s.weekly) > 0 {
+		rate *= s.weekly[int(local.Weekday())]
+	}
+
+	if multiplier, ok := s.holidays[local.Format("2006-01-02")]; ok {
+		rate *= multiplier
+	}
+
+	return rate
+}
+
+func (s *seasonal) noiseMultiplier() float64 {
+	if s.noise == 0 {
+		return 1
+	}
+	return math.Exp(s.noise*rand.NormFloat64() - s.noise*s.noise/2)
+}
+
+// schedulePoisson sends requests with exponentially distributed gaps between from and until.
+func schedulePoisson(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, from, until time.Time, rps float64) {
+	if rps <= 0 {
+		return
+	}
+
+	gaps := model.NewDistribution(model.DistributionConfig{
+		Kind: model.DistributionExponential,
+		Mean: time.Duration(float64(time.Second) / rps),
+	})
+	for at := from.Add(gaps.Sample()); at.Before(until); at = at.Add(gaps.Sample()) {
+		env.AddToSchedule(simulator.NewMovement(
+			"arrive_at_routing_stock",
+			at.Add(1*time.Nanosecond),
+			source,
+			routingStock,
+			nil,
+		))
+	}
+}
+
+func init() {
+	Register(Registration{
+		Name:        "seasonal",
+		Description: "A base rate shaped by daily and weekly profiles and holidays, with optional noise.",
+		Config: func() interface{} {
+			return &SeasonalConfig{Location: "UTC"}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewSeasonal(w.Env, w.Source, w.RoutingStock, *config.(*SeasonalConfig))
+		},
+	})
+}
+
+func (c SeasonalConfig) Validate() error {
+	if c.BaseRPS < 0 || c.Noise < 0 {
+		return fmt.Errorf("base rps and noise must not be negative, got %f and %f", c.BaseRPS, c.Noise)
+	}
+	if len(c.Weekly) != 0 && len(c.Weekly) != 7 {
+		return fmt.Errorf("weekly profiles need a multiplier for each of the 7 days, got %d", len(c.Weekly))
+	}
+	for _, m := range append(append([]float64{}, c.Daily...), c.Weekly...) {
+		if m < 0 {
+			return fmt.Errorf("multipliers must not be negative, got %f", m)
+		}
+	}
+
+	if c.Location != "" {
+		_, err := time.LoadLocation(c.Location)
+		if err != nil {
+			return fmt.Errorf("unknown location '%s': %s", c.Location, err.Error())
+		}
+	}
+
+	for _, h := range c.Holidays {
+		_, err := time.Parse("2006-01-02", h.Date)
+		if err != nil {
+			return fmt.Errorf("could not parse holiday date '%s': %s", h.Date, err.Error())
+		}
+		if h.Multiplier < 0 {
+			return fmt.Errorf("the multiplier for %s must not be negative, got %f", h.Date, h.Multiplier)
+		}
+	}
+	return nil
+}
+
+func NewSeasonal(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config SeasonalConfig) Pattern {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	location := time.UTC
+	if config.Location != "" {
+		location, _ = time.LoadLocation(config.Location)
+	}
+
+	holidays := make(map[string]float64, len(config.Holidays))
+	for _, h := range config.Holidays {
+		date, _ := time.Parse("2006-01-02", h.Date)
+		holidays[date.Format("2006-01-02")] = h.Multiplier
+	}
+
+	return &seasonal{
+		env:          env,
+		source:       source,
+		routingStock: routingStock,
+		baseRPS:      config.BaseRPS,
+		daily:        config.Daily,
+		weekly:       config.Weekly,
+		holidays:     holidays,
+		noise:        config.Noise,
+		location:     location,
+	}
+}
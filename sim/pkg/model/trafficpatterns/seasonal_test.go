@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestSeasonal(t *testing.T) {
+	spec.Run(t, "Seasonal traffic pattern", testSeasonal, spec.Report(report.Terminal{}))
+}
+
+func testSeasonal(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var config SeasonalConfig
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		// a Wednesday
+		envFake.TheTime = time.Date(2019, 12, 25, 0, 0, 0, 0, time.UTC)
+		envFake.TheHaltTime = envFake.TheTime.Add(10 * time.Minute)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+
+		config = SeasonalConfig{
+			BaseRPS:  10,
+			Daily:    []float64{1, 3, 2, 0},
+			Weekly:   []float64{0.5, 1, 1, 2, 1, 1, 0.5},
+			Holidays: []Holiday{{Date: "2019-12-25", Multiplier: 0.1}},
+		}
+	})
+
+	describe("Name()", func() {
+		it("calls itself 'seasonal'", func() {
+			subject = NewSeasonal(envFake, trafficSource, routingStock, config)
+			assert.Equal(t, "seasonal", subject.Name())
+		})
+	})
+
+	describe("the rate", func() {
+		var rawSubject *seasonal
+
+		it.Before(func() {
+			rawSubject = NewSeasonal(envFake, trafficSource, routingStock, config).(*seasonal)
+		})
+
+		it("interpolates the daily profile", func() {
+			// Monday 03:00 is halfway between the first two points
+			assert.InDelta(t, 20, rawSubject.rateAt(time.Date(2019, 12, 23, 3, 0, 0, 0, time.UTC)), 0.001)
+			// Monday 21:00 is halfway between the last point and the first of the next day
+			assert.InDelta(t, 5, rawSubject.rateAt(time.Date(2019, 12, 23, 21, 0, 0, 0, time.UTC)), 0.001)
+		})
+
+		it("multiplies by the day of the week", func() {
+			// Sunday, then Thursday
+			assert.InDelta(t, 5, rawSubject.rateAt(time.Date(2019, 12, 22, 0, 0, 0, 0, time.UTC)), 0.001)
+			assert.InDelta(t, 10, rawSubject.rateAt(time.Date(2019, 12, 26, 0, 0, 0, 0, time.UTC)), 0.001)
+		})
+
+		it("multiplies by holidays", func() {
+			// a Wednesday too
+			assert.InDelta(t, 2, rawSubject.rateAt(time.Date(2019, 12, 25, 0, 0, 0, 0, time.UTC)), 0.001)
+		})
+
+		it("follows the time zone of the profiles", func() {
+			config.Location = "America/New_York"
+			rawSubject = NewSeasonal(envFake, trafficSource, routingStock, config).(*seasonal)
+
+			// 03:00 UTC on Thursday is 22:00 on Wednesday, a holiday, in New York
+			assert.InDelta(t, 10*(2.0/3)*2*0.1, rawSubject.rateAt(time.Date(2019, 12, 26, 3, 0, 0, 0, time.UTC)), 0.001)
+		})
+	})
+
+	describe("Generate()", func() {
+		it.Before(func() {
+			config = SeasonalConfig{BaseRPS: 10, Noise: 0.2}
+			subject = NewSeasonal(envFake, trafficSource, routingStock, config)
+			subject.Generate()
+		})
+
+		it("sends roughly the base rate with noise around it", func() {
+			assert.InDelta(t, 6000, len(envFake.Movements), 600)
+		})
+
+		it("uses the wall-clock time of the run", func() {
+			for _, mv := range envFake.Movements {
+				assert.True(t, mv.OccursAt().After(envFake.TheTime))
+				assert.True(t, mv.OccursAt().Before(envFake.TheHaltTime.Add(1*time.Nanosecond)))
+			}
+		})
+	})
+
+	describe("NewSeasonal()", func() {
+		it("panics on a weekly profile without 7 days", func() {
+			config.Weekly = []float64{1, 2}
+			assert.Panics(t, func() { NewSeasonal(envFake, trafficSource, routingStock, config) })
+		})
+
+		it("panics on an unknown location", func() {
+			config.Location = "Atlantis/Capital"
+			assert.Panics(t, func() { NewSeasonal(envFake, trafficSource, routingStock, config) })
+		})
+
+		it("panics on a holiday it cannot parse", func() {
+			config.Holidays = []Holiday{{Date: "Christmas", Multiplier: 2}}
+			assert.Panics(t, func() { NewSeasonal(envFake, trafficSource, routingStock, config) })
+		})
+	})
+}
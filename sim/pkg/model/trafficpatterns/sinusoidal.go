@@ -29,6 +29,9 @@ type sinusoidal struct {
 	period       time.Duration
 	source       model.TrafficSource
 	routingStock model.RequestsRoutingStock
+	started      bool
+	startAt      time.Time
+	next         time.Time
 }
 
 type SinusoidalConfig struct {
@@ -41,13 +44,22 @@ func (*sinusoidal) Name() string {
 }
 
 func (s *sinusoidal) Generate() {
-	var t time.Time
-	startAt := s.env.CurrentMovementTime()
+	s.GenerateUntil(s.env.HaltTime())
+}
+
+func (s *sinusoidal) GenerateUntil(until time.Time) {
+	if !s.started {
+		s.started = true
+		s.startAt = s.env.CurrentMovementTime()
+		s.next = s.startAt
+	}
+
 	twoPi := 2.0 * math.Pi
-	for t = startAt; t.Before(s.env.HaltTime()); t = t.Add(1 * time.Second) {
+	for ; s.next.Before(until) && s.next.Before(s.env.HaltTime()); s.next = s.next.Add(1 * time.Second) {
+		t := s.next
 		ampl := float64(s.amplitude)
 		perd := float64(s.period.Seconds())
-		tsec := float64(t.Unix())
+		tsec := t.Sub(s.startAt).Seconds()
 
 		rps := ampl*math.Sin(twoPi*(tsec/perd)) + ampl
 		roundedRPS := int(math.Round(rps))
@@ -61,6 +73,19 @@ func (s *sinusoidal) Generate() {
 	}
 }
 
+func init() {
+	Register(Registration{
+		Name:        "sinusoidal",
+		Description: "Varies the rate as a sine wave of the given amplitude and period.",
+		Config: func() interface{} {
+			return &SinusoidalConfig{Amplitude: 1, Period: 50 * time.Second}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewSinusoidal(w.Env, w.Source, w.RoutingStock, *config.(*SinusoidalConfig))
+		},
+	})
+}
+
 func NewSinusoidal(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config SinusoidalConfig) Pattern {
 	return &sinusoidal{
 		env:          env,
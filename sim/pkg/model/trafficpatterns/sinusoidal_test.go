@@ -90,4 +90,16 @@ func testSinusoidal(t *testing.T, describe spec.G, it spec.S) {
 			}
 		})
 	})
+
+	describe("starting at another time", func() {
+		it.Before(func() {
+			envFake.TheTime = time.Date(2019, 12, 25, 9, 0, 7, 0, time.UTC)
+			envFake.TheHaltTime = envFake.TheTime.Add(30 * time.Second)
+			subject.Generate()
+		})
+
+		it("starts the wave from the start of the run", func() {
+			assert.Len(t, envFake.Movements, 726)
+		})
+	})
 }
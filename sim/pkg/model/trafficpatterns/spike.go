@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+const (
+	SpikeDecayExponential = "exponential"
+	SpikeDecayPowerLaw    = "power_law"
+)
+
+// spike is a flash crowd: a baseline rate that climbs to a peak at the onset of the spike, then
+// decays back towards the baseline, optionally again and again.
+type spike struct {
+	env          simulator.Environment
+	source       model.TrafficSource
+	routingStock model.RequestsRoutingStock
+	config       SpikeConfig
+	startAt      time.Time
+	started      bool
+	next         time.Time
+}
+
+type SpikeConfig struct {
+	BaseRPS        float64       `json:"base_rps"`
+	Onset          time.Duration `json:"onset"`               // after the start of the run
+	RiseTime       time.Duration `json:"rise_time,omitempty"` // to climb linearly to the peak; the climb is instant when 0
+	PeakMultiplier float64       `json:"peak_multiplier"`     // of the base rate
+	Decay          string        `json:"decay,omitempty"`     // exponential (the default) or power_law
+	// DecayTime is the time constant of the decay. Exponential decay falls to 1/e of the peak's excess over
+	// the baseline after DecayTime, and power-law decay falls as (1 + t/DecayTime)^-DecayExponent.
+	DecayTime     time.Duration `json:"decay_time"`
+	DecayExponent float64       `json:"decay_exponent,omitempty"` // defaults to 1
+	// Every repeats the spike, each repeat taking over from the spike before it.
+	Every   time.Duration `json:"every,omitempty"`
+	Repeats int           `json:"repeats,omitempty"` // the number of spikes when repeating; when 0, until the run ends
+}
+
+func (*spike) Name() string {
+	return "spike"
+}
+
+func (s *spike) Generate() {
+	s.GenerateUntil(s.env.HaltTime())
+}
+
+func (s *spike) GenerateUntil(until time.Time) {
+	if !s.started {
+		s.started = true
+		s.startAt = s.env.CurrentMovementTime()
+		s.next = s.startAt
+	}
+
+	for ; s.next.Before(until) && s.next.Before(s.env.HaltTime()); s.next = s.next.Add(1 * time.Second) {
+		secondEnd := s.next.Add(1 * time.Second)
+		if secondEnd.After(s.env.HaltTime()) {
+			secondEnd = s.env.HaltTime()
+		}
+
+		middle := s.next.Add(secondEnd.Sub(s.next) / 2)
+		schedulePoisson(s.env, s.source, s.routingStock, s.next, secondEnd, s.rateAt(middle.Sub(s.startAt)))
+	}
+}
+
+// rateAt is the rate at a time since the start of the run.
+func (s *spike) rateAt(t time.Duration) float64 {
+	since := t - s.config.Onset
+	if since < 0 {
+		return s.config.BaseRPS
+	}
+	if s.config.Every > 0 {
+		repeat := int64(since / s.config.Every)
+		if s.config.Repeats > 0 && repeat >= int64(s.config.Repeats) {
+			repeat = int64(s.config.Repeats) - 1
+		}
+		since -= time.Duration(repeat) * s.config.Every
+	}
+
+	excess := s.config.BaseRPS * (s.config.PeakMultiplier - 1)
+	if since < s.config.RiseTime {
+		return s.config.BaseRPS + excess*float64(since)/float64(s.config.RiseTime)
+	}
+
+	decaying := float64(since-s.config.RiseTime) / float64(s.config.DecayTime)
+	if s.config.Decay == SpikeDecayPowerLaw {
+		return s.config.BaseRPS + excess*math.Pow(1+decaying, -s.config.DecayExponent)
+	}
+	return s.config.BaseRPS + excess*math.Exp(-decaying)
+}
+
+func init() {
+	Register(Registration{
+		Name:        "spike",
+		Description: "A baseline rate with a flash crowd that rises to a peak and decays exponentially or by a power law, optionally repeated.",
+		Config: func() interface{} {
+			return &SpikeConfig{
+				BaseRPS:        10,
+				Onset:          10 * time.Second,
+				RiseTime:       5 * time.Second,
+				PeakMultiplier: 10,
+				Decay:          SpikeDecayExponential,
+				DecayTime:      30 * time.Second,
+				DecayExponent:  1,
+			}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewSpike(w.Env, w.Source, w.RoutingStock, *config.(*SpikeConfig))
+		},
+	})
+}
+
+func (c SpikeConfig) Validate() error {
+	if c.BaseRPS < 0 || c.PeakMultiplier < 0 || c.DecayExponent < 0 {
+		return fmt.Errorf("base rps, peak multiplier and decay exponent must not be negative, got %f, %f and %f", c.BaseRPS, c.PeakMultiplier, c.DecayExponent)
+	}
+	if c.Onset < 0 || c.RiseTime < 0 || c.Every < 0 || c.Repeats < 0 {
+		return fmt.Errorf("onset, rise time, every and repeats must not be negative, got %v, %v, %v and %d", c.Onset, c.RiseTime, c.Every, c.Repeats)
+	}
+	if c.DecayTime <= 0 {
+		return fmt.Errorf("the decay time must be positive, got %v", c.DecayTime)
+	}
+	switch c.Decay {
+	case "", SpikeDecayExponential, SpikeDecayPowerLaw:
+	default:
+		return fmt.Errorf("unknown spike decay '%s'", c.Decay)
+	}
+	return nil
+}
+
+func NewSpike(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config SpikeConfig) Pattern {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.DecayExponent == 0 {
+		config.DecayExponent = 1
+	}
+
+	return &spike{
+		env:          env,
+		source:       source,
+		routingStock: routingStock,
+		config:       config,
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package trafficpatterns
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model"
+	"skenario/pkg/simulator"
+)
+
+func TestSpike(t *testing.T) {
+	spec.Run(t, "Spike traffic pattern", testSpike, spec.Report(report.Terminal{}))
+}
+
+func testSpike(t *testing.T, describe spec.G, it spec.S) {
+	var subject Pattern
+	var envFake *model.FakeEnvironment
+	var trafficSource model.TrafficSource
+	var routingStock model.RequestsRoutingStock
+	var config SpikeConfig
+
+	it.Before(func() {
+		envFake = new(model.FakeEnvironment)
+		envFake.TheTime = time.Unix(0, 0)
+		envFake.TheHaltTime = envFake.TheTime.Add(100 * time.Second)
+
+		routingStock = model.NewRequestsRoutingStock(envFake, model.NewReplicasActiveStock(envFake), simulator.NewSinkStock("Failed", "Request"))
+		trafficSource = model.NewTrafficSource(envFake, routingStock, model.RequestConfig{CPUTimeMillis: 500, IOTimeMillis: 500, Timeout: 1 * time.Second})
+
+		config = SpikeConfig{
+			BaseRPS:        10,
+			Onset:          10 * time.Second,
+			PeakMultiplier: 10,
+			DecayTime:      10 * time.Second,
+		}
+	})
+
+	rateAt := func(t time.Duration) float64 {
+		return NewSpike(envFake, trafficSource, routingStock, config).(*spike).rateAt(t)
+	}
+
+	describe("Name()", func() {
+		it("calls itself 'spike'", func() {
+			subject = NewSpike(envFake, trafficSource, routingStock, config)
+			assert.Equal(t, "spike", subject.Name())
+		})
+	})
+
+	describe("rateAt()", func() {
+		it("is the base rate before the onset", func() {
+			assert.Equal(t, 10.0, rateAt(9*time.Second))
+		})
+
+		it("jumps to the peak at the onset when there is no rise time", func() {
+			assert.Equal(t, 100.0, rateAt(10*time.Second))
+		})
+
+		it("climbs linearly to the peak over the rise time", func() {
+			config.RiseTime = 4 * time.Second
+			assert.Equal(t, 10.0, rateAt(10*time.Second))
+			assert.Equal(t, 55.0, rateAt(12*time.Second))
+			assert.Equal(t, 100.0, rateAt(14*time.Second))
+		})
+
+		it("decays exponentially by default", func() {
+			assert.InDelta(t, 10+90*math.Exp(-1), rateAt(20*time.Second), 0.0001)
+			assert.InDelta(t, 10, rateAt(99*time.Second), 0.1)
+		})
+
+		it("decays by a power law", func() {
+			config.Decay = SpikeDecayPowerLaw
+			assert.InDelta(t, 10+90.0/2, rateAt(20*time.Second), 0.0001)
+			assert.InDelta(t, 10+90.0/10, rateAt(100*time.Second), 0.0001)
+		})
+
+		it("decays by a power law with the given exponent", func() {
+			config.Decay = SpikeDecayPowerLaw
+			config.DecayExponent = 2
+			assert.InDelta(t, 10+90.0/4, rateAt(20*time.Second), 0.0001)
+		})
+
+		describe("repeated spikes", func() {
+			it.Before(func() {
+				config.Every = 30 * time.Second
+			})
+
+			it("spikes again every interval", func() {
+				assert.Equal(t, 100.0, rateAt(40*time.Second))
+				assert.Equal(t, 100.0, rateAt(70*time.Second))
+				assert.InDelta(t, rateAt(20*time.Second), rateAt(50*time.Second), 0.0001)
+			})
+
+			it("stops after the given number of spikes", func() {
+				config.Repeats = 2
+				assert.Equal(t, 100.0, rateAt(40*time.Second))
+				assert.InDelta(t, 10+90*math.Exp(-3), rateAt(70*time.Second), 0.0001)
+			})
+		})
+	})
+
+	describe("Generate()", func() {
+		it.Before(func() {
+			subject = NewSpike(envFake, trafficSource, routingStock, config)
+			subject.Generate()
+		})
+
+		it("sends the baseline plus the spike's excess", func() {
+			expected := 10*100 + 90*10*(1-math.Exp(-9))
+			assert.InDelta(t, expected, len(envFake.Movements), expected*0.1)
+		})
+
+		it("sends requests to the routing stock within the run", func() {
+			for _, mv := range envFake.Movements {
+				assert.Equal(t, simulator.MovementKind("arrive_at_routing_stock"), mv.Kind())
+				assert.True(t, mv.OccursAt().After(envFake.TheTime))
+				assert.True(t, mv.OccursAt().Before(envFake.TheHaltTime.Add(1*time.Nanosecond)))
+			}
+		})
+	})
+
+	describe("GenerateUntil()", func() {
+		it("only generates up to the given time", func() {
+			subject = NewSpike(envFake, trafficSource, routingStock, config)
+			subject.(IncrementalPattern).GenerateUntil(envFake.TheTime.Add(10 * time.Second))
+
+			assert.InDelta(t, 100, len(envFake.Movements), 40)
+			for _, mv := range envFake.Movements {
+				assert.True(t, mv.OccursAt().Before(envFake.TheTime.Add(10*time.Second).Add(1*time.Nanosecond)))
+			}
+		})
+	})
+
+	describe("NewSpike()", func() {
+		it("panics on negative rates", func() {
+			config.BaseRPS = -1
+			assert.Panics(t, func() {
+				NewSpike(envFake, trafficSource, routingStock, config)
+			})
+		})
+
+		it("panics without a decay time", func() {
+			config.DecayTime = 0
+			assert.Panics(t, func() {
+				NewSpike(envFake, trafficSource, routingStock, config)
+			})
+		})
+
+		it("panics on an unknown decay", func() {
+			config.Decay = "linear"
+			assert.Panics(t, func() {
+				NewSpike(envFake, trafficSource, routingStock, config)
+			})
+		})
+	})
+}
@@ -28,6 +28,8 @@ type step struct {
 	stepAfter    time.Duration
 	source       model.TrafficSource
 	routingStock model.RequestsRoutingStock
+	started      bool
+	next         time.Time // the start of the next second to generate
 }
 
 type StepConfig struct {
@@ -40,19 +42,38 @@ func (*step) Name() string {
 }
 
 func (s *step) Generate() {
-	var t time.Time
-	startAt := s.env.CurrentMovementTime().Add(s.stepAfter)
+	s.GenerateUntil(s.env.HaltTime())
+}
+
+func (s *step) GenerateUntil(until time.Time) {
+	if !s.started {
+		s.started = true
+		s.next = s.env.CurrentMovementTime().Add(s.stepAfter)
+	}
 
-	for t = startAt; t.Before(s.env.HaltTime()); t = t.Add(1 * time.Second) {
+	for ; s.next.Before(until) && s.next.Before(s.env.HaltTime()); s.next = s.next.Add(1 * time.Second) {
 		uniRand := NewUniformRandom(s.env, s.source, s.routingStock, UniformConfig{
 			NumberOfRequests: s.rps,
-			StartAt:          t,
+			StartAt:          s.next,
 			RunFor:           time.Second,
 		})
 		uniRand.Generate()
 	}
 }
 
+func init() {
+	Register(Registration{
+		Name:        "step",
+		Description: "Sends nothing, then a constant rate from step_after on.",
+		Config: func() interface{} {
+			return &StepConfig{RPS: 10, StepAfter: 10 * time.Second}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			return NewStep(w.Env, w.Source, w.RoutingStock, *config.(*StepConfig))
+		},
+	})
+}
+
 func NewStep(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config StepConfig) Pattern {
 	return &step{
 		env:          env,
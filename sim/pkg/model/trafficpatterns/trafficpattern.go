@@ -15,7 +15,17 @@
 
 package trafficpatterns
 
+import "time"
+
 type Pattern interface {
 	Name() string
 	Generate()
 }
+
+// IncrementalPattern can generate its traffic a piece at a time. GenerateUntil schedules the arrivals
+// before until that it has not scheduled yet, and may schedule some beyond it. Generate is the same as
+// generating until the halt time.
+type IncrementalPattern interface {
+	Pattern
+	GenerateUntil(until time.Time)
+}
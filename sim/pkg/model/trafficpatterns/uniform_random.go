@@ -16,6 +16,7 @@
 package trafficpatterns
 
 import (
+	"math"
 	"math/rand"
 	"time"
 
@@ -30,6 +31,9 @@ type uniformRandom struct {
 	numberOfRequests int
 	startAt          time.Time
 	runFor           time.Duration
+	generated        int
+	drawn            bool
+	next             float64 // nanoseconds after startAt of the next request, once drawn
 }
 
 type UniformConfig struct {
@@ -56,6 +60,53 @@ func (ur *uniformRandom) Generate() {
 	}
 }
 
+// GenerateUntil draws the same uniform times as Generate, but in order: each is the earliest
+// of the requests still to come, spread over what is left of runFor.
+func (ur *uniformRandom) GenerateUntil(until time.Time) {
+	runFor := float64(ur.runFor.Nanoseconds())
+	for ; ur.generated < ur.numberOfRequests; ur.generated++ {
+		if !ur.drawn {
+			remaining := float64(ur.numberOfRequests - ur.generated)
+			ur.next = ur.next + (runFor-ur.next)*(1-math.Pow(rand.Float64(), 1/remaining))
+			ur.drawn = true
+		}
+
+		at := ur.startAt.Add(time.Duration(ur.next))
+		if !at.Before(until) {
+			return
+		}
+
+		ur.drawn = false
+		ur.env.AddToSchedule(simulator.NewMovement(
+			"arrive_at_routing_stock",
+			at,
+			ur.source,
+			ur.routingStock,
+			nil,
+		))
+	}
+}
+
+func init() {
+	Register(Registration{
+		Name:        "golang_rand_uniform",
+		Description: "Sends a number of requests at uniformly random times, by default over the whole run.",
+		Config: func() interface{} {
+			return &UniformConfig{NumberOfRequests: 100}
+		},
+		New: func(w Wiring, config interface{}) Pattern {
+			c := *config.(*UniformConfig)
+			if c.StartAt.IsZero() {
+				c.StartAt = w.Env.CurrentMovementTime()
+			}
+			if c.RunFor == 0 {
+				c.RunFor = w.Env.HaltTime().Sub(c.StartAt)
+			}
+			return NewUniformRandom(w.Env, w.Source, w.RoutingStock, c)
+		},
+	})
+}
+
 func NewUniformRandom(env simulator.Environment, source model.TrafficSource, routingStock model.RequestsRoutingStock, config UniformConfig) Pattern {
 	return &uniformRandom{
 		env:              env,
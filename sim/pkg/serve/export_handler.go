@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug"
+	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
+
+	"skenario/pkg/loadtest"
+)
+
+// ExportRequest asks for a traffic pattern as input to a load generator.
+type ExportRequest struct {
+	TrafficPattern string          `json:"traffic_pattern"`
+	TrafficConfig  json.RawMessage `json:"traffic_config,omitempty"`
+	RunFor         time.Duration   `json:"run_for"`
+	StartAt        time.Time       `json:"start_at,omitempty"`
+	Format         string          `json:"format"`           // vegeta, k6 or csv
+	Target         string          `json:"target,omitempty"` // the vegeta target, e.g. "GET http://staging.example.com/"
+}
+
+func ExportHandler(generators dispatcher.TrafficDispatcher) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		exportReq := &ExportRequest{}
+		err := json.NewDecoder(r.Body).Decode(exportReq)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		startAt := defaultStartAt
+		if !exportReq.StartAt.IsZero() {
+			startAt = exportReq.StartAt
+		}
+
+		var export bytes.Buffer
+		err = loadtest.ExportPattern(&export, loadtest.Options{
+			Pattern: exportReq.TrafficPattern,
+			Config:  exportReq.TrafficConfig,
+			StartAt: startAt,
+			RunFor:  exportReq.RunFor,
+			Format:  exportReq.Format,
+			Target:  exportReq.Target,
+			TrafficGenerator: func(generatorType string) skplug.TrafficGenerator {
+				return trafficGenerator(generators, generatorType)
+			},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", loadtest.ContentType(exportReq.Format))
+		_, err = export.WriteTo(w)
+		if err != nil {
+			panic(err.Error())
+		}
+	}
+}
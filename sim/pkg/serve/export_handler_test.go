@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func testExportHandler(t *testing.T, describe spec.G, it spec.S) {
+	var recorder *httptest.ResponseRecorder
+
+	export := func(exportReq ExportRequest) {
+		body := new(bytes.Buffer)
+		assert.NoError(t, json.NewEncoder(body).Encode(exportReq))
+
+		req, err := http.NewRequest("POST", "/export", body)
+		assert.NoError(t, err)
+
+		recorder = httptest.NewRecorder()
+		ExportHandler(nil)(recorder, req)
+	}
+
+	describe("a pattern that can be exported", func() {
+		it.Before(func() {
+			export(ExportRequest{
+				TrafficPattern: "step",
+				TrafficConfig:  json.RawMessage(`{"rps": 4, "step_after": 2000000000}`),
+				RunFor:         4 * time.Second,
+				Format:         "csv",
+			})
+		})
+
+		it("responds with the export", func() {
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "second,requests\n0,0\n1,0\n2,4\n3,4\n", recorder.Body.String())
+		})
+
+		it("sets the content type of the format", func() {
+			assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+		})
+	})
+
+	describe("a request that cannot be exported", func() {
+		it("responds with a bad request", func() {
+			export(ExportRequest{TrafficPattern: "step", RunFor: 4 * time.Second, Format: "jmeter"})
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	})
+}
@@ -20,7 +20,9 @@ import (
 	"fmt"
 	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
 	"net/http"
+	"reflect"
 	"skenario/pkg/simulator"
+	"strings"
 	"time"
 
 	"github.com/bvinc/go-sqlite-lite/sqlite3"
@@ -30,9 +32,10 @@ import (
 	"skenario/pkg/data"
 	"skenario/pkg/model"
 	"skenario/pkg/model/trafficpatterns"
+	"skenario/pkg/slo"
 )
 
-var startAt = time.Unix(0, 0)
+var defaultStartAt = time.Unix(0, 0)
 
 type TallyLine struct {
 	OccursAt    int64  `json:"occurs_at"`
@@ -41,6 +44,13 @@ type TallyLine struct {
 	Tally       int64  `json:"tally"`
 }
 
+type RevisionTallyLine struct {
+	OccursAt  int64  `json:"occurs_at"`
+	StockName string `json:"stock_name"`
+	Revision  int    `json:"revision"`
+	Tally     int64  `json:"tally"`
+}
+
 type ResponseTime struct {
 	ArrivedAt    int64 `json:"arrived_at"`
 	CompletedAt  int64 `json:"completed_at"`
@@ -57,31 +67,127 @@ type CPUUtilizationMetric struct {
 	CalculatedAt   int64   `json:"calculated_at"`
 }
 
-type SkenarioRunResponse struct {
-	RanFor            time.Duration          `json:"ran_for"`
+type CostPoint struct {
+	At       int64   `json:"at"`
+	CPUCores float64 `json:"cpu_cores"`
+	MemoryGB float64 `json:"memory_gb"`
+	Cost     float64 `json:"cost"` // cumulative
+}
+
+type CostResponse struct {
+	Total         float64     `json:"total"`
+	CPUCoreHours  float64     `json:"cpu_core_hours"`
+	MemoryGBHours float64     `json:"memory_gb_hours"`
+	Series        []CostPoint `json:"series"`
+}
+
+type SLOViolation struct {
+	From  int64 `json:"from"`
+	Until int64 `json:"until"`
+}
+
+type SLOResult struct {
+	Name              string         `json:"name"`
+	Passed            bool           `json:"passed"`
+	ErrorBudgetBurned float64        `json:"error_budget_burned"`
+	Violations        []SLOViolation `json:"violations"`
+}
+
+type ConstrainedRecommendation struct {
+	RecommendedAt int64  `json:"recommended_at"`
+	Recommended   int32  `json:"recommended"`
+	Target        int32  `json:"target"`
+	Constraint    string `json:"constraint,omitempty"`
+	ActuatesAt    int64  `json:"actuates_at"`
+}
+
+type DeliveredStat struct {
+	Time    int64  `json:"time"`
+	PodName string `json:"pod_name"`
+	Type    string `json:"type"`
+	Name    string `json:"name,omitempty"`
+	Value   int64  `json:"value"`
+}
+
+type AutoscalerDecisionLine struct {
+	TickAt                   int64           `json:"tick_at"`
+	StatsDelivered           int             `json:"stats_delivered"`
+	Stats                    []DeliveredStat `json:"stats"`
+	CurrentReplicas          int32           `json:"current_replicas"`
+	DesiredReplicas          int32           `json:"desired_replicas"`
+	ReadyReplicas            int32           `json:"ready_replicas"`
+	HorizontalRecommendation int32           `json:"horizontal_recommendation"`
+	HorizontalTarget         int32           `json:"horizontal_target"`
+	HorizontalApplied        bool            `json:"horizontal_applied"`
+	Delta                    int32           `json:"delta"`
+	VerticalCPUTarget        int64           `json:"vertical_cpu_target,omitempty"`
+	VerticalApplied          bool            `json:"vertical_applied"`
+}
+
+// ServiceRunResponse holds the results for one of the services simulated in a run.
+type ServiceRunResponse struct {
+	Name              string                 `json:"name,omitempty"`
+	ScenarioRunId     int64                  `json:"scenario_run_id"`
 	TrafficPattern    string                 `json:"traffic_pattern"`
 	TallyLines        []TallyLine            `json:"tally_lines"`
+	RevisionTally     []RevisionTallyLine    `json:"revision_tally_lines"`
 	ResponseTimes     []ResponseTime         `json:"response_times"`
 	RequestsPerSecond []RPS                  `json:"requests_per_second"`
 	CPUUtilizations   []CPUUtilizationMetric `json:"cpu_utilizations"`
+	Cost              CostResponse           `json:"cost"`
+	SLOs              []SLOResult            `json:"slos"`
+
+	ConstrainedRecommendations []ConstrainedRecommendation `json:"constrained_recommendations"`
+	AutoscalerDecisions        []AutoscalerDecisionLine    `json:"autoscaler_decisions"`
 }
 
-type SkenarioRunRequest struct {
-	RunFor           time.Duration `json:"run_for"`
-	TrafficPattern   string        `json:"traffic_pattern"`
-	InMemoryDatabase bool          `json:"in_memory_database,omitempty"`
+type SkenarioRunResponse struct {
+	RanFor time.Duration `json:"ran_for"`
+
+	// The first service's results, for clients that only simulate one service.
+	ServiceRunResponse
+
+	Services []ServiceRunResponse `json:"services"`
+}
+
+// ServiceRequest describes a single service: its replicas, autoscaler, requests and traffic.
+type ServiceRequest struct {
+	Name           string `json:"name,omitempty"`
+	TrafficPattern string `json:"traffic_pattern"`
+	// LazyTraffic generates traffic a second at a time as the run reaches it, rather than all up front,
+	// to keep long runs in bounded memory. Composite patterns are still generated up front, and closed_loop
+	// users only ever send requests as they go.
+	LazyTraffic bool `json:"lazy_traffic,omitempty"`
 
 	InitialNumberOfReplicas uint `json:"initial_number_of_replicas"`
 
 	LaunchDelay    time.Duration `json:"launch_delay"`
 	TerminateDelay time.Duration `json:"terminate_delay"`
 	TickInterval   time.Duration `json:"tick_interval"`
+	CPUModel       string        `json:"cpu_model,omitempty"`
+
+	MemoryRequestMB float64     `json:"memory_request_mb,omitempty"`
+	Cost            CostRequest `json:"cost,omitempty"`
+
+	SLOs []SLORequest `json:"slos,omitempty"`
 
 	Plugins map[string]string `json:"plugins"`
 
-	RequestTimeout       time.Duration `json:"request_timeout_nanos"`
-	RequestCPUTimeMillis int           `json:"request_cpu_time_millis"`
-	RequestIOTimeMillis  int           `json:"request_io_time_millis"`
+	RequestTimeout       time.Duration        `json:"request_timeout_nanos"`
+	RequestCPUTimeMillis int                  `json:"request_cpu_time_millis"`
+	RequestIOTimeMillis  int                  `json:"request_io_time_millis"`
+	Calls                []ServiceCallRequest `json:"calls,omitempty"`
+
+	MetricAliases []MetricAliasRequest `json:"metric_aliases,omitempty"`
+	Metrics       MetricsRequest       `json:"metrics,omitempty"`
+	HPA           HPARequest           `json:"hpa,omitempty"`
+	Rollouts      []RolloutRequest     `json:"rollouts,omitempty"`
+	VPA           VPARequest           `json:"vpa,omitempty"`
+	Warmup        WarmupRequest        `json:"warmup,omitempty"`
+
+	// TrafficConfig is the config of the traffic pattern, as described by GET /traffic-patterns. When it is
+	// empty, the original patterns fall back to their own fields below.
+	TrafficConfig json.RawMessage `json:"traffic_config,omitempty"`
 
 	UniformConfig    trafficpatterns.UniformConfig    `json:"uniform_config,omitempty"`
 	RampConfig       trafficpatterns.RampConfig       `json:"ramp_config,omitempty"`
@@ -89,9 +195,120 @@ type SkenarioRunRequest struct {
 	SinusoidalConfig trafficpatterns.SinusoidalConfig `json:"sinusoidal_config,omitempty"`
 }
 
+// ServiceCallRequest makes each request to a service call another service, by name.
+type ServiceCallRequest struct {
+	Service     string  `json:"service"`
+	FanOut      int     `json:"fan_out,omitempty"`     // defaults to 1
+	Probability float64 `json:"probability,omitempty"` // defaults to 1
+}
+
+// MetricAliasRequest reports one of the built-in metric types to the autoscaler again under a
+// new name, as a CUSTOM metric. Source is a MetricType name from skplug.proto, e.g.
+// "requests_per_second_millis".
+type MetricAliasRequest struct {
+	Name   string  `json:"name"`
+	Source string  `json:"source"`
+	Scale  float64 `json:"scale,omitempty"` // defaults to 1
+}
+
+// MetricsRequest configures how metrics are scraped from replicas and delivered to the autoscaler.
+type MetricsRequest struct {
+	ScrapeInterval   time.Duration            `json:"scrape_interval,omitempty"`
+	FirstScrapeAfter time.Duration            `json:"first_scrape_after,omitempty"`
+	Lag              model.DistributionConfig `json:"lag,omitempty"`
+	Window           time.Duration            `json:"window,omitempty"`
+	DropProbability  float64                  `json:"drop_probability,omitempty"`
+	StaleProbability float64                  `json:"stale_probability,omitempty"`
+	Outages          []MetricsOutageRequest   `json:"outages,omitempty"`
+}
+
+// MetricsOutageRequest is a metrics-server outage, starting After the start of the run.
+type MetricsOutageRequest struct {
+	After    time.Duration `json:"after"`
+	Duration time.Duration `json:"duration"`
+}
+
+// HPARequest bounds the replicas the autoscaler can ask for, and delays acting on its recommendations.
+type HPARequest struct {
+	MinReplicas    int32         `json:"min_replicas,omitempty"`
+	MaxReplicas    int32         `json:"max_replicas,omitempty"`
+	CPUQuotaMillis float64       `json:"cpu_quota_millis,omitempty"`
+	MemoryQuotaMB  float64       `json:"memory_quota_mb,omitempty"`
+	ActuationDelay time.Duration `json:"actuation_delay,omitempty"`
+}
+
+// RolloutRequest replaces the replicas with a new revision, starting After the start of the run.
+type RolloutRequest struct {
+	After          time.Duration `json:"after"`
+	MaxSurge       int           `json:"max_surge,omitempty"`
+	MaxUnavailable int           `json:"max_unavailable,omitempty"`
+	CPUCostFactor  float64       `json:"cpu_cost_factor,omitempty"` // the new revision's CPU time per request, relative to request_cpu_time_millis
+}
+
+// VPARequest configures how the vertical autoscaler's recommendations are applied.
+type VPARequest struct {
+	UpdateMode        string        `json:"update_mode,omitempty"` // Off, Initial, Recreate, InPlace or Auto (the default)
+	EvictionRateLimit float64       `json:"eviction_rate_limit,omitempty"`
+	EvictionRateBurst int           `json:"eviction_rate_burst,omitempty"`
+	MinAvailable      int           `json:"min_available,omitempty"`
+	EvictionFraction  float64       `json:"eviction_fraction,omitempty"`
+	ResizeDelay       time.Duration `json:"resize_delay,omitempty"`
+}
+
+// WarmupRequest slows new replicas down and makes them burn extra CPU, easing off as they age.
+type WarmupRequest struct {
+	Duration        time.Duration `json:"duration,omitempty"`
+	Curve           string        `json:"curve,omitempty"` // linear (the default), step or exponential
+	InitialSlowdown float64       `json:"initial_slowdown,omitempty"`
+	InitialCPUBoost float64       `json:"initial_cpu_boost,omitempty"`
+}
+
+// CostRequest prices the CPU and memory requested by replicas.
+type CostRequest struct {
+	CPUCoreHourPrice  float64       `json:"cpu_core_hour_price,omitempty"`
+	MemoryGBHourPrice float64       `json:"memory_gb_hour_price,omitempty"`
+	Interval          time.Duration `json:"interval,omitempty"` // between points of the series, defaults to 10s
+}
+
+// SLORequest is a service level objective to evaluate the run against. A latency SLO needs a percentile
+// and a threshold, an error rate SLO a max error rate. Without a window it is evaluated over the whole run.
+type SLORequest struct {
+	Name         string        `json:"name"`
+	Kind         string        `json:"kind"` // latency or error_rate
+	Percentile   float64       `json:"percentile,omitempty"`
+	Threshold    time.Duration `json:"threshold,omitempty"`
+	MaxErrorRate float64       `json:"max_error_rate,omitempty"`
+	Window       time.Duration `json:"window,omitempty"`
+}
+
+type SkenarioRunRequest struct {
+	RunFor           time.Duration `json:"run_for"`
+	StartAt          time.Time     `json:"start_at,omitempty"` // the wall-clock time the scenario starts at, defaults to the Unix epoch
+	InMemoryDatabase bool          `json:"in_memory_database,omitempty"`
+
+	// Used when Services is empty, so that a single service can be given inline.
+	ServiceRequest
+
+	Services []ServiceRequest `json:"services,omitempty"`
+}
+
+// serviceRun is a service's model, built in its own partition of the run's Environment.
+type serviceRun struct {
+	name        string
+	env         simulator.PartitionEnvironment
+	clusterConf model.ClusterConfig
+	asConf      model.AutoscalerConfig
+	costConf    model.CostConfig
+	objectives  []slo.Objective
+	cluster     model.ClusterModel
+	autoscaler  model.AutoscalerModel
+	traffic     trafficpatterns.Pattern
+	source      model.TrafficSource
+}
+
 var environmentSequence int32 = 0
 
-func RunHandler(dispatcher *dispatcher.Dispatcher) func(w http.ResponseWriter, r *http.Request) {
+func RunHandler(dispatcher *dispatcher.Dispatcher, generators dispatcher.TrafficDispatcher) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
@@ -101,40 +318,30 @@ func RunHandler(dispatcher *dispatcher.Dispatcher) func(w http.ResponseWriter, r
 			panic(err.Error())
 		}
 
+		startAt := defaultStartAt
+		if !runReq.StartAt.IsZero() {
+			startAt = runReq.StartAt
+		}
+
 		env := simulator.NewEnvironment(r.Context(), startAt, runReq.RunFor, dispatcher)
 
-		clusterConf := buildClusterConfig(runReq)
-		asConf := buildAutoscalerConfig(runReq)
-		replicasConfig := model.ReplicasConfig{
-			LaunchDelay:    runReq.LaunchDelay,
-			TerminateDelay: runReq.TerminateDelay,
+		services := runReq.Services
+		if len(services) == 0 {
+			services = []ServiceRequest{runReq.ServiceRequest}
 		}
 
-		requestConfig := model.RequestConfig{
-			CPUTimeMillis: runReq.RequestCPUTimeMillis,
-			IOTimeMillis:  runReq.RequestIOTimeMillis,
-			Timeout:       runReq.RequestTimeout,
+		// downstream services are built first, so that upstream services can call them
+		runs := make([]*serviceRun, len(services))
+		sources := make(map[string]model.TrafficSource)
+		for _, i := range serviceBuildOrder(services) {
+			runs[i] = buildServiceRun(env, dispatcher, generators, startAt, &services[i], sources)
+			sources[services[i].Name] = runs[i].source
 		}
 
-		cluster := model.NewCluster(env, clusterConf, replicasConfig)
-
-		model.NewAutoscaler(env, startAt, cluster, asConf)
-		trafficSource := model.NewTrafficSource(env, cluster.RoutingStock(), requestConfig)
-
-		var traffic trafficpatterns.Pattern
-		switch runReq.TrafficPattern {
-		case "golang_rand_uniform":
-			traffic = trafficpatterns.NewUniformRandom(env, trafficSource, cluster.RoutingStock(), runReq.UniformConfig)
-		case "step":
-			traffic = trafficpatterns.NewStep(env, trafficSource, cluster.RoutingStock(), runReq.StepConfig)
-		case "ramp":
-			traffic = trafficpatterns.NewRamp(env, trafficSource, cluster.RoutingStock(), runReq.RampConfig)
-		case "sinusoidal":
-			traffic = trafficpatterns.NewSinusoidal(env, trafficSource, cluster.RoutingStock(), runReq.SinusoidalConfig)
+		for _, run := range runs {
+			run.traffic.Generate()
 		}
 
-		traffic.Generate()
-
 		completed, ignored, err := env.Run()
 		if err != nil {
 			panic(err.Error())
@@ -154,18 +361,78 @@ func RunHandler(dispatcher *dispatcher.Dispatcher) func(w http.ResponseWriter, r
 		defer conn.Close()
 
 		store := data.NewRunStore(conn)
-		scenarioRunId, err := store.Store(completed, ignored, clusterConf, asConf, "skenario_web", traffic.Name(), runReq.RunFor, env.CPUUtilizations())
-		if err != nil {
-			fmt.Printf("there was an error saving data: %s", err.Error())
+		serviceResponses := make([]ServiceRunResponse, 0, len(runs))
+		var groupRunId int64
+		for i, run := range runs {
+			runCompleted, runIgnored := partitionMovements(run.env, completed, ignored)
+
+			scenarioRunId, err := store.Store(runCompleted, runIgnored, run.clusterConf, run.asConf, "skenario_web", run.traffic.Name(), runReq.RunFor, run.env.CPUUtilizations())
+			if err != nil {
+				fmt.Printf("there was an error saving data: %s", err.Error())
+			}
+
+			if len(runs) > 1 {
+				if i == 0 {
+					groupRunId = scenarioRunId
+				}
+				err = store.StoreService(scenarioRunId, groupRunId, run.name)
+				if err != nil {
+					fmt.Printf("there was an error saving service data: %s", err.Error())
+				}
+			}
+
+			cost := run.cluster.Cost(run.costConf, startAt, env.HaltTime())
+			err = store.StoreCost(scenarioRunId, run.costConf, cost)
+			if err != nil {
+				fmt.Printf("there was an error saving cost data: %s", err.Error())
+			}
+
+			err = store.StoreConstrainedDecisions(scenarioRunId, run.autoscaler.ConstrainedDecisions())
+			if err != nil {
+				fmt.Printf("there was an error saving constrained recommendations: %s", err.Error())
+			}
+
+			err = store.StoreDecisions(scenarioRunId, run.autoscaler.Decisions())
+			if err != nil {
+				fmt.Printf("there was an error saving autoscaler decisions: %s", err.Error())
+			}
+
+			err = store.StoreReplicaRevisions(scenarioRunId, run.cluster.ReplicaRevisions())
+			if err != nil {
+				fmt.Printf("there was an error saving replica revisions: %s", err.Error())
+			}
+
+			err = store.StoreRequestClasses(scenarioRunId, run.source.RequestClasses())
+			if err != nil {
+				fmt.Printf("there was an error saving request classes: %s", err.Error())
+			}
+
+			serviceResponses = append(serviceResponses, ServiceRunResponse{
+				Name:              run.name,
+				ScenarioRunId:     scenarioRunId,
+				TrafficPattern:    run.traffic.Name(),
+				TallyLines:        tallyLines(dbFileName, scenarioRunId),
+				RevisionTally:     revisionTallyLines(dbFileName, scenarioRunId),
+				ResponseTimes:     responseTimes(dbFileName, scenarioRunId),
+				RequestsPerSecond: requestsPerSecond(dbFileName, scenarioRunId),
+				CPUUtilizations:   cpuUtilizations(dbFileName, scenarioRunId),
+				Cost: CostResponse{
+					Total:         cost.Total,
+					CPUCoreHours:  cost.CPUCoreHours,
+					MemoryGBHours: cost.MemoryGBHours,
+					Series:        costSeries(dbFileName, scenarioRunId),
+				},
+				SLOs: sloResults(run.objectives, requestOutcomes(dbFileName, scenarioRunId), startAt, env.HaltTime()),
+
+				ConstrainedRecommendations: constrainedRecommendations(dbFileName, scenarioRunId),
+				AutoscalerDecisions:        autoscalerDecisions(dbFileName, scenarioRunId),
+			})
 		}
 
 		var vds = SkenarioRunResponse{
-			RanFor:            env.HaltTime().Sub(startAt),
-			TrafficPattern:    traffic.Name(),
-			TallyLines:        tallyLines(dbFileName, scenarioRunId),
-			ResponseTimes:     responseTimes(dbFileName, scenarioRunId),
-			RequestsPerSecond: requestsPerSecond(dbFileName, scenarioRunId),
-			CPUUtilizations:   cpuUtilizations(dbFileName, scenarioRunId),
+			RanFor:             env.HaltTime().Sub(startAt),
+			ServiceRunResponse: serviceResponses[0],
+			Services:           serviceResponses,
 		}
 
 		err = json.NewEncoder(w).Encode(vds)
@@ -174,11 +441,270 @@ func RunHandler(dispatcher *dispatcher.Dispatcher) func(w http.ResponseWriter, r
 			return
 		}
 
-		err = env.Plugin().Event(startAt.UnixNano(), proto.EventType_DELETE, &skplug.Autoscaler{})
+		for _, run := range runs {
+			err = run.env.Plugin().Event(startAt.UnixNano(), proto.EventType_DELETE, &skplug.Autoscaler{})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+func buildServiceRun(env simulator.Environment, dispatcher *dispatcher.Dispatcher, generators dispatcher.TrafficDispatcher, startAt time.Time, svc *ServiceRequest, sources map[string]model.TrafficSource) *serviceRun {
+	run := &serviceRun{
+		name:        svc.Name,
+		env:         simulator.NewPartitionEnvironment(env, dispatcher),
+		clusterConf: buildClusterConfig(svc),
+		asConf:      buildAutoscalerConfig(svc),
+		costConf: model.CostConfig{
+			CPUCoreHourPrice:  svc.Cost.CPUCoreHourPrice,
+			MemoryGBHourPrice: svc.Cost.MemoryGBHourPrice,
+			Interval:          svc.Cost.Interval,
+		},
+		objectives: buildObjectives(svc),
+	}
+
+	replicasConfig := model.ReplicasConfig{
+		LaunchDelay:    svc.LaunchDelay,
+		TerminateDelay: svc.TerminateDelay,
+		CPUModel:       model.CPUModel(svc.CPUModel),
+		MetricAliases:  buildMetricAliases(svc),
+		Metrics:        buildMetricsConfig(svc, startAt),
+		Warmup:         buildWarmupConfig(svc),
+
+		MemoryRequestMB: svc.MemoryRequestMB,
+	}
+
+	requestConfig := model.RequestConfig{
+		CPUTimeMillis: svc.RequestCPUTimeMillis,
+		IOTimeMillis:  svc.RequestIOTimeMillis,
+		Timeout:       svc.RequestTimeout,
+		Calls:         buildServiceCalls(svc, sources),
+	}
+
+	cluster := model.NewCluster(run.env, run.clusterConf, replicasConfig)
+	run.cluster = cluster
+
+	run.autoscaler = model.NewAutoscaler(run.env, startAt, cluster, run.asConf)
+	for _, rollout := range buildRolloutConfigs(svc, startAt) {
+		model.NewRolloutStock(run.env, cluster, rollout)
+	}
+	trafficSource := model.NewTrafficSource(run.env, cluster.RoutingStock(), requestConfig)
+	run.source = trafficSource
+
+	run.traffic = trafficpatterns.Build(trafficpatterns.Wiring{
+		Env:          run.env,
+		Source:       trafficSource,
+		RoutingStock: cluster.RoutingStock(),
+		TrafficGenerator: func(generatorType string) skplug.TrafficGenerator {
+			return trafficGenerator(generators, generatorType)
+		},
+	}, svc.TrafficPattern, svc.trafficConfig())
+
+	if svc.LazyTraffic {
+		run.traffic = trafficpatterns.NewLazy(run.env, run.traffic, time.Second)
+	}
+
+	return run
+}
+
+// trafficConfig is the raw config for the service's traffic pattern, or nil to use the pattern's defaults.
+func (svc *ServiceRequest) trafficConfig() json.RawMessage {
+	if len(svc.TrafficConfig) > 0 {
+		return svc.TrafficConfig
+	}
+
+	patternConfigs := map[string]interface{}{
+		"golang_rand_uniform": svc.UniformConfig,
+		"step":                svc.StepConfig,
+		"ramp":                svc.RampConfig,
+		"sinusoidal":          svc.SinusoidalConfig,
+	}
+	config, ok := patternConfigs[svc.TrafficPattern]
+	if !ok || reflect.ValueOf(config).IsZero() {
+		return nil
+	}
+
+	raw, err := json.Marshal(config)
+	if err != nil {
+		panic(err.Error())
+	}
+	return raw
+}
+
+// trafficGenerator finds the plugin for a generator type, or nil when no plugin has it.
+func trafficGenerator(generators dispatcher.TrafficDispatcher, generatorType string) skplug.TrafficGenerator {
+	if generators == nil {
+		return nil
+	}
+	generator, ok := generators.GetGenerator(generatorType)
+	if !ok {
+		return nil
+	}
+	return generator
+}
+
+func buildObjectives(svc *ServiceRequest) []slo.Objective {
+	objectives := make([]slo.Objective, 0, len(svc.SLOs))
+	for _, s := range svc.SLOs {
+		objective := slo.Objective{
+			Name:         s.Name,
+			Kind:         slo.Kind(s.Kind),
+			Percentile:   s.Percentile,
+			Threshold:    s.Threshold,
+			MaxErrorRate: s.MaxErrorRate,
+			Window:       s.Window,
+		}
+		err := objective.Validate()
 		if err != nil {
 			panic(err)
 		}
+		objectives = append(objectives, objective)
+	}
+	return objectives
+}
+
+func sloResults(objectives []slo.Objective, eachRequest func(observe func(slo.Request)), startAt, haltTime time.Time) []SLOResult {
+	evaluators := make([]slo.Evaluator, 0, len(objectives))
+	for _, objective := range objectives {
+		evaluators = append(evaluators, slo.NewEvaluator(objective, startAt, haltTime))
+	}
+	eachRequest(func(request slo.Request) {
+		for _, evaluator := range evaluators {
+			evaluator.Observe(request)
+		}
+	})
+
+	results := make([]SLOResult, 0, len(objectives))
+	for i, objective := range objectives {
+		result := evaluators[i].Result()
+
+		violations := make([]SLOViolation, 0, len(result.Violations))
+		for _, v := range result.Violations {
+			violations = append(violations, SLOViolation{From: v.From.UnixNano(), Until: v.Until.UnixNano()})
+		}
+
+		results = append(results, SLOResult{
+			Name:              objective.Name,
+			Passed:            result.Passed,
+			ErrorBudgetBurned: result.ErrorBudgetBurned,
+			Violations:        violations,
+		})
 	}
+	return results
+}
+
+func buildMetricAliases(svc *ServiceRequest) []model.MetricAliasConfig {
+	metricAliases := make([]model.MetricAliasConfig, 0, len(svc.MetricAliases))
+	for _, ma := range svc.MetricAliases {
+		source, ok := proto.MetricType_value[strings.ToUpper(ma.Source)]
+		if !ok || proto.MetricType(source) == proto.MetricType_CUSTOM {
+			panic(fmt.Errorf("metric alias '%s' has unknown source metric '%s'", ma.Name, ma.Source))
+		}
+
+		scale := ma.Scale
+		if scale == 0 {
+			scale = 1
+		}
+
+		metricAliases = append(metricAliases, model.MetricAliasConfig{
+			Name:   ma.Name,
+			Source: proto.MetricType(source),
+			Scale:  scale,
+		})
+	}
+	return metricAliases
+}
+
+func buildServiceCalls(svc *ServiceRequest, sources map[string]model.TrafficSource) []model.ServiceCall {
+	calls := make([]model.ServiceCall, 0, len(svc.Calls))
+	for _, c := range svc.Calls {
+		source, ok := sources[c.Service]
+		if !ok {
+			panic(fmt.Errorf("service '%s' calls unknown service '%s'", svc.Name, c.Service))
+		}
+
+		probability := c.Probability
+		if probability == 0 {
+			probability = 1
+		}
+
+		calls = append(calls, model.ServiceCall{
+			Service:     source,
+			FanOut:      c.FanOut,
+			Probability: probability,
+		})
+	}
+	return calls
+}
+
+// serviceBuildOrder gives the indices of services so that every service comes after the services it calls.
+// Panics if services call each other in a cycle, or if several services are not named uniquely.
+func serviceBuildOrder(services []ServiceRequest) []int {
+	byName := make(map[string]int)
+	for i, svc := range services {
+		if len(services) > 1 && svc.Name == "" {
+			panic(fmt.Errorf("service %d has no name, but every service needs one when several are simulated", i))
+		}
+		if _, ok := byName[svc.Name]; ok {
+			panic(fmt.Errorf("more than one service is named '%s'", svc.Name))
+		}
+		byName[svc.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(services))
+	order := make([]int, 0, len(services))
+
+	var visit func(i int)
+	visit = func(i int) {
+		switch state[i] {
+		case visited:
+			return
+		case visiting:
+			panic(fmt.Errorf("service '%s' is part of a cycle of calls", services[i].Name))
+		}
+
+		state[i] = visiting
+		for _, c := range services[i].Calls {
+			callee, ok := byName[c.Service]
+			if !ok {
+				panic(fmt.Errorf("service '%s' calls unknown service '%s'", services[i].Name, c.Service))
+			}
+			visit(callee)
+		}
+		state[i] = visited
+		order = append(order, i)
+	}
+
+	for i := range services {
+		visit(i)
+	}
+
+	return order
+}
+
+// partitionMovements picks out the movements that belong to a single service.
+func partitionMovements(env simulator.PartitionEnvironment, completed []simulator.CompletedMovement, ignored []simulator.IgnoredMovement) ([]simulator.CompletedMovement, []simulator.IgnoredMovement) {
+	ownCompleted := make([]simulator.CompletedMovement, 0)
+	for _, c := range completed {
+		if env.Owns(c.Movement) {
+			ownCompleted = append(ownCompleted, c)
+		}
+	}
+
+	ownIgnored := make([]simulator.IgnoredMovement, 0)
+	for _, i := range ignored {
+		if env.Owns(i.Movement) {
+			ownIgnored = append(ownIgnored, i)
+		}
+	}
+
+	return ownCompleted, ownIgnored
 }
 
 func cpuUtilizations(dbFileName string, scenarioRunId int64) []CPUUtilizationMetric {
@@ -221,6 +747,47 @@ func cpuUtilizations(dbFileName string, scenarioRunId int64) []CPUUtilizationMet
 	return cpuUtilizations
 }
 
+func costSeries(dbFileName string, scenarioRunId int64) []CostPoint {
+	costConn, err := sqlite3.Open(dbFileName, sqlite3.OPEN_READONLY)
+	if err != nil {
+		panic(fmt.Errorf("could not open database file '%s': %s", dbFileName, err.Error()))
+	}
+	defer costConn.Close()
+
+	costStmt, err := costConn.Prepare(data.CostSeriesQuery, scenarioRunId)
+	if err != nil {
+		panic(fmt.Errorf("could not prepare query: %s", err.Error()))
+	}
+
+	var at int64
+	var cpuCores, memoryGB, cost float64
+	series := make([]CostPoint, 0)
+	for {
+		hasRow, err := costStmt.Step()
+		if err != nil {
+			panic(fmt.Errorf("could not step: %s", err.Error()))
+		}
+
+		if !hasRow {
+			break
+		}
+
+		err = costStmt.Scan(&at, &cpuCores, &memoryGB, &cost)
+		if err != nil {
+			panic(fmt.Errorf("could not scan: %s", err.Error()))
+		}
+
+		series = append(series, CostPoint{
+			At:       at,
+			CPUCores: cpuCores,
+			MemoryGB: memoryGB,
+			Cost:     cost,
+		})
+	}
+
+	return series
+}
+
 func tallyLines(dbFileName string, scenarioRunId int64) []TallyLine {
 	totalConn, err := sqlite3.Open(dbFileName, sqlite3.OPEN_READONLY)
 	if err != nil {
@@ -263,6 +830,48 @@ func tallyLines(dbFileName string, scenarioRunId int64) []TallyLine {
 	return tallyLines
 }
 
+func revisionTallyLines(dbFileName string, scenarioRunId int64) []RevisionTallyLine {
+	revisionConn, err := sqlite3.Open(dbFileName, sqlite3.OPEN_READONLY)
+	if err != nil {
+		panic(fmt.Errorf("could not open database file '%s': %s", dbFileName, err.Error()))
+	}
+	defer revisionConn.Close()
+
+	revisionStmt, err := revisionConn.Prepare(data.RevisionTallyQuery, scenarioRunId)
+	if err != nil {
+		panic(fmt.Errorf("could not prepare query: %s", err.Error()))
+	}
+
+	var occursAt, tally int64
+	var stockName string
+	var revision int
+	lines := make([]RevisionTallyLine, 0)
+	for {
+		hasRow, err := revisionStmt.Step()
+		if err != nil {
+			panic(fmt.Errorf("could not step: %s", err.Error()))
+		}
+
+		if !hasRow {
+			break
+		}
+
+		err = revisionStmt.Scan(&occursAt, &stockName, &revision, &tally)
+		if err != nil {
+			panic(fmt.Errorf("could not scan: %s", err.Error()))
+		}
+
+		lines = append(lines, RevisionTallyLine{
+			OccursAt:  occursAt,
+			StockName: stockName,
+			Revision:  revision,
+			Tally:     tally,
+		})
+	}
+
+	return lines
+}
+
 func responseTimes(dbFileName string, scenarioRunId int64) []ResponseTime {
 	responseConn, err := sqlite3.Open(dbFileName, sqlite3.OPEN_READONLY)
 	if err != nil {
@@ -303,6 +912,181 @@ func responseTimes(dbFileName string, scenarioRunId int64) []ResponseTime {
 	return responseTimes
 }
 
+// requestOutcomes streams the outcome of each request in the order they completed, rather than
+// loading them all at once.
+func requestOutcomes(dbFileName string, scenarioRunId int64) func(observe func(slo.Request)) {
+	return func(observe func(slo.Request)) {
+		outcomeConn, err := sqlite3.Open(dbFileName, sqlite3.OPEN_READONLY)
+		if err != nil {
+			panic(fmt.Errorf("could not open database file '%s': %s", dbFileName, err.Error()))
+		}
+		defer outcomeConn.Close()
+
+		outcomeStmt, err := outcomeConn.Prepare(data.RequestOutcomesQuery, scenarioRunId)
+		if err != nil {
+			panic(fmt.Errorf("could not prepare query: %s", err.Error()))
+		}
+		defer outcomeStmt.Close()
+
+		var arrivedAt, completedAt int64
+		var failed bool
+		for {
+			hasRow, err := outcomeStmt.Step()
+			if err != nil {
+				panic(fmt.Errorf("could not step: %s", err.Error()))
+			}
+
+			if !hasRow {
+				break
+			}
+
+			err = outcomeStmt.Scan(&arrivedAt, &completedAt, &failed)
+			if err != nil {
+				panic(fmt.Errorf("could not scan: %s", err.Error()))
+			}
+
+			observe(slo.Request{
+				ArrivedAt:   time.Unix(0, arrivedAt),
+				CompletedAt: time.Unix(0, completedAt),
+				Failed:      failed,
+			})
+		}
+	}
+}
+
+func constrainedRecommendations(dbFileName string, scenarioRunId int64) []ConstrainedRecommendation {
+	recConn, err := sqlite3.Open(dbFileName, sqlite3.OPEN_READONLY)
+	if err != nil {
+		panic(fmt.Errorf("could not open database file '%s': %s", dbFileName, err.Error()))
+	}
+	defer recConn.Close()
+
+	recStmt, err := recConn.Prepare(data.ConstrainedRecommendationsQuery, scenarioRunId)
+	if err != nil {
+		panic(fmt.Errorf("could not prepare query: %s", err.Error()))
+	}
+
+	var recommendedAt, actuatesAt int64
+	var recommended, target int
+	var constraint string
+	recommendations := make([]ConstrainedRecommendation, 0)
+	for {
+		hasRow, err := recStmt.Step()
+		if err != nil {
+			panic(fmt.Errorf("could not step: %s", err.Error()))
+		}
+
+		if !hasRow {
+			break
+		}
+
+		err = recStmt.Scan(&recommendedAt, &recommended, &target, &constraint, &actuatesAt)
+		if err != nil {
+			panic(fmt.Errorf("could not scan: %s", err.Error()))
+		}
+
+		recommendations = append(recommendations, ConstrainedRecommendation{
+			RecommendedAt: recommendedAt,
+			Recommended:   int32(recommended),
+			Target:        int32(target),
+			Constraint:    constraint,
+			ActuatesAt:    actuatesAt,
+		})
+	}
+
+	return recommendations
+}
+
+func autoscalerDecisions(dbFileName string, scenarioRunId int64) []AutoscalerDecisionLine {
+	decisionConn, err := sqlite3.Open(dbFileName, sqlite3.OPEN_READONLY)
+	if err != nil {
+		panic(fmt.Errorf("could not open database file '%s': %s", dbFileName, err.Error()))
+	}
+	defer decisionConn.Close()
+
+	statsStmt, err := decisionConn.Prepare(data.AutoscalerDecisionStatsQuery, scenarioRunId)
+	if err != nil {
+		panic(fmt.Errorf("could not prepare query: %s", err.Error()))
+	}
+	defer statsStmt.Close()
+
+	var decisionId, statAt, value int64
+	var podName, statType, name string
+	stats := make(map[int64][]DeliveredStat)
+	for {
+		hasRow, err := statsStmt.Step()
+		if err != nil {
+			panic(fmt.Errorf("could not step: %s", err.Error()))
+		}
+
+		if !hasRow {
+			break
+		}
+
+		err = statsStmt.Scan(&decisionId, &statAt, &podName, &statType, &name, &value)
+		if err != nil {
+			panic(fmt.Errorf("could not scan: %s", err.Error()))
+		}
+
+		stats[decisionId] = append(stats[decisionId], DeliveredStat{
+			Time:    statAt,
+			PodName: podName,
+			Type:    statType,
+			Name:    name,
+			Value:   value,
+		})
+	}
+
+	decisionStmt, err := decisionConn.Prepare(data.AutoscalerDecisionsQuery, scenarioRunId)
+	if err != nil {
+		panic(fmt.Errorf("could not prepare query: %s", err.Error()))
+	}
+	defer decisionStmt.Close()
+
+	var tickAt, verticalCPUTarget int64
+	var statsDelivered, current, desired, ready, recommendation, target, delta int
+	var horizontalApplied, verticalApplied bool
+	decisions := make([]AutoscalerDecisionLine, 0)
+	for {
+		hasRow, err := decisionStmt.Step()
+		if err != nil {
+			panic(fmt.Errorf("could not step: %s", err.Error()))
+		}
+
+		if !hasRow {
+			break
+		}
+
+		err = decisionStmt.Scan(&decisionId, &tickAt, &statsDelivered, &current, &desired, &ready,
+			&recommendation, &target, &horizontalApplied, &delta, &verticalCPUTarget, &verticalApplied)
+		if err != nil {
+			panic(fmt.Errorf("could not scan: %s", err.Error()))
+		}
+
+		delivered := stats[decisionId]
+		if delivered == nil {
+			delivered = make([]DeliveredStat, 0)
+		}
+
+		decisions = append(decisions, AutoscalerDecisionLine{
+			TickAt:                   tickAt,
+			StatsDelivered:           statsDelivered,
+			Stats:                    delivered,
+			CurrentReplicas:          int32(current),
+			DesiredReplicas:          int32(desired),
+			ReadyReplicas:            int32(ready),
+			HorizontalRecommendation: int32(recommendation),
+			HorizontalTarget:         int32(target),
+			HorizontalApplied:        horizontalApplied,
+			Delta:                    int32(delta),
+			VerticalCPUTarget:        verticalCPUTarget,
+			VerticalApplied:          verticalApplied,
+		})
+	}
+
+	return decisions
+}
+
 func requestsPerSecond(dbFileName string, scenarioRunId int64) []RPS {
 	rpsConn, err := sqlite3.Open(dbFileName, sqlite3.OPEN_READONLY)
 	if err != nil {
@@ -342,7 +1126,7 @@ func requestsPerSecond(dbFileName string, scenarioRunId int64) []RPS {
 	return requestsPerSecond
 }
 
-func buildClusterConfig(srr *SkenarioRunRequest) model.ClusterConfig {
+func buildClusterConfig(srr *ServiceRequest) model.ClusterConfig {
 	return model.ClusterConfig{
 		LaunchDelay:             srr.LaunchDelay,
 		TerminateDelay:          srr.TerminateDelay,
@@ -351,9 +1135,86 @@ func buildClusterConfig(srr *SkenarioRunRequest) model.ClusterConfig {
 	}
 }
 
-func buildAutoscalerConfig(srr *SkenarioRunRequest) model.AutoscalerConfig {
+func buildMetricsConfig(srr *ServiceRequest, startAt time.Time) model.MetricsConfig {
+	outages := make([]model.MetricsOutage, 0, len(srr.Metrics.Outages))
+	for _, o := range srr.Metrics.Outages {
+		outages = append(outages, model.MetricsOutage{
+			From:  startAt.Add(o.After),
+			Until: startAt.Add(o.After).Add(o.Duration),
+		})
+	}
+
+	return model.MetricsConfig{
+		ScrapeInterval:   srr.Metrics.ScrapeInterval,
+		FirstScrapeAfter: srr.Metrics.FirstScrapeAfter,
+		Lag:              srr.Metrics.Lag,
+		Window:           srr.Metrics.Window,
+		DropProbability:  srr.Metrics.DropProbability,
+		StaleProbability: srr.Metrics.StaleProbability,
+		Outages:          outages,
+	}
+}
+
+func buildRolloutConfigs(srr *ServiceRequest, startAt time.Time) []model.RolloutConfig {
+	rollouts := make([]model.RolloutConfig, 0, len(srr.Rollouts))
+	for _, r := range srr.Rollouts {
+		if r.MaxSurge < 0 || r.MaxUnavailable < 0 {
+			panic(fmt.Errorf("rollout after %s has a negative max surge or max unavailable", r.After))
+		}
+
+		rollouts = append(rollouts, model.RolloutConfig{
+			At:             startAt.Add(r.After).Add(1 * time.Nanosecond),
+			MaxSurge:       r.MaxSurge,
+			MaxUnavailable: r.MaxUnavailable,
+			CPUCostFactor:  r.CPUCostFactor,
+		})
+	}
+	return rollouts
+}
+
+func buildWarmupConfig(srr *ServiceRequest) model.WarmupConfig {
+	switch model.WarmupCurve(srr.Warmup.Curve) {
+	case "", model.WarmupCurveLinear, model.WarmupCurveStep, model.WarmupCurveExponential:
+	default:
+		panic(fmt.Errorf("unknown warm-up curve '%s'", srr.Warmup.Curve))
+	}
+
+	return model.WarmupConfig{
+		Duration:        srr.Warmup.Duration,
+		Curve:           model.WarmupCurve(srr.Warmup.Curve),
+		InitialSlowdown: srr.Warmup.InitialSlowdown,
+		InitialCPUBoost: srr.Warmup.InitialCPUBoost,
+	}
+}
+
+func buildAutoscalerConfig(srr *ServiceRequest) model.AutoscalerConfig {
+	switch model.VPAUpdateMode(srr.VPA.UpdateMode) {
+	case "", model.VPAUpdateModeOff, model.VPAUpdateModeInitial, model.VPAUpdateModeRecreate, model.VPAUpdateModeAuto, model.VPAUpdateModeInPlace:
+	default:
+		panic(fmt.Errorf("unknown VPA update mode '%s'", srr.VPA.UpdateMode))
+	}
+
+	if srr.HPA.MaxReplicas > 0 && srr.HPA.MinReplicas > srr.HPA.MaxReplicas {
+		panic(fmt.Errorf("min replicas %d is more than max replicas %d", srr.HPA.MinReplicas, srr.HPA.MaxReplicas))
+	}
+
 	return model.AutoscalerConfig{
 		TickInterval: srr.TickInterval,
 		Plugins:      srr.Plugins,
+		Horizontal: model.HorizontalConfig{
+			MinReplicas:    srr.HPA.MinReplicas,
+			MaxReplicas:    srr.HPA.MaxReplicas,
+			CPUQuotaMillis: srr.HPA.CPUQuotaMillis,
+			MemoryQuotaMB:  srr.HPA.MemoryQuotaMB,
+			ActuationDelay: srr.HPA.ActuationDelay,
+		},
+		Vertical: model.VerticalConfig{
+			UpdateMode:        model.VPAUpdateMode(srr.VPA.UpdateMode),
+			EvictionRateLimit: srr.VPA.EvictionRateLimit,
+			EvictionRateBurst: srr.VPA.EvictionRateBurst,
+			MinAvailable:      srr.VPA.MinAvailable,
+			EvictionFraction:  srr.VPA.EvictionFraction,
+			ResizeDelay:       srr.VPA.ResizeDelay,
+		},
 	}
 }
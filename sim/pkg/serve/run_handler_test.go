@@ -17,7 +17,9 @@ package serve
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
 	"net/http"
 	"net/http/httptest"
@@ -25,11 +27,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/josephburnett/sk-plugin/pkg/skplug/proto"
 	"github.com/sclevine/spec"
 	"github.com/stretchr/testify/assert"
 
 	"skenario/pkg/model"
 	"skenario/pkg/model/trafficpatterns"
+	"skenario/pkg/slo"
 )
 
 func testRunHandler(t *testing.T, describe spec.G, it spec.S) {
@@ -130,15 +134,43 @@ func testRunHandler(t *testing.T, describe spec.G, it spec.S) {
 	//	})
 	//})
 
+	describe("trafficConfig()", func() {
+		it("prefers traffic_config", func() {
+			srr := &ServiceRequest{
+				TrafficPattern: "step",
+				TrafficConfig:  json.RawMessage(`{"rps":3}`),
+				StepConfig:     trafficpatterns.StepConfig{RPS: 5},
+			}
+			assert.JSONEq(t, `{"rps":3}`, string(srr.trafficConfig()))
+		})
+
+		it("falls back to the pattern's own config", func() {
+			srr := &ServiceRequest{
+				TrafficPattern: "step",
+				StepConfig:     trafficpatterns.StepConfig{RPS: 5},
+			}
+			assert.JSONEq(t, `{"rps":5,"step_after":0}`, string(srr.trafficConfig()))
+		})
+
+		it("only reads newer patterns from traffic_config", func() {
+			srr := &ServiceRequest{TrafficPattern: "poisson"}
+			assert.Nil(t, srr.trafficConfig())
+		})
+
+		it("leaves the pattern to its defaults when neither is set", func() {
+			srr := &ServiceRequest{TrafficPattern: "step"}
+			assert.Nil(t, srr.trafficConfig())
+		})
+	})
+
 	describe("buildClusterConfig()", func() {
-		var srr *SkenarioRunRequest
+		var srr *ServiceRequest
 		var subject model.ClusterConfig
 
 		it.Before(func() {
-			srr = &SkenarioRunRequest{
-				InMemoryDatabase: true,
-				LaunchDelay:      11 * time.Second,
-				TerminateDelay:   22 * time.Second,
+			srr = &ServiceRequest{
+				LaunchDelay:    11 * time.Second,
+				TerminateDelay: 22 * time.Second,
 				UniformConfig: trafficpatterns.UniformConfig{
 					NumberOfRequests: 33,
 				},
@@ -161,14 +193,13 @@ func testRunHandler(t *testing.T, describe spec.G, it spec.S) {
 	})
 
 	describe("buildAutoscalerConfig()", func() {
-		var srr *SkenarioRunRequest
+		var srr *ServiceRequest
 		var subject model.AutoscalerConfig
 
 		it.Before(func() {
-			srr = &SkenarioRunRequest{
-				InMemoryDatabase: true,
-				LaunchDelay:      time.Second,
-				TickInterval:     11 * time.Second,
+			srr = &ServiceRequest{
+				LaunchDelay:  time.Second,
+				TickInterval: 11 * time.Second,
 				UniformConfig: trafficpatterns.UniformConfig{
 					NumberOfRequests: 88,
 				},
@@ -180,6 +211,266 @@ func testRunHandler(t *testing.T, describe spec.G, it spec.S) {
 		it("sets a tick interval", func() {
 			assert.Equal(t, 11*time.Second, subject.TickInterval)
 		})
+
+		it("sets the VPA update mode and eviction limits", func() {
+			srr.VPA = VPARequest{UpdateMode: "Recreate", MinAvailable: 2, EvictionFraction: 0.5}
+			subject = buildAutoscalerConfig(srr)
+
+			assert.Equal(t, model.VPAUpdateModeRecreate, subject.Vertical.UpdateMode)
+			assert.Equal(t, 2, subject.Vertical.MinAvailable)
+			assert.Equal(t, 0.5, subject.Vertical.EvictionFraction)
+		})
+
+		it("sets the in-place resize delay", func() {
+			srr.VPA = VPARequest{UpdateMode: "InPlace", ResizeDelay: 2 * time.Second}
+			subject = buildAutoscalerConfig(srr)
+
+			assert.Equal(t, model.VPAUpdateModeInPlace, subject.Vertical.UpdateMode)
+			assert.Equal(t, 2*time.Second, subject.Vertical.ResizeDelay)
+		})
+
+		it("sets the HPA bounds and actuation delay", func() {
+			srr := &ServiceRequest{HPA: HPARequest{MinReplicas: 1, MaxReplicas: 5, CPUQuotaMillis: 4000, ActuationDelay: time.Second}}
+			assert.Equal(t, model.HorizontalConfig{
+				MinReplicas:    1,
+				MaxReplicas:    5,
+				CPUQuotaMillis: 4000,
+				ActuationDelay: time.Second,
+			}, buildAutoscalerConfig(srr).Horizontal)
+		})
+
+		it("panics when the min replicas are more than the max replicas", func() {
+			srr := &ServiceRequest{HPA: HPARequest{MinReplicas: 6, MaxReplicas: 5}}
+			assert.Panics(t, func() { buildAutoscalerConfig(srr) })
+		})
+
+		it("panics on an unknown VPA update mode", func() {
+			srr.VPA = VPARequest{UpdateMode: "Sometimes"}
+			assert.Panics(t, func() { buildAutoscalerConfig(srr) })
+		})
+	})
+	describe("buildMetricsConfig()", func() {
+		var subject model.MetricsConfig
+
+		it.Before(func() {
+			subject = buildMetricsConfig(&ServiceRequest{Metrics: MetricsRequest{
+				ScrapeInterval:  15 * time.Second,
+				Window:          time.Minute,
+				DropProbability: 0.1,
+				Outages:         []MetricsOutageRequest{{After: 2 * time.Minute, Duration: 30 * time.Second}},
+			}}, defaultStartAt)
+		})
+
+		it("sets the scrape interval and window", func() {
+			assert.Equal(t, 15*time.Second, subject.ScrapeInterval)
+			assert.Equal(t, time.Minute, subject.Window)
+			assert.Equal(t, 0.1, subject.DropProbability)
+		})
+
+		it("places outages relative to the start of the run", func() {
+			assert.Equal(t, defaultStartAt.Add(2*time.Minute), subject.Outages[0].From)
+			assert.Equal(t, defaultStartAt.Add(150*time.Second), subject.Outages[0].Until)
+		})
+	})
+
+	describe("buildRolloutConfigs()", func() {
+		it("schedules each rollout relative to the start of the run", func() {
+			svc := &ServiceRequest{Rollouts: []RolloutRequest{{After: time.Minute, MaxSurge: 2, CPUCostFactor: 1.5}}}
+			assert.Equal(t, []model.RolloutConfig{{
+				At:            defaultStartAt.Add(time.Minute).Add(time.Nanosecond),
+				MaxSurge:      2,
+				CPUCostFactor: 1.5,
+			}}, buildRolloutConfigs(svc, defaultStartAt))
+		})
+
+		it("panics on a negative max surge", func() {
+			svc := &ServiceRequest{Rollouts: []RolloutRequest{{MaxSurge: -1}}}
+			assert.Panics(t, func() { buildRolloutConfigs(svc, defaultStartAt) })
+		})
+	})
+
+	describe("buildWarmupConfig()", func() {
+		it("sets the warm-up curve and factors", func() {
+			svc := &ServiceRequest{Warmup: WarmupRequest{Duration: time.Minute, Curve: "step", InitialSlowdown: 3, InitialCPUBoost: 2}}
+			assert.Equal(t, model.WarmupConfig{
+				Duration:        time.Minute,
+				Curve:           model.WarmupCurveStep,
+				InitialSlowdown: 3,
+				InitialCPUBoost: 2,
+			}, buildWarmupConfig(svc))
+		})
+
+		it("panics on an unknown curve", func() {
+			svc := &ServiceRequest{Warmup: WarmupRequest{Curve: "sigmoid"}}
+			assert.Panics(t, func() { buildWarmupConfig(svc) })
+		})
+	})
+
+	describe("serviceBuildOrder()", func() {
+		it("puts called services before their callers", func() {
+			services := []ServiceRequest{
+				{Name: "frontend", Calls: []ServiceCallRequest{{Service: "backend"}, {Service: "auth"}}},
+				{Name: "backend", Calls: []ServiceCallRequest{{Service: "database"}}},
+				{Name: "database"},
+				{Name: "auth"},
+			}
+			assert.Equal(t, []int{2, 1, 3, 0}, serviceBuildOrder(services))
+		})
+
+		it("panics when services call each other in a cycle", func() {
+			services := []ServiceRequest{
+				{Name: "frontend", Calls: []ServiceCallRequest{{Service: "backend"}}},
+				{Name: "backend", Calls: []ServiceCallRequest{{Service: "frontend"}}},
+			}
+			assert.Panics(t, func() { serviceBuildOrder(services) })
+		})
+
+		it("panics when two services share a name", func() {
+			services := []ServiceRequest{{Name: "frontend"}, {Name: "frontend"}}
+			assert.Panics(t, func() { serviceBuildOrder(services) })
+		})
+
+		it("panics when one of several services has no name", func() {
+			services := []ServiceRequest{{Name: "frontend"}, {}}
+			assert.Panics(t, func() { serviceBuildOrder(services) })
+		})
+
+		it("lets a lone service go unnamed", func() {
+			assert.Equal(t, []int{0}, serviceBuildOrder([]ServiceRequest{{}}))
+		})
+
+		it("panics when a service calls an unknown service", func() {
+			services := []ServiceRequest{
+				{Name: "frontend", Calls: []ServiceCallRequest{{Service: "nowhere"}}},
+			}
+			assert.Panics(t, func() { serviceBuildOrder(services) })
+		})
+	})
+
+	describe("buildServiceCalls()", func() {
+		var subject []model.ServiceCall
+		var backend model.TrafficSource
+
+		it.Before(func() {
+			backend = model.NewTrafficSource(model.NewFakeEnvironment(), nil, model.RequestConfig{})
+			svc := &ServiceRequest{
+				Name: "frontend",
+				Calls: []ServiceCallRequest{
+					{Service: "backend", FanOut: 3, Probability: 0.5},
+					{Service: "backend"},
+				},
+			}
+			subject = buildServiceCalls(svc, map[string]model.TrafficSource{"backend": backend})
+		})
+
+		it("calls the named service's traffic source", func() {
+			assert.Equal(t, backend, subject[0].Service)
+		})
+
+		it("sets the fan out and probability", func() {
+			assert.Equal(t, 3, subject[0].FanOut)
+			assert.Equal(t, 0.5, subject[0].Probability)
+		})
+
+		it("defaults to always calling", func() {
+			assert.Equal(t, 1.0, subject[1].Probability)
+		})
+	})
+
+	describe("buildMetricAliases()", func() {
+		it("looks up the source metric by name", func() {
+			svc := &ServiceRequest{MetricAliases: []MetricAliasRequest{
+				{Name: "rps", Source: "requests_per_second_millis", Scale: 0.001},
+				{Name: "queue", Source: "QUEUE_DEPTH"},
+			}}
+			subject := buildMetricAliases(svc)
+
+			assert.Equal(t, model.MetricAliasConfig{Name: "rps", Source: proto.MetricType_REQUESTS_PER_SECOND_MILLIS, Scale: 0.001}, subject[0])
+			assert.Equal(t, proto.MetricType_QUEUE_DEPTH, subject[1].Source)
+		})
+
+		it("defaults the scale to 1", func() {
+			svc := &ServiceRequest{MetricAliases: []MetricAliasRequest{{Name: "queue", Source: "queue_depth"}}}
+			assert.Equal(t, 1.0, buildMetricAliases(svc)[0].Scale)
+		})
+
+		it("panics on an unknown source metric", func() {
+			svc := &ServiceRequest{MetricAliases: []MetricAliasRequest{{Name: "bogus", Source: "bogosity"}}}
+			assert.Panics(t, func() { buildMetricAliases(svc) })
+		})
+	})
+
+	describe("buildObjectives()", func() {
+		it("converts SLO requests to objectives", func() {
+			svc := &ServiceRequest{SLOs: []SLORequest{
+				{Name: "p99", Kind: "latency", Percentile: 99, Threshold: time.Second, Window: time.Minute},
+			}}
+			assert.Equal(t, []slo.Objective{
+				{Name: "p99", Kind: slo.KindLatency, Percentile: 99, Threshold: time.Second, Window: time.Minute},
+			}, buildObjectives(svc))
+		})
+
+		it("panics on an invalid SLO", func() {
+			svc := &ServiceRequest{SLOs: []SLORequest{{Name: "errors", Kind: "error_rate"}}}
+			assert.Panics(t, func() { buildObjectives(svc) })
+		})
+	})
+
+	describe("sloResults()", func() {
+		it("reports violations in nanoseconds", func() {
+			objectives := []slo.Objective{{Name: "errors", Kind: slo.KindErrorRate, MaxErrorRate: 0.1}}
+			requests := func(observe func(slo.Request)) {
+				observe(slo.Request{ArrivedAt: defaultStartAt, CompletedAt: defaultStartAt.Add(time.Second), Failed: true})
+			}
+
+			results := sloResults(objectives, requests, defaultStartAt, defaultStartAt.Add(time.Minute))
+			assert.Equal(t, []SLOResult{{
+				Name:              "errors",
+				Passed:            false,
+				ErrorBudgetBurned: 10,
+				Violations:        []SLOViolation{{From: defaultStartAt.UnixNano(), Until: defaultStartAt.Add(time.Minute).UnixNano()}},
+			}}, results)
+		})
+	})
+
+	describe("partitionMovements()", func() {
+		var env simulator.Environment
+		var first, second simulator.PartitionEnvironment
+		var firstCompleted []simulator.CompletedMovement
+		var firstIgnored []simulator.IgnoredMovement
+
+		it.Before(func() {
+			var d dispatcher.Dispatcher = simulator.NewFakeDispatcher()
+			env = simulator.NewEnvironment(context.Background(), defaultStartAt, 10*time.Second, &d)
+			first = simulator.NewPartitionEnvironment(env, &d)
+			second = simulator.NewPartitionEnvironment(env, &d)
+
+			from := simulator.NewArrayThroughStock("from", "Entity")
+			to := simulator.NewArrayThroughStock("to", "Entity")
+			for i := 0; i < 3; i++ {
+				from.Add(simulator.NewEntity(simulator.EntityName(fmt.Sprintf("entity-%d", i)), "Entity"))
+			}
+			first.AddToSchedule(simulator.NewMovement("first", defaultStartAt.Add(1*time.Second), from, to, nil))
+			second.AddToSchedule(simulator.NewMovement("second", defaultStartAt.Add(1*time.Second), from, to, nil))
+			first.AddToSchedule(simulator.NewMovement("first", defaultStartAt.Add(2*time.Second), from, to, nil))
+			first.AddToSchedule(simulator.NewMovement("first", defaultStartAt.Add(20*time.Second), from, to, nil))
+
+			completed, ignored, err := env.Run()
+			assert.NoError(t, err)
+
+			firstCompleted, firstIgnored = partitionMovements(first, completed, ignored)
+		})
+
+		it("keeps the completed movements scheduled by the partition", func() {
+			assert.Len(t, firstCompleted, 2)
+			for _, c := range firstCompleted {
+				assert.Equal(t, simulator.MovementKind("first"), c.Movement.Kind())
+			}
+		})
+
+		it("keeps the ignored movements scheduled by the partition", func() {
+			assert.Len(t, firstIgnored, 1)
+		})
 	})
 }
 
@@ -189,9 +480,11 @@ func trafficPatternBefore(t *testing.T, pattern string) *SkenarioRunResponse {
 	skenarioRunRequest := &SkenarioRunRequest{
 		InMemoryDatabase: true,
 		RunFor:           20 * time.Second,
-		TrafficPattern:   pattern,
-		TickInterval:     2 * time.Second,
-		LaunchDelay:      2 * time.Second,
+		ServiceRequest: ServiceRequest{
+			TrafficPattern: pattern,
+			TickInterval:   2 * time.Second,
+			LaunchDelay:    2 * time.Second,
+		},
 	}
 	var reqBody = new(bytes.Buffer)
 	err := json.NewEncoder(reqBody).Encode(skenarioRunRequest)
@@ -201,7 +494,7 @@ func trafficPatternBefore(t *testing.T, pattern string) *SkenarioRunResponse {
 	assert.NoError(t, err)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/run", RunHandler(&dispatcher))
+	mux.HandleFunc("/run", RunHandler(&dispatcher, nil))
 
 	recorder := httptest.NewRecorder()
 	mux.ServeHTTP(recorder, req)
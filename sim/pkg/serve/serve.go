@@ -17,24 +17,49 @@ package serve
 
 import (
 	"context"
+	"fmt"
 	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+
+	"skenario/pkg/model/trafficpatterns"
 )
 
 type SkenarioServer struct {
-	IndexRoot  string
-	Dispatcher dispatcher.Dispatcher
-	srv        *http.Server
+	IndexRoot         string
+	Dispatcher        dispatcher.Dispatcher
+	TrafficDispatcher dispatcher.TrafficDispatcher
+	srv               *http.Server
 }
 
-func (ss *SkenarioServer) Serve() {
-	ss.Dispatcher.Init(os.Args[1:])
+// trafficGeneratorArg marks a plugin argument as a traffic generator rather than an autoscaler.
+const trafficGeneratorArg = "--traffic-generator="
+
+// traceDirArg gives the directory that replayed traces may be read from by path.
+const traceDirArg = "--trace-dir="
+
+// Serve launches the plugins and starts listening. It returns an error, having shut down any
+// plugins already launched, if a plugin could not be launched.
+func (ss *SkenarioServer) Serve() error {
+	autoscalerPaths, trafficGeneratorPaths, traceDir := splitArgs(os.Args[1:])
+	trafficpatterns.TraceDir = traceDir
+	err := ss.Dispatcher.Init(autoscalerPaths)
+	if err != nil {
+		ss.Dispatcher.Shutdown()
+		return fmt.Errorf("could not launch autoscaler plugins: %s", err.Error())
+	}
+	err = ss.TrafficDispatcher.Init(trafficGeneratorPaths)
+	if err != nil {
+		ss.Dispatcher.Shutdown()
+		ss.TrafficDispatcher.Shutdown()
+		return fmt.Errorf("could not launch traffic generator plugins: %s", err.Error())
+	}
 	router := chi.NewRouter()
 	router.Use(middleware.NoCache)
 	router.Use(middleware.DefaultCompress)
@@ -42,7 +67,9 @@ func (ss *SkenarioServer) Serve() {
 
 	router.Mount("/debug", middleware.Profiler())
 	router.Mount("/", http.FileServer(http.Dir(ss.IndexRoot)))
-	router.HandleFunc("/run", RunHandler(&ss.Dispatcher))
+	router.HandleFunc("/run", RunHandler(&ss.Dispatcher, ss.TrafficDispatcher))
+	router.Get("/traffic-patterns", TrafficPatternsHandler)
+	router.Post("/export", ExportHandler(ss.TrafficDispatcher))
 
 	ss.srv = &http.Server{
 		Addr:    "0.0.0.0:3000",
@@ -53,6 +80,7 @@ func (ss *SkenarioServer) Serve() {
 		log.Println("Listening ...")
 		log.Fatal(ss.srv.ListenAndServe())
 	}()
+	return nil
 }
 
 func (ss *SkenarioServer) Shutdown() {
@@ -67,5 +95,22 @@ func (ss *SkenarioServer) Shutdown() {
 	log.Println("Shutting down autoscaler plugins")
 	ss.Dispatcher.Shutdown()
 
+	log.Println("Shutting down traffic generator plugins")
+	ss.TrafficDispatcher.Shutdown()
+
 	log.Println("Done.")
 }
+
+func splitArgs(args []string) (autoscalerPaths []string, trafficGeneratorPaths []string, traceDir string) {
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, trafficGeneratorArg):
+			trafficGeneratorPaths = append(trafficGeneratorPaths, strings.TrimPrefix(arg, trafficGeneratorArg))
+		case strings.HasPrefix(arg, traceDirArg):
+			traceDir = strings.TrimPrefix(arg, traceDirArg)
+		default:
+			autoscalerPaths = append(autoscalerPaths, arg)
+		}
+	}
+	return autoscalerPaths, trafficGeneratorPaths, traceDir
+}
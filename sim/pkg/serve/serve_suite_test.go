@@ -24,6 +24,8 @@ import (
 
 func TestServePkg(t *testing.T) {
 	spec.Run(t, "RunHandler", testRunHandler, spec.Report(report.Terminal{}), spec.Sequential())
+	spec.Run(t, "TrafficPatternsHandler", testTrafficPatternsHandler, spec.Report(report.Terminal{}))
+	spec.Run(t, "ExportHandler", testExportHandler, spec.Report(report.Terminal{}))
 
 	//TODO https://github.com/pivotal/skenario/issues/83
 	//var server *SkenarioServer
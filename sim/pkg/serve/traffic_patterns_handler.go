@@ -0,0 +1,33 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"skenario/pkg/model/trafficpatterns"
+)
+
+// TrafficPatternsHandler lists the traffic patterns a run can use, with their configs' schemas and defaults.
+func TrafficPatternsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(trafficpatterns.Describe())
+	if err != nil {
+		panic(err.Error())
+	}
+}
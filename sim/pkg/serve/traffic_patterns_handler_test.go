@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/assert"
+
+	"skenario/pkg/model/trafficpatterns"
+)
+
+func testTrafficPatternsHandler(t *testing.T, describe spec.G, it spec.S) {
+	var descriptions []trafficpatterns.PatternDescription
+	var recorder *httptest.ResponseRecorder
+
+	it.Before(func() {
+		req, err := http.NewRequest("GET", "/traffic-patterns", nil)
+		assert.NoError(t, err)
+
+		recorder = httptest.NewRecorder()
+		TrafficPatternsHandler(recorder, req)
+
+		descriptions = nil
+		err = json.NewDecoder(recorder.Result().Body).Decode(&descriptions)
+		assert.NoError(t, err)
+	})
+
+	it("responds with JSON", func() {
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	})
+
+	it("lists every registered pattern", func() {
+		assert.Len(t, descriptions, len(trafficpatterns.Registrations()))
+
+		names := make([]string, 0, len(descriptions))
+		for _, d := range descriptions {
+			names = append(names, d.Name)
+		}
+		assert.Contains(t, names, "step")
+		assert.Contains(t, names, "composite")
+	})
+
+	it("describes each config's fields and defaults", func() {
+		for _, d := range descriptions {
+			if d.Name != "step" {
+				continue
+			}
+			assert.Equal(t, "object", d.Config.Type)
+			assert.Len(t, d.Config.Fields, 2)
+			assert.Equal(t, "rps", d.Config.Fields[0].Name)
+			assert.Equal(t, "integer", d.Config.Fields[0].Type)
+			assert.Equal(t, float64(10), d.Config.Fields[0].Default)
+			assert.Equal(t, "duration", d.Config.Fields[1].Type)
+		}
+	})
+}
@@ -32,6 +32,7 @@ const (
 type Environment interface {
 	Plugin() plugin.PluginPartition
 	AddToSchedule(movement Movement) (added bool)
+	RemoveFromSchedule(movement Movement) (removed bool)
 	Run() (completed []CompletedMovement, ignored []IgnoredMovement, err error)
 	CurrentMovementTime() time.Time
 	HaltTime() time.Time
@@ -104,6 +105,15 @@ func (env *environment) AddToSchedule(movement Movement) (added bool) {
 	return schedulable
 }
 
+func (env *environment) RemoveFromSchedule(movement Movement) (removed bool) {
+	removed, err := env.futureMovements.RemoveMovement(movement)
+	if err != nil {
+		panic(fmt.Errorf("unknown error meant '%#v' was not removed from future movements: %s", movement, err.Error()))
+	}
+
+	return removed
+}
+
 func (env *environment) Run() ([]CompletedMovement, []IgnoredMovement, error) {
 	for {
 		var err error
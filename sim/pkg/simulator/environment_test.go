@@ -139,6 +139,40 @@ func testEnvironment(t *testing.T, describe spec.G, it spec.S) {
 		})
 	}, spec.Nested())
 
+	describe("RemoveFromSchedule()", func() {
+		it.Before(func() {
+			subject = NewEnvironment(ctx, startTime, runFor, &dispatcher)
+			assert.NotNil(t, subject)
+			movement = NewMovement("test movement kind", time.Unix(333333, 0), fromStock, toStock, nil)
+		})
+
+		describe("the movement was scheduled", func() {
+			it.Before(func() {
+				subject.AddToSchedule(movement)
+			})
+
+			it("returns true", func() {
+				assert.True(t, subject.RemoveFromSchedule(movement))
+			})
+
+			it("does not run the movement", func() {
+				subject.RemoveFromSchedule(movement)
+				completed, _, err := subject.Run()
+				assert.NoError(t, err)
+
+				for _, c := range completed {
+					assert.NotEqual(t, MovementKind("test movement kind"), c.Movement.Kind())
+				}
+			})
+		})
+
+		describe("the movement was not scheduled", func() {
+			it("returns false", func() {
+				assert.False(t, subject.RemoveFromSchedule(movement))
+			})
+		})
+	}, spec.Nested())
+
 	describe("Run()", func() {
 		describe("taking the next movement from the schedule", func() {
 			var fromMock, toMock *MockStockType
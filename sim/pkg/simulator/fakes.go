@@ -90,7 +90,8 @@ func (es *EchoSourceStockType) Remove(entity *Entity) Entity {
 type fakeDispatcher struct {
 }
 
-func (fd *fakeDispatcher) Init(pluginsPaths []string) {
+func (fd *fakeDispatcher) Init(pluginsPaths []string) error {
+	return nil
 }
 func (fd *fakeDispatcher) Shutdown() {
 }
@@ -44,6 +44,7 @@ type move struct {
 	occursAt time.Time
 	notes    []string
 	entity   *Entity
+	owner    Environment
 }
 
 func (mv *move) Kind() MovementKind {
@@ -74,6 +75,14 @@ func (mv *move) WhatToMove() *Entity {
 	return mv.entity
 }
 
+func (mv *move) scheduledBy() Environment {
+	return mv.owner
+}
+
+func (mv *move) setScheduledBy(env Environment) {
+	mv.owner = env
+}
+
 func NewMovement(kind MovementKind, occursAt time.Time, from SourceStock, to SinkStock, entity *Entity) Movement {
 	return &move{
 		kind:     kind,
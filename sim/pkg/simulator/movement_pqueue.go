@@ -26,12 +26,35 @@ import (
 type MovementPriorityQueue interface {
 	EnqueueMovement(movement Movement) (wasShifted bool, scheduledAt time.Time, err error)
 	DequeueMovement() (movement Movement, err error, closed bool)
+	RemoveMovement(movement Movement) (removed bool, err error)
 	Close()
 	IsClosed() bool
 }
 
+// shiftedMovement keeps the identity and notes of a Movement that was moved to a free time.
+type shiftedMovement struct {
+	Movement
+	occursAt time.Time
+}
+
+func (sm *shiftedMovement) OccursAt() time.Time {
+	return sm.occursAt
+}
+
+// unshifted returns the Movement as it was originally scheduled.
+func unshifted(movement Movement) Movement {
+	if sm, ok := movement.(*shiftedMovement); ok {
+		return sm.Movement
+	}
+	return movement
+}
+
 type movementPQ struct {
 	heap *cache.Heap
+	// scheduled tracks the key each enqueued movement was stored under, which differs
+	// from its OccursAt() when it was time-shifted.
+	scheduled map[Movement]string
+	originals map[string]Movement
 }
 
 func (mpq *movementPQ) EnqueueMovement(movement Movement) (wasShifted bool, scheduledAt time.Time, err error) {
@@ -53,9 +76,13 @@ func (mpq *movementPQ) EnqueueMovement(movement Movement) (wasShifted bool, sche
 		}
 	}
 
+	key := occursAtToStr(movement.OccursAt().Add(i))
+	mpq.scheduled[movement] = key
+	mpq.originals[key] = movement
+
 	if wasShifted {
-		shiftedMovement := NewMovement(movement.Kind(), movement.OccursAt().Add(i), movement.From(), movement.To(), movement.WhatToMove())
-		return true, shiftedMovement.OccursAt(), mpq.heap.Add(shiftedMovement)
+		shifted := &shiftedMovement{Movement: movement, occursAt: movement.OccursAt().Add(i)}
+		return true, shifted.OccursAt(), mpq.heap.Add(shifted)
 	}
 
 	return false, movement.OccursAt(), mpq.heap.Add(movement)
@@ -78,9 +105,39 @@ func (mpq *movementPQ) DequeueMovement() (movement Movement, err error, closed b
 	}
 
 	next := n.(Movement)
+	mpq.forget(occursAtToStr(next.OccursAt()))
 	return next, nil, false
 }
 
+// RemoveMovement takes a previously enqueued Movement out of the queue, so that it never occurs.
+// Returns false if the Movement is not in the queue, eg. because it was already dequeued.
+func (mpq *movementPQ) RemoveMovement(movement Movement) (removed bool, err error) {
+	key, ok := mpq.scheduled[movement]
+	if !ok {
+		return false, nil
+	}
+
+	stored, exists, err := mpq.heap.GetByKey(key)
+	if err != nil {
+		return false, err
+	}
+	mpq.forget(key)
+	if !exists {
+		return false, nil
+	}
+
+	return true, mpq.heap.Delete(stored)
+}
+
+func (mpq *movementPQ) forget(key string) {
+	if original, ok := mpq.originals[key]; ok {
+		if mpq.scheduled[original] == key {
+			delete(mpq.scheduled, original)
+		}
+		delete(mpq.originals, key)
+	}
+}
+
 func (mpq *movementPQ) Close() {
 	mpq.heap.Close()
 }
@@ -93,7 +150,9 @@ func NewMovementPriorityQueue() MovementPriorityQueue {
 	heap := cache.NewHeap(movementToKey, leftMovementIsEarlier)
 
 	return &movementPQ{
-		heap: heap,
+		heap:      heap,
+		scheduled: make(map[Movement]string),
+		originals: make(map[string]Movement),
 	}
 }
 
@@ -59,6 +59,17 @@ func testMovementPQ(t *testing.T, describe spec.G, it spec.S) {
 			it("indicates that time-shifting occurred", func() {
 				assert.True(t, shifted)
 			})
+
+			it("keeps the notes of the time-shifted Movement", func() {
+				movement.AddNote("a note")
+				_, err, _ = subject.DequeueMovement()
+				assert.NoError(t, err)
+
+				dqmv, err, _ := subject.DequeueMovement()
+				assert.NoError(t, err)
+				assert.Equal(t, theTime.Add(1*time.Nanosecond), dqmv.OccursAt())
+				assert.Equal(t, []string{"a note"}, dqmv.Notes())
+			})
 		})
 
 		describe("when no other Movement has been scheduled at the same time", func() {
@@ -113,6 +124,94 @@ func testMovementPQ(t *testing.T, describe spec.G, it spec.S) {
 		})
 	})
 
+	describe("RemoveMovement()", func() {
+		var removed bool
+
+		it.Before(func() {
+			subject = NewMovementPriorityQueue()
+			theTime = time.Now()
+			movement = NewMovement("test movement kind", theTime, nil, nil, nil)
+		})
+
+		describe("when the Movement is in the queue", func() {
+			var other Movement
+
+			it.Before(func() {
+				other = NewMovement("other movement kind", theTime.Add(1*time.Second), nil, nil, nil)
+				_, _, err = subject.EnqueueMovement(movement)
+				assert.NoError(t, err)
+				_, _, err = subject.EnqueueMovement(other)
+				assert.NoError(t, err)
+
+				removed, err = subject.RemoveMovement(movement)
+				assert.NoError(t, err)
+			})
+
+			it("returns true", func() {
+				assert.True(t, removed)
+			})
+
+			it("no longer dequeues the Movement", func() {
+				dqmv, err, _ := subject.DequeueMovement()
+				assert.NoError(t, err)
+				assert.Equal(t, other, dqmv)
+			})
+		})
+
+		describe("when the Movement was time-shifted", func() {
+			var shiftedMovement Movement
+
+			it.Before(func() {
+				shiftedMovement = NewMovement("shifted movement kind", theTime, nil, nil, nil)
+				_, _, err = subject.EnqueueMovement(movement)
+				assert.NoError(t, err)
+				_, _, err = subject.EnqueueMovement(shiftedMovement)
+				assert.NoError(t, err)
+
+				removed, err = subject.RemoveMovement(shiftedMovement)
+				assert.NoError(t, err)
+			})
+
+			it("returns true", func() {
+				assert.True(t, removed)
+			})
+
+			it("removes the shifted Movement, not the Movement it collided with", func() {
+				dqmv, err, _ := subject.DequeueMovement()
+				assert.NoError(t, err)
+				assert.Equal(t, movement, dqmv)
+
+				subject.Close()
+				_, _, closed := subject.DequeueMovement()
+				assert.True(t, closed)
+			})
+		})
+
+		describe("when the Movement was already dequeued", func() {
+			it.Before(func() {
+				_, _, err = subject.EnqueueMovement(movement)
+				assert.NoError(t, err)
+				_, err, _ = subject.DequeueMovement()
+				assert.NoError(t, err)
+
+				removed, err = subject.RemoveMovement(movement)
+				assert.NoError(t, err)
+			})
+
+			it("returns false", func() {
+				assert.False(t, removed)
+			})
+		})
+
+		describe("when the Movement was never enqueued", func() {
+			it("returns false", func() {
+				removed, err = subject.RemoveMovement(movement)
+				assert.NoError(t, err)
+				assert.False(t, removed)
+			})
+		})
+	})
+
 	describe("Close()", func() {
 		it.Before(func() {
 			subject = NewMovementPriorityQueue()
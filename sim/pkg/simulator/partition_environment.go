@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package simulator
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
+
+	"skenario/pkg/plugin"
+)
+
+// PartitionEnvironment shares the clock and schedule of a parent Environment, but has its own
+// plugin partition and CPU utilizations. This allows several independent models, each with their
+// own autoscaler, to run in one simulation.
+type PartitionEnvironment interface {
+	Environment
+	Owns(movement Movement) bool
+}
+
+// ownedMovement remembers the partition it was scheduled through, so that the partition need not
+// keep every movement it has ever scheduled.
+type ownedMovement interface {
+	scheduledBy() Environment
+	setScheduledBy(env Environment)
+}
+
+type partitionEnvironment struct {
+	parent          Environment
+	pluginPartition plugin.PluginPartition
+	cpuUtilizations []*CPUUtilization
+}
+
+func (pe *partitionEnvironment) Plugin() plugin.PluginPartition {
+	return pe.pluginPartition
+}
+
+func (pe *partitionEnvironment) AddToSchedule(movement Movement) (added bool) {
+	if om, ok := movement.(ownedMovement); ok {
+		om.setScheduledBy(pe)
+	}
+	return pe.parent.AddToSchedule(movement)
+}
+
+func (pe *partitionEnvironment) RemoveFromSchedule(movement Movement) (removed bool) {
+	return pe.parent.RemoveFromSchedule(movement)
+}
+
+// Run runs the parent Environment, including the movements of every other partition.
+func (pe *partitionEnvironment) Run() ([]CompletedMovement, []IgnoredMovement, error) {
+	return pe.parent.Run()
+}
+
+func (pe *partitionEnvironment) CurrentMovementTime() time.Time {
+	return pe.parent.CurrentMovementTime()
+}
+
+func (pe *partitionEnvironment) HaltTime() time.Time {
+	return pe.parent.HaltTime()
+}
+
+func (pe *partitionEnvironment) Context() context.Context {
+	return pe.parent.Context()
+}
+
+func (pe *partitionEnvironment) CPUUtilizations() []*CPUUtilization {
+	return pe.cpuUtilizations
+}
+
+func (pe *partitionEnvironment) AppendCPUUtilization(cpuUtilization *CPUUtilization) {
+	pe.cpuUtilizations = append(pe.cpuUtilizations, cpuUtilization)
+}
+
+// Owns is true for movements that were scheduled through this partition, even if they were time-shifted.
+func (pe *partitionEnvironment) Owns(movement Movement) bool {
+	om, ok := unshifted(movement).(ownedMovement)
+	return ok && om.scheduledBy() == pe
+}
+
+func NewPartitionEnvironment(parent Environment, dispatcher *dispatcher.Dispatcher) PartitionEnvironment {
+	return &partitionEnvironment{
+		parent:          parent,
+		pluginPartition: plugin.NewPluginPartition(dispatcher),
+		cpuUtilizations: make([]*CPUUtilization, 0),
+	}
+}
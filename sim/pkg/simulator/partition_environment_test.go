@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/josephburnett/sk-plugin/pkg/skplug/dispatcher"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionEnvironment(t *testing.T) {
+	spec.Run(t, "PartitionEnvironment spec", testPartitionEnvironment, spec.Report(report.Terminal{}))
+}
+
+func testPartitionEnvironment(t *testing.T, describe spec.G, it spec.S) {
+	var (
+		subject    PartitionEnvironment
+		other      PartitionEnvironment
+		parent     Environment
+		dispatcher dispatcher.Dispatcher
+		startTime  time.Time
+		fromStock  SourceStock
+		toStock    SinkStock
+	)
+
+	it.Before(func() {
+		dispatcher = NewFakeDispatcher()
+		startTime = time.Unix(222222, 0)
+		parent = NewEnvironment(context.Background(), startTime, 100*time.Second, &dispatcher)
+		subject = NewPartitionEnvironment(parent, &dispatcher)
+		other = NewPartitionEnvironment(parent, &dispatcher)
+		fromStock = &EchoSourceStockType{
+			name: "from stock",
+			kind: "test entity kind",
+		}
+		toStock = NewSinkStock("to stock", "test entity kind")
+	})
+
+	describe("NewPartitionEnvironment()", func() {
+		it("has its own plugin partition", func() {
+			assert.NotNil(t, subject.Plugin())
+			assert.NotEqual(t, parent.Plugin(), subject.Plugin())
+			assert.NotEqual(t, other.Plugin(), subject.Plugin())
+		})
+
+		it("shares the parent's clock", func() {
+			assert.Equal(t, parent.CurrentMovementTime(), subject.CurrentMovementTime())
+			assert.Equal(t, parent.HaltTime(), subject.HaltTime())
+		})
+	})
+
+	describe("CPU utilizations", func() {
+		it.Before(func() {
+			subject.AppendCPUUtilization(&CPUUtilization{CPUUtilization: 50, CalculatedAt: startTime})
+		})
+
+		it("keeps them separate from other partitions", func() {
+			assert.Len(t, subject.CPUUtilizations(), 1)
+			assert.Len(t, other.CPUUtilizations(), 0)
+			assert.Len(t, parent.CPUUtilizations(), 0)
+		})
+	})
+
+	describe("Owns()", func() {
+		var owned, collided, notOwned Movement
+		var completed []CompletedMovement
+
+		it.Before(func() {
+			owned = NewMovement("owned", startTime.Add(10*time.Second), fromStock, toStock, nil)
+			notOwned = NewMovement("not owned", startTime.Add(20*time.Second), fromStock, toStock, nil)
+			collided = NewMovement("collided", startTime.Add(20*time.Second), fromStock, toStock, nil)
+
+			assert.True(t, subject.AddToSchedule(owned))
+			assert.True(t, other.AddToSchedule(notOwned))
+			assert.True(t, subject.AddToSchedule(collided))
+
+			var err error
+			completed, _, err = subject.Run()
+			assert.NoError(t, err)
+		})
+
+		it("is true for movements scheduled through the partition", func() {
+			assert.True(t, subject.Owns(owned))
+		})
+
+		it("is false for movements scheduled through other partitions", func() {
+			assert.False(t, subject.Owns(notOwned))
+			assert.True(t, other.Owns(notOwned))
+		})
+
+		it("recognises movements that were time-shifted", func() {
+			ownedCount := 0
+			for _, c := range completed {
+				if subject.Owns(c.Movement) {
+					ownedCount++
+					assert.NotEqual(t, MovementKind("not owned"), c.Movement.Kind())
+				}
+			}
+			assert.Equal(t, 2, ownedCount)
+		})
+	})
+}
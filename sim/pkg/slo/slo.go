@@ -0,0 +1,275 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package slo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindLatency   Kind = "latency"
+	KindErrorRate Kind = "error_rate"
+)
+
+// evaluationStep is how often a rolling window is evaluated.
+const evaluationStep = time.Second
+
+// Objective is either a latency objective, that the Percentile latency of successful requests is
+// at most Threshold, or an error rate objective, that at most MaxErrorRate of requests fail.
+// With a Window it must hold over every rolling window, otherwise over the whole run.
+type Objective struct {
+	Name         string
+	Kind         Kind
+	Percentile   float64 // e.g. 99
+	Threshold    time.Duration
+	MaxErrorRate float64 // e.g. 0.01
+	Window       time.Duration
+}
+
+// Request is the outcome of a request, as recorded in a run's movements.
+type Request struct {
+	ArrivedAt   time.Time
+	CompletedAt time.Time
+	Failed      bool
+}
+
+type Violation struct {
+	From  time.Time
+	Until time.Time
+}
+
+type Result struct {
+	Objective Objective
+	Passed    bool
+	// ErrorBudgetBurned is the fraction of the run's error budget used up; above 1 it was overspent.
+	ErrorBudgetBurned float64
+	Violations        []Violation
+}
+
+func (o Objective) Validate() error {
+	switch o.Kind {
+	case KindLatency:
+		if o.Percentile <= 0 || o.Percentile >= 100 {
+			return fmt.Errorf("SLO '%s' has percentile %v, which is not between 0 and 100", o.Name, o.Percentile)
+		}
+	case KindErrorRate:
+		if o.MaxErrorRate <= 0 || o.MaxErrorRate >= 1 {
+			return fmt.Errorf("SLO '%s' has max error rate %v, which is not between 0 and 1", o.Name, o.MaxErrorRate)
+		}
+	default:
+		return fmt.Errorf("SLO '%s' has unknown kind '%s'", o.Name, o.Kind)
+	}
+	if o.Window < 0 {
+		return fmt.Errorf("SLO '%s' has a negative window", o.Name)
+	}
+	return nil
+}
+
+// Evaluate checks an objective against the requests which completed between from and until.
+// Panics if the objective is not valid.
+func Evaluate(objective Objective, requests []Request, from, until time.Time) Result {
+	evaluator := NewEvaluator(objective, from, until)
+
+	sorted := make([]Request, len(requests))
+	copy(sorted, requests)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CompletedAt.Before(sorted[j].CompletedAt)
+	})
+	for _, r := range sorted {
+		evaluator.Observe(r)
+	}
+
+	return evaluator.Result()
+}
+
+// Evaluator checks an objective against requests observed in the order they completed. It keeps
+// tallies rather than the requests themselves, other than those still inside a rolling window.
+type Evaluator interface {
+	// Observe panics if the request completed before one already observed.
+	Observe(request Request)
+	Result() Result
+}
+
+type evaluator struct {
+	objective       Objective
+	from            time.Time
+	until           time.Time
+	lastCompletedAt time.Time
+	run             tally
+	window          tally
+	inWindow        []outcome
+	nextStep        time.Time
+	violating       *Violation
+	violations      []Violation
+}
+
+// outcome is what an objective needs to know about a request.
+type outcome struct {
+	completedAt time.Time
+	failed      bool
+	slow        bool
+}
+
+type tally struct {
+	requests int
+	failed   int
+	slow     int
+}
+
+func (t *tally) add(o outcome) {
+	t.requests++
+	if o.failed {
+		t.failed++
+	} else if o.slow {
+		t.slow++
+	}
+}
+
+func (t *tally) remove(o outcome) {
+	t.requests--
+	if o.failed {
+		t.failed--
+	} else if o.slow {
+		t.slow--
+	}
+}
+
+func (e *evaluator) Observe(request Request) {
+	if request.CompletedAt.Before(e.from) || request.CompletedAt.After(e.until) {
+		return
+	}
+	if request.CompletedAt.Before(e.lastCompletedAt) {
+		panic(fmt.Errorf("request completed at %v was observed after one which completed at %v", request.CompletedAt, e.lastCompletedAt))
+	}
+	e.lastCompletedAt = request.CompletedAt
+
+	o := outcome{
+		completedAt: request.CompletedAt,
+		failed:      request.Failed,
+		slow:        e.objective.Kind == KindLatency && request.CompletedAt.Sub(request.ArrivedAt) > e.objective.Threshold,
+	}
+	e.run.add(o)
+
+	if e.objective.Window > 0 {
+		// Windows ending before this request completed have seen every request they will get.
+		for e.nextStep.Before(o.completedAt) {
+			e.step()
+		}
+		e.inWindow = append(e.inWindow, o)
+		e.window.add(o)
+	}
+}
+
+// step evaluates the window which ends at nextStep, after dropping the requests which left it.
+func (e *evaluator) step() {
+	windowFrom := e.nextStep.Add(-e.objective.Window)
+	for len(e.inWindow) > 0 && !e.inWindow[0].completedAt.After(windowFrom) {
+		e.window.remove(e.inWindow[0])
+		e.inWindow = e.inWindow[1:]
+	}
+
+	if met(e.objective, e.window) {
+		if e.violating != nil {
+			e.violating.Until = e.nextStep
+			e.violations = append(e.violations, *e.violating)
+			e.violating = nil
+		}
+	} else if e.violating == nil {
+		e.violating = &Violation{From: e.nextStep}
+	}
+	e.nextStep = e.nextStep.Add(evaluationStep)
+}
+
+func (e *evaluator) Result() Result {
+	result := Result{
+		Objective:         e.objective,
+		ErrorBudgetBurned: budgetBurned(e.objective, e.run),
+		Violations:        make([]Violation, 0),
+	}
+
+	if e.objective.Window == 0 {
+		if !met(e.objective, e.run) {
+			result.Violations = append(result.Violations, Violation{From: e.from, Until: e.until})
+		}
+	} else {
+		for !e.nextStep.After(e.until) {
+			e.step()
+		}
+		result.Violations = append(result.Violations, e.violations...)
+		if e.violating != nil {
+			result.Violations = append(result.Violations, Violation{From: e.violating.From, Until: e.until})
+		}
+	}
+
+	result.Passed = len(result.Violations) == 0
+	return result
+}
+
+// met is true when the requests meet the objective. No requests at all do not violate it.
+func met(objective Objective, requests tally) bool {
+	switch objective.Kind {
+	case KindLatency:
+		successful := requests.requests - requests.failed
+		if successful == 0 {
+			return true
+		}
+		// The percentile latency is within the threshold when at least that many requests were.
+		rank := int(math.Ceil(objective.Percentile / 100 * float64(successful)))
+		return successful-requests.slow >= rank
+	default:
+		if requests.requests == 0 {
+			return true
+		}
+		return float64(requests.failed)/float64(requests.requests) <= objective.MaxErrorRate
+	}
+}
+
+// budgetBurned compares the bad requests to the number the objective allows for.
+func budgetBurned(objective Objective, requests tally) float64 {
+	var bad, allowed float64
+	switch objective.Kind {
+	case KindLatency:
+		bad = float64(requests.slow)
+		allowed = (1 - objective.Percentile/100) * float64(requests.requests-requests.failed)
+	default:
+		bad = float64(requests.failed)
+		allowed = objective.MaxErrorRate * float64(requests.requests)
+	}
+
+	if allowed == 0 {
+		return 0
+	}
+	return bad / allowed
+}
+
+// NewEvaluator panics if the objective is not valid.
+func NewEvaluator(objective Objective, from, until time.Time) Evaluator {
+	if err := objective.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &evaluator{
+		objective: objective,
+		from:      from,
+		until:     until,
+		nextStep:  from.Add(evaluationStep),
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+ *
+ * This program and the accompanying materials are made available under the terms
+ * of the Apache License, Version 2.0 (the "License”); you may not use this file
+ * except in compliance with the License. You may obtain a copy of the License at:
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package slo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLO(t *testing.T) {
+	spec.Run(t, "SLO evaluation", testSLO, spec.Report(report.Terminal{}))
+}
+
+func testSLO(t *testing.T, describe spec.G, it spec.S) {
+	var start = time.Unix(0, 0)
+	var until = start.Add(10 * time.Second)
+	var requests []Request
+
+	request := func(completedAfter, latency time.Duration, failed bool) Request {
+		completedAt := start.Add(completedAfter)
+		return Request{ArrivedAt: completedAt.Add(-latency), CompletedAt: completedAt, Failed: failed}
+	}
+
+	describe("Validate()", func() {
+		it("rejects unknown kinds", func() {
+			assert.Error(t, Objective{Kind: "availability"}.Validate())
+		})
+
+		it("rejects percentiles outside of (0, 100)", func() {
+			assert.Error(t, Objective{Kind: KindLatency, Percentile: 100}.Validate())
+		})
+
+		it("rejects error rates outside of (0, 1)", func() {
+			assert.Error(t, Objective{Kind: KindErrorRate, MaxErrorRate: 5}.Validate())
+		})
+	})
+
+	describe("Evaluate()", func() {
+		it.Before(func() {
+			requests = make([]Request, 0)
+			for i := 1; i <= 10; i++ {
+				requests = append(requests, request(time.Duration(i)*time.Second-time.Millisecond, 100*time.Millisecond, false))
+			}
+		})
+
+		it("panics on an invalid objective", func() {
+			assert.Panics(t, func() {
+				Evaluate(Objective{Kind: "availability"}, requests, start, until)
+			})
+		})
+
+		describe("a latency objective over the whole run", func() {
+			var objective = Objective{Name: "p90", Kind: KindLatency, Percentile: 90, Threshold: 200 * time.Millisecond}
+
+			it("passes when the percentile latency is under the threshold", func() {
+				result := Evaluate(objective, requests, start, until)
+				assert.True(t, result.Passed)
+				assert.Empty(t, result.Violations)
+				assert.Equal(t, 0.0, result.ErrorBudgetBurned)
+			})
+
+			it("fails over the whole run when the percentile latency is over the threshold", func() {
+				requests[3] = request(4*time.Second-time.Millisecond, time.Second, false)
+				requests[4] = request(5*time.Second-time.Millisecond, time.Second, false)
+				result := Evaluate(objective, requests, start, until)
+				assert.False(t, result.Passed)
+				assert.Equal(t, []Violation{{From: start, Until: until}}, result.Violations)
+				assert.InDelta(t, 2.0, result.ErrorBudgetBurned, 0.0001)
+			})
+
+			it("ignores failed requests", func() {
+				requests[3] = request(4*time.Second-time.Millisecond, time.Second, true)
+				assert.True(t, Evaluate(objective, requests, start, until).Passed)
+			})
+		})
+
+		describe("an error rate objective over a rolling window", func() {
+			var objective = Objective{Name: "errors", Kind: KindErrorRate, MaxErrorRate: 0.2, Window: 2 * time.Second}
+
+			it.Before(func() {
+				requests[4].Failed = true
+				requests[5].Failed = true
+			})
+
+			it("reports the time ranges in which the window was violated", func() {
+				result := Evaluate(objective, requests, start, until)
+				assert.False(t, result.Passed)
+				assert.Equal(t, []Violation{{From: start.Add(5 * time.Second), Until: start.Add(8 * time.Second)}}, result.Violations)
+			})
+
+			it("reports the error budget burned over the whole run", func() {
+				result := Evaluate(objective, requests, start, until)
+				assert.InDelta(t, 1.0, result.ErrorBudgetBurned, 0.0001)
+			})
+
+			it("runs a violation until the end of the run if it never recovers", func() {
+				requests[9].Failed = true
+				requests[8].Failed = true
+				result := Evaluate(objective, requests, start, until)
+				assert.Equal(t, until, result.Violations[len(result.Violations)-1].Until)
+			})
+		})
+	})
+
+	describe("NewEvaluator()", func() {
+		var objective = Objective{Name: "p50", Kind: KindLatency, Percentile: 50, Threshold: 200 * time.Millisecond, Window: 2 * time.Second}
+		var subject Evaluator
+
+		it.Before(func() {
+			subject = NewEvaluator(objective, start, until)
+		})
+
+		it("gives the same result as evaluating all the requests at once", func() {
+			requests = make([]Request, 0)
+			for i := 1; i <= 10; i++ {
+				latency := 100 * time.Millisecond
+				if i%3 == 0 || i == 4 {
+					latency = time.Second
+				}
+				requests = append(requests, request(time.Duration(i)*time.Second-time.Millisecond, latency, i == 7))
+			}
+			for _, r := range requests {
+				subject.Observe(r)
+			}
+			assert.Equal(t, Evaluate(objective, requests, start, until), subject.Result())
+			assert.Equal(t, []Violation{
+				{From: start.Add(4 * time.Second), Until: start.Add(5 * time.Second)},
+				{From: start.Add(7 * time.Second), Until: start.Add(8 * time.Second)},
+			}, subject.Result().Violations)
+		})
+
+		it("only keeps the requests still inside the window", func() {
+			for i := 1; i <= 10; i++ {
+				subject.Observe(request(time.Duration(i)*time.Second-time.Millisecond, 100*time.Millisecond, false))
+			}
+			subject.Result()
+			assert.Len(t, subject.(*evaluator).inWindow, 2)
+		})
+
+		it("panics when requests are observed out of order", func() {
+			subject.Observe(request(2*time.Second, 100*time.Millisecond, false))
+			assert.Panics(t, func() {
+				subject.Observe(request(time.Second, 100*time.Millisecond, false))
+			})
+		})
+	})
+}